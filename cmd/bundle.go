@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/bundle"
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/install"
+	"github.com/sol-eng/wbi/internal/jupyter"
+	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/operatingsystem"
+	"github.com/sol-eng/wbi/internal/prodrivers"
+	"github.com/sol-eng/wbi/internal/quarto"
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/sol-eng/wbi/internal/workbench"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type bundleCmd struct {
+	cmd  *cobra.Command
+	opts bundleOpts
+}
+
+type bundleOpts struct {
+	osName         string
+	arch           string
+	rVersions      []string
+	pythonVersions []string
+	quartoVersions []string
+	workbench      bool
+	proDrivers     bool
+	jupyter        bool
+	file           string
+}
+
+func newBundle(bundleOpts bundleOpts, action string) error {
+	if action != "create" {
+		return fmt.Errorf("invalid action provided, please provide the following: create")
+	}
+
+	osType, err := bundle.ParseOS(bundleOpts.osName)
+	if err != nil {
+		return err
+	}
+	arch := config.HostArchitecture()
+	if bundleOpts.arch != "" {
+		arch, err = config.ParseArchitecture(bundleOpts.arch)
+		if err != nil {
+			return err
+		}
+	}
+	builder, err := bundle.NewBuilder(bundleOpts.osName, arch)
+	if err != nil {
+		return err
+	}
+	defer builder.Cleanup()
+
+	manifest := builder.Manifest()
+	manifest.RVersions = bundleOpts.rVersions
+	manifest.PythonVersions = bundleOpts.pythonVersions
+	manifest.QuartoVersions = bundleOpts.quartoVersions
+	manifest.Workbench = bundleOpts.workbench
+	manifest.ProDrivers = bundleOpts.proDrivers
+	manifest.Jupyter = bundleOpts.jupyter
+
+	// R
+	if len(bundleOpts.rVersions) > 0 {
+		err = addVersionList(builder, languages.RVersionsURL(), "metadata/r-versions.json", "r_versions", bundleOpts.rVersions)
+		if err != nil {
+			return err
+		}
+		for _, rVersion := range bundleOpts.rVersions {
+			installerInfo, err := languages.PopulateInstallerInfo("r", rVersion, osType, arch)
+			if err != nil {
+				return fmt.Errorf("PopulateInstallerInfo: %w", err)
+			}
+			err = builder.AddURL("R", installerInfo.URL, "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Python and the pip wheels needed to upgrade pip and install Jupyter
+	if len(bundleOpts.pythonVersions) > 0 {
+		err = addVersionList(builder, languages.PythonVersionsURL(), "metadata/python-versions.json", "python_versions", bundleOpts.pythonVersions)
+		if err != nil {
+			return err
+		}
+		wheels := []string{"pip", "setuptools", "wheel"}
+		if bundleOpts.jupyter {
+			wheels = append(wheels, jupyter.JupyterPackages...)
+			wheels = append(wheels, "ipykernel")
+		}
+		for _, pythonVersion := range bundleOpts.pythonVersions {
+			installerInfo, err := languages.PopulateInstallerInfo("python", pythonVersion, osType, arch)
+			if err != nil {
+				return fmt.Errorf("PopulateInstallerInfo: %w", err)
+			}
+			err = builder.AddURL("Python", installerInfo.URL, "")
+			if err != nil {
+				return err
+			}
+			err = builder.AddWheels(pythonVersion, wheels)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Quarto
+	if len(bundleOpts.quartoVersions) > 0 {
+		err = addQuartoReleases(builder, bundleOpts.quartoVersions)
+		if err != nil {
+			return err
+		}
+		for _, quartoVersion := range bundleOpts.quartoVersions {
+			quartoURL, err := quarto.GenerateQuartoInstallURL(quartoVersion, osType, arch)
+			if err != nil {
+				return fmt.Errorf("GenerateQuartoInstallURL: %w", err)
+			}
+			checksum, err := quarto.RetrieveQuartoChecksum(quartoVersion, quartoURL)
+			if err != nil {
+				return fmt.Errorf("RetrieveQuartoChecksum: %w", err)
+			}
+			if checksum != "" {
+				err = builder.AddURL("Quarto checksums", quarto.ChecksumsURL(quartoVersion), "")
+				if err != nil {
+					return err
+				}
+			} else {
+				// the release has no checksums file, record that so offline installs don't look for it on the network
+				builder.AddMissing(quarto.ChecksumsURL(quartoVersion))
+			}
+			err = builder.AddURL("Quarto", quartoURL, checksum)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Workbench and Pro Drivers share the downloads.json metadata and the key their packages are signed with
+	if bundleOpts.workbench || bundleOpts.proDrivers {
+		err = builder.AddURL("Posit downloads metadata", workbench.DownloadsURL(), "")
+		if err != nil {
+			return err
+		}
+		err = builder.AddURL("Posit signing key", install.PositSigningKeyURL(), "")
+		if err != nil {
+			return err
+		}
+	}
+	if bundleOpts.workbench {
+		rstudio, err := workbench.RetrieveWorkbenchInstallerInfo()
+		if err != nil {
+			return fmt.Errorf("RetrieveWorkbenchInstallerInfo: %w", err)
+		}
+		installerInfo, err := rstudio.GetInstallerInfo(osType, arch)
+		if err != nil {
+			return fmt.Errorf("GetInstallerInfo: %w", err)
+		}
+		err = builder.AddURL("Workbench", installerInfo.URL, installerInfo.SHA256)
+		if err != nil {
+			return err
+		}
+	}
+	if bundleOpts.proDrivers {
+		proDrivers, err := prodrivers.RetrieveProDriversInstallerInfo()
+		if err != nil {
+			return fmt.Errorf("RetrieveProDriversInstallerInfo: %w", err)
+		}
+		installerInfo, err := proDrivers.GetInstallerInfo(osType, arch)
+		if err != nil {
+			return fmt.Errorf("GetInstallerInfo: %w", err)
+		}
+		err = builder.AddURL("Pro Drivers", installerInfo.URL, installerInfo.SHA256)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the EPEL repository is enabled as a pre-requisite on RHEL
+	if osType == config.Redhat7 || osType == config.Redhat8 || osType == config.Redhat9 {
+		epelURL, err := operatingsystem.EPELReleaseURL(osType)
+		if err != nil {
+			return err
+		}
+		err = builder.AddURL("EPEL", epelURL, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = builder.Write(bundleOpts.file)
+	if err != nil {
+		return err
+	}
+	system.PrintAndLogInfo("\nThe offline bundle has been written to " + bundleOpts.file + "\nCopy it to the server and run \"wbi setup --bundle " + bundleOpts.file + "\"")
+	return nil
+}
+
+// addVersionList adds version metadata that only lists the bundled versions, so prompts and validation only offer them
+func addVersionList(builder *bundle.Builder, url string, bundlePath string, key string, versions []string) error {
+	content, err := json.Marshal(map[string][]string{key: versions})
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", bundlePath, err)
+	}
+	return builder.AddContent(url, bundlePath, content)
+}
+
+// addQuartoReleases adds pages of GitHub releases that only list the bundled Quarto versions
+func addQuartoReleases(builder *bundle.Builder, quartoVersions []string) error {
+	releases := make(quarto.Quarto, len(quartoVersions))
+	for i, quartoVersion := range quartoVersions {
+		releases[i].Name = quartoVersion
+		releases[i].Assets = quarto.Assets{}
+	}
+	content, err := json.Marshal(releases)
+	if err != nil {
+		return fmt.Errorf("failed to create the Quarto releases metadata: %w", err)
+	}
+	for page := 1; page <= quarto.ReleasesPages; page++ {
+		// every page is requested when retrieving versions, the bundled versions are all on the first
+		pageContent := []byte("[]")
+		if page == 1 {
+			pageContent = content
+		}
+		err = builder.AddContent(quarto.ReleasesURL(page), fmt.Sprintf("metadata/quarto-releases-%d.json", page), pageContent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setBundleOpts(bundleOpts *bundleOpts) {
+	bundleOpts.osName = viper.GetString("bundle-os")
+	bundleOpts.arch = viper.GetString("bundle-arch")
+	bundleOpts.rVersions = viper.GetStringSlice("bundle-r")
+	bundleOpts.pythonVersions = viper.GetStringSlice("bundle-python")
+	bundleOpts.quartoVersions = viper.GetStringSlice("bundle-quarto")
+	bundleOpts.workbench = viper.GetBool("bundle-workbench")
+	bundleOpts.proDrivers = viper.GetBool("bundle-prodrivers")
+	bundleOpts.jupyter = viper.GetBool("bundle-jupyter")
+	bundleOpts.file = viper.GetString("bundle-file")
+	if bundleOpts.file == "" && bundleOpts.osName != "" {
+		bundleOpts.file = "wbi-bundle-" + strings.ToLower(bundleOpts.osName) + ".tar"
+		if arch, err := config.ParseArchitecture(bundleOpts.arch); err == nil && arch == config.ARM64 {
+			bundleOpts.file = "wbi-bundle-" + strings.ToLower(bundleOpts.osName) + "-arm64.tar"
+		}
+	}
+}
+
+func (opts *bundleOpts) Validate(args []string) error {
+	// check args lengths
+	if len(args) == 0 {
+		return fmt.Errorf("no arguments provided, please provide one argument")
+	} else if len(args) > 1 {
+		return fmt.Errorf("too many arguments provided, please provide only one argument")
+	}
+
+	// ensure action is valid
+	if args[0] != "create" {
+		return fmt.Errorf("invalid argument provided")
+	}
+
+	// the os flag is required and must be supported
+	if opts.osName == "" {
+		return fmt.Errorf("the os flag is required, valid options are: %s", strings.Join(bundle.ValidOSNames(), ", "))
+	}
+	if !lo.Contains(bundle.ValidOSNames(), strings.ToLower(opts.osName)) {
+		return fmt.Errorf("invalid os provided, valid options are: %s", strings.Join(bundle.ValidOSNames(), ", "))
+	}
+
+	// the arch flag defaults to the architecture of this server
+	if opts.arch != "" {
+		if _, err := config.ParseArchitecture(opts.arch); err != nil {
+			return err
+		}
+	}
+
+	// at least one component must be bundled
+	if len(opts.rVersions) == 0 && len(opts.pythonVersions) == 0 && len(opts.quartoVersions) == 0 && !opts.workbench && !opts.proDrivers {
+		return fmt.Errorf("nothing to bundle, please provide at least one of the r, python, quarto, workbench or prodrivers flags")
+	}
+
+	// Jupyter is installed into a bundled version of Python
+	if opts.jupyter && len(opts.pythonVersions) == 0 {
+		return fmt.Errorf("the jupyter flag requires the python flag")
+	}
+
+	return nil
+}
+
+func newBundleCmd() *bundleCmd {
+	root := &bundleCmd{opts: bundleOpts{}}
+
+	// adding two spaces to have consistent formatting
+	exampleText := []string{
+		"To create an offline bundle for an air-gapped Ubuntu 22.04 server:",
+		"  wbi bundle create --os jammy --r 4.3.2 --python 3.11.7 --quarto v1.4.550 --workbench --prodrivers --jupyter",
+		"",
+		"To create an offline bundle with multiple R versions at a specific location:",
+		"  wbi bundle create --os rhel9 --r 4.3.2,4.2.3 --workbench --file /tmp/wbi-bundle.tar",
+		"",
+		"To create an offline bundle for an air-gapped arm64 server:",
+		"  wbi bundle create --os noble --arch arm64 --r 4.3.2 --workbench",
+		"",
+		"To use an offline bundle on the air-gapped server:",
+		"  wbi setup --bundle wbi-bundle-jammy.tar",
+		"  wbi install r --version 4.3.2 --bundle wbi-bundle-jammy.tar",
+	}
+
+	cmd := &cobra.Command{
+		Use:     "bundle [action]",
+		Short:   "Create an offline bundle of installers for air-gapped servers",
+		Example: strings.Join(exampleText, "\n"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			setBundleOpts(&root.opts)
+			if err := root.opts.Validate(args); err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("bundle-opts")
+			if err := newBundle(root.opts, strings.ToLower(args[0])); err != nil {
+				return err
+			}
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().String("os", "", "Operating system of the air-gapped server ("+strings.Join(bundle.ValidOSNames(), ", ")+")")
+	viper.BindPFlag("bundle-os", cmd.Flags().Lookup("os"))
+
+	cmd.Flags().String("arch", "", "Architecture of the air-gapped server (amd64, arm64), defaults to the architecture of this server")
+	viper.BindPFlag("bundle-arch", cmd.Flags().Lookup("arch"))
+
+	cmd.Flags().StringSlice("r", []string{}, "Version(s) of R to bundle. Multiple values can be passed by seperating each version with a comma.")
+	viper.BindPFlag("bundle-r", cmd.Flags().Lookup("r"))
+
+	cmd.Flags().StringSlice("python", []string{}, "Version(s) of Python to bundle. Multiple values can be passed by seperating each version with a comma.")
+	viper.BindPFlag("bundle-python", cmd.Flags().Lookup("python"))
+
+	cmd.Flags().StringSlice("quarto", []string{}, "Version(s) of Quarto to bundle, such as v1.4.550. Multiple values can be passed by seperating each version with a comma.")
+	viper.BindPFlag("bundle-quarto", cmd.Flags().Lookup("quarto"))
+
+	cmd.Flags().Bool("workbench", false, "Bundle the latest Workbench installer.")
+	viper.BindPFlag("bundle-workbench", cmd.Flags().Lookup("workbench"))
+
+	cmd.Flags().Bool("prodrivers", false, "Bundle the latest Pro Drivers installer.")
+	viper.BindPFlag("bundle-prodrivers", cmd.Flags().Lookup("prodrivers"))
+
+	cmd.Flags().Bool("jupyter", false, "Bundle the pip wheels needed to install Jupyter into each bundled version of Python.")
+	viper.BindPFlag("bundle-jupyter", cmd.Flags().Lookup("jupyter"))
+
+	cmd.Flags().StringP("file", "f", "", "Path to write the bundle to (default wbi-bundle-<os>.tar)")
+	viper.BindPFlag("bundle-file", cmd.Flags().Lookup("file"))
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBundleParamsValidate tests the bundle command parameters
+func TestBundleParamsValidate(t *testing.T) {
+
+	tests := map[string]struct {
+		args        []string
+		flags       bundleOpts
+		expectError string
+	}{
+		"no argument": {
+			args:        []string{},
+			flags:       bundleOpts{},
+			expectError: "no arguments provided, please provide one argument",
+		},
+		"too many arguments": {
+			args:        []string{"create", "extract"},
+			flags:       bundleOpts{osName: "jammy", workbench: true},
+			expectError: "too many arguments provided, please provide only one argument",
+		},
+		"invalid argument": {
+			args:        []string{"extract"},
+			flags:       bundleOpts{osName: "jammy", workbench: true},
+			expectError: "invalid argument provided",
+		},
+		"create without an os flag fails": {
+			args:        []string{"create"},
+			flags:       bundleOpts{workbench: true},
+			expectError: "the os flag is required",
+		},
+		"create with an invalid os fails": {
+			args:        []string{"create"},
+			flags:       bundleOpts{osName: "bionic", workbench: true},
+			expectError: "invalid os provided",
+		},
+		"create with nothing to bundle fails": {
+			args:        []string{"create"},
+			flags:       bundleOpts{osName: "jammy"},
+			expectError: "nothing to bundle",
+		},
+		"create with jupyter and no python fails": {
+			args:        []string{"create"},
+			flags:       bundleOpts{osName: "jammy", rVersions: []string{"4.3.2"}, jupyter: true},
+			expectError: "the jupyter flag requires the python flag",
+		},
+		"create with every component succeeds": {
+			args: []string{"create"},
+			flags: bundleOpts{
+				osName:         "jammy",
+				rVersions:      []string{"4.3.2"},
+				pythonVersions: []string{"3.11.7"},
+				quartoVersions: []string{"v1.4.550"},
+				workbench:      true,
+				proDrivers:     true,
+				jupyter:        true,
+			},
+			expectError: "",
+		},
+		"create with an uppercase os succeeds": {
+			args:        []string{"create"},
+			flags:       bundleOpts{osName: "RHEL9", rVersions: []string{"4.3.2"}},
+			expectError: "",
+		},
+		"create with an invalid arch fails": {
+			args:        []string{"create"},
+			flags:       bundleOpts{osName: "jammy", arch: "ppc64le", rVersions: []string{"4.3.2"}},
+			expectError: "invalid architecture ppc64le",
+		},
+		"create with the aarch64 arch succeeds": {
+			args:        []string{"create"},
+			flags:       bundleOpts{osName: "noble", arch: "aarch64", rVersions: []string{"4.3.2"}},
+			expectError: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bundleCmd := newBundleCmd()
+			// set the flags
+			bundleCmd.opts = tc.flags
+			// run validation
+			err := bundleCmd.opts.Validate(tc.args)
+
+			if err != nil && tc.expectError != "" {
+				// if we expect an error, check that it contains the expected error
+				assert.Containsf(t, err.Error(), tc.expectError, "expected error containing %q, got %s", tc.expectError, err)
+			} else if err != nil && tc.expectError == "" {
+				// if we expect no error but get one then fail
+				t.Fatalf("expected no error, but got %s", err)
+			} else if err == nil && tc.expectError != "" {
+				// if we expect an error but don't get one then fail
+				t.Fatalf("expected error containing %q, but the command ran without error", tc.expectError)
+			}
+			// otherwise we expect the command to succeed so pass the test
+		})
+	}
+
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/install"
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type cacheCmd struct {
+	cmd  *cobra.Command
+	opts cacheOpts
+}
+
+type cacheOpts struct {
+	maxAge time.Duration
+}
+
+func newCache(cacheOpts cacheOpts, action string) error {
+	if action == "list" {
+		entries, err := install.ListCache()
+		if err != nil {
+			return fmt.Errorf("issue listing the download cache: %w", err)
+		}
+		if len(entries) == 0 {
+			system.PrintAndLogInfo("The download cache " + install.CacheDir() + " is empty")
+			return nil
+		}
+		var total int64
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "FILE\tSIZE\tLAST USED\tURL")
+		for _, entry := range entries {
+			total += entry.Size
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.Filename, formatSize(entry.Size), entry.LastUsed.Local().Format("2006-01-02 15:04"), entry.URL)
+		}
+		writer.Flush()
+		system.PrintAndLogInfo(fmt.Sprintf("\n%d files using %s in %s", len(entries), formatSize(total), install.CacheDir()))
+	} else if action == "prune" {
+		removed, err := install.PruneCache(cacheOpts.maxAge)
+		if err != nil {
+			return fmt.Errorf("issue pruning the download cache: %w", err)
+		}
+		var total int64
+		for _, entry := range removed {
+			total += entry.Size
+			system.PrintAndLogInfo("Removed " + entry.Filename + " (last used " + entry.LastUsed.Local().Format("2006-01-02") + ")")
+		}
+		system.PrintAndLogInfo(fmt.Sprintf("Pruned %d files not used in the last %s, freeing %s", len(removed), cacheOpts.maxAge, formatSize(total)))
+	} else if action == "clear" {
+		err := install.ClearCache()
+		if err != nil {
+			return fmt.Errorf("issue clearing the download cache: %w", err)
+		}
+		system.PrintAndLogInfo("The download cache " + install.CacheDir() + " has been cleared")
+	} else {
+		return fmt.Errorf("invalid action provided, please provide one of the following: list, prune, clear")
+	}
+	return nil
+}
+
+// formatSize formats a number of bytes as a human readable size
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func setCacheOpts(cacheOpts *cacheOpts) {
+	cacheOpts.maxAge = viper.GetDuration("max-age")
+}
+
+func (opts *cacheOpts) Validate(args []string) error {
+	// check args lengths
+	if len(args) == 0 {
+		return fmt.Errorf("no arguments provided, please provide one argument")
+	} else if len(args) > 1 {
+		return fmt.Errorf("too many arguments provided, please provide only one argument")
+	}
+
+	// ensure action is valid
+	if args[0] != "list" && args[0] != "prune" && args[0] != "clear" {
+		return fmt.Errorf("invalid argument provided")
+	}
+
+	// ensure max-age is positive
+	if opts.maxAge <= 0 {
+		return fmt.Errorf("the max-age flag must be a positive duration, such as 720h")
+	}
+
+	// the cache directory is required
+	if install.CacheDir() == "" {
+		return fmt.Errorf("the download cache is disabled, please provide a cache-dir")
+	}
+
+	return nil
+}
+
+func newCacheCmd() *cacheCmd {
+	root := &cacheCmd{opts: cacheOpts{}}
+
+	// adding two spaces to have consistent formatting
+	exampleText := []string{
+		"To list the installers in the download cache:",
+		"  wbi cache list",
+		"",
+		"To remove installers that haven't been used in the last 30 days:",
+		"  wbi cache prune",
+		"",
+		"To remove installers that haven't been used in the last week:",
+		"  wbi cache prune --max-age 168h",
+		"",
+		"To remove every installer from the download cache:",
+		"  wbi cache clear",
+	}
+
+	cmd := &cobra.Command{
+		Use:     "cache [action]",
+		Short:   "List, prune or clear the download cache",
+		Example: strings.Join(exampleText, "\n"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			setCacheOpts(&root.opts)
+			if err := root.opts.Validate(args); err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("cache-opts")
+			if err := newCache(root.opts, strings.ToLower(args[0])); err != nil {
+				return err
+			}
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().Duration("max-age", 30*24*time.Hour, "Prune installers that haven't been used for this long")
+	viper.BindPFlag("max-age", cmd.Flags().Lookup("max-age"))
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCacheParamsValidate tests the cache command parameters
+func TestCacheParamsValidate(t *testing.T) {
+
+	tests := map[string]struct {
+		args        []string
+		flags       cacheOpts
+		expectError string
+	}{
+		"no argument": {
+			args:        []string{},
+			flags:       cacheOpts{maxAge: time.Hour},
+			expectError: "no arguments provided, please provide one argument",
+		},
+		"too many arguments": {
+			args:        []string{"list", "clear"},
+			flags:       cacheOpts{maxAge: time.Hour},
+			expectError: "too many arguments provided, please provide only one argument",
+		},
+		"invalid argument": {
+			args:        []string{"delete"},
+			flags:       cacheOpts{maxAge: time.Hour},
+			expectError: "invalid argument provided",
+		},
+		"prune with a negative max-age fails": {
+			args:        []string{"prune"},
+			flags:       cacheOpts{maxAge: -time.Hour},
+			expectError: "the max-age flag must be a positive duration",
+		},
+		"list succeeds": {
+			args:        []string{"list"},
+			flags:       cacheOpts{maxAge: time.Hour},
+			expectError: "",
+		},
+		"prune succeeds": {
+			args:        []string{"prune"},
+			flags:       cacheOpts{maxAge: 720 * time.Hour},
+			expectError: "",
+		},
+		"clear succeeds": {
+			args:        []string{"clear"},
+			flags:       cacheOpts{maxAge: time.Hour},
+			expectError: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cacheCmd := newCacheCmd()
+			// set the flags
+			cacheCmd.opts = tc.flags
+			// run validation
+			err := cacheCmd.opts.Validate(tc.args)
+
+			if err != nil && tc.expectError != "" {
+				// if we expect an error, check that it contains the expected error
+				assert.Containsf(t, err.Error(), tc.expectError, "expected error containing %q, got %s", tc.expectError, err)
+			} else if err != nil && tc.expectError == "" {
+				// if we expect no error but get one then fail
+				t.Fatalf("expected no error, but got %s", err)
+			} else if err == nil && tc.expectError != "" {
+				// if we expect an error but don't get one then fail
+				t.Fatalf("expected error containing %q, but the command ran without error", tc.expectError)
+			}
+			// otherwise we expect the command to succeed so pass the test
+		})
+	}
+
+}
@@ -2,9 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 
+	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/activedirectory"
+	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/launcher"
+	"github.com/sol-eng/wbi/internal/operatingsystem"
+	"github.com/sol-eng/wbi/internal/system"
 	"github.com/sol-eng/wbi/internal/workbench"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,13 +24,41 @@ type configCmd struct {
 }
 
 type configOpts struct {
-	certPath string
-	keyPath  string
-	url      string
-	source   string
+	certPath     string
+	keyPath      string
+	url          string
+	source       string
+	rVersions    languages.RVersionsOptions
+	auth         workbench.AuthOptions
+	ad           activedirectory.Options
+	database     workbench.DatabaseOptions
+	loadBalancer workbench.LoadBalancerOptions
+	// passwordFile holds the password of the Active Directory join user or the database user, it is prompted for
+	// when not provided
+	passwordFile string
+	// clientSecretFile holds the OpenID Connect client secret, so it isn't visible in the process list or shell history
+	clientSecretFile string
+	launcher         launcherFlags
+	// profiles are the session profiles, such as "@data-science:max-cpus=4,max-mem-mb=8192,image=IMAGE"
+	profiles []string
 }
 
-func newConfig(configOpts configOpts, item string) error {
+// launcherFlags holds the launcher flags as provided, the files they point to are read when the plugin is configured
+type launcherFlags struct {
+	apiURL             string
+	namespace          string
+	tokenFile          string
+	kubeconfig         string
+	caCertPath         string
+	insecureSkipVerify bool
+	binPath            string
+	slurmConf          string
+	serviceUser        string
+	partition          string
+	callbackAddress    string
+}
+
+func newConfig(configOpts configOpts, item string, plugin string) error {
 	if item == "ssl" {
 		err := workbench.WriteSSLConfig(configOpts.certPath, configOpts.keyPath, configOpts.url)
 		if err != nil {
@@ -38,23 +74,209 @@ func newConfig(configOpts configOpts, item string) error {
 		if err != nil {
 			return fmt.Errorf("failed to write Connect URL config for Workbench: %w", err)
 		}
+	} else if item == "r-versions" {
+		err := languages.ConfigureRVersions(configOpts.rVersions)
+		if err != nil {
+			return fmt.Errorf("failed to write R versions config for Workbench: %w", err)
+		}
+	} else if item == "auth" {
+		// prompt for anything not provided with flags when the type isn't given
+		authOpts := configOpts.auth
+		if configOpts.clientSecretFile != "" {
+			var err error
+			authOpts.ClientSecret, err = system.ReadPasswordFile(configOpts.clientSecretFile)
+			if err != nil {
+				return err
+			}
+		}
+		if authOpts.Type == "" {
+			var err error
+			authOpts, err = workbench.PromptAuthOptions(authOpts)
+			if err != nil {
+				return err
+			}
+		}
+		err := workbench.WriteAuthConfig(authOpts)
+		if err != nil {
+			return fmt.Errorf("failed to write authentication config for Workbench: %w", err)
+		}
+	} else if item == "ad" {
+		osType, err := operatingsystem.DetectOS()
+		if err != nil {
+			return fmt.Errorf("issue detecting OS: %w", err)
+		}
+		adOpts := configOpts.ad
+		if configOpts.passwordFile != "" {
+			adOpts.Password, err = system.ReadPasswordFile(configOpts.passwordFile)
+			if err != nil {
+				return err
+			}
+		}
+		adOpts, err = activedirectory.PromptOptions(adOpts)
+		if err != nil {
+			return err
+		}
+		err = activedirectory.JoinDomain(adOpts, osType)
+		if err != nil {
+			return fmt.Errorf("failed to join the Active Directory domain: %w", err)
+		}
+	} else if item == "database" {
+		databaseOpts := configOpts.database
+		if configOpts.passwordFile != "" {
+			var err error
+			databaseOpts.Password, err = system.ReadPasswordFile(configOpts.passwordFile)
+			if err != nil {
+				return err
+			}
+		}
+		// prompt for anything not provided with flags when the provider isn't given
+		if databaseOpts.Provider == "" {
+			var err error
+			databaseOpts, err = workbench.PromptDatabaseOptions(databaseOpts)
+			if err != nil {
+				return err
+			}
+		}
+		err := workbench.WriteDatabaseConfig(databaseOpts)
+		if err != nil {
+			return fmt.Errorf("failed to write database config for Workbench: %w", err)
+		}
+	} else if item == "load-balancer" {
+		err := workbench.ConfigureLoadBalancer(configOpts.loadBalancer)
+		if err != nil {
+			return fmt.Errorf("failed to write load balancer config for Workbench: %w", err)
+		}
+	} else if item == "launcher" && plugin == launcher.PluginKubernetes {
+		kubernetesOpts, err := configOpts.kubernetesOptions()
+		if err != nil {
+			return err
+		}
+		err = launcher.ConfigureKubernetes(kubernetesOpts)
+		if err != nil {
+			return fmt.Errorf("failed to configure the Kubernetes Launcher plugin: %w", err)
+		}
+	} else if item == "launcher" && plugin == launcher.PluginSlurm {
+		profiles, err := parseProfiles(configOpts.profiles)
+		if err != nil {
+			return err
+		}
+		slurmOpts := launcher.SlurmOptions{
+			BinPath:         configOpts.launcher.binPath,
+			ConfPath:        configOpts.launcher.slurmConf,
+			ServiceUser:     configOpts.launcher.serviceUser,
+			Partition:       configOpts.launcher.partition,
+			CallbackAddress: configOpts.launcher.callbackAddress,
+			Profiles:        profiles,
+		}
+		err = launcher.ConfigureSlurm(slurmOpts)
+		if err != nil {
+			return fmt.Errorf("failed to configure the Slurm Launcher plugin: %w", err)
+		}
 	} else {
-		return fmt.Errorf("invalid item provided, please provide one of the following: ssl, repo, connect-url")
+		return fmt.Errorf("invalid item provided, please provide one of the following: ssl, repo, connect-url, r-versions, auth, ad, database, load-balancer, launcher")
 	}
 	return nil
 }
 
+// kubernetesOptions reads the token and certificate authority files and parses the profiles provided with flags
+func (opts *configOpts) kubernetesOptions() (launcher.KubernetesOptions, error) {
+	kubernetesOpts := launcher.KubernetesOptions{
+		APIURL:             opts.launcher.apiURL,
+		Namespace:          opts.launcher.namespace,
+		Kubeconfig:         opts.launcher.kubeconfig,
+		InsecureSkipVerify: opts.launcher.insecureSkipVerify,
+		CallbackAddress:    opts.launcher.callbackAddress,
+	}
+	var err error
+	if opts.launcher.tokenFile != "" {
+		kubernetesOpts.Token, err = system.ReadPasswordFile(opts.launcher.tokenFile)
+		if err != nil {
+			return kubernetesOpts, err
+		}
+	}
+	if opts.launcher.caCertPath != "" {
+		kubernetesOpts.CACert, err = os.ReadFile(opts.launcher.caCertPath)
+		if err != nil {
+			return kubernetesOpts, fmt.Errorf("failed to read the certificate authority %s: %w", opts.launcher.caCertPath, err)
+		}
+	}
+	kubernetesOpts.Profiles, err = parseProfiles(opts.profiles)
+	return kubernetesOpts, err
+}
+
+// parseProfiles parses each profile provided with the profile flag
+func parseProfiles(specs []string) ([]launcher.Profile, error) {
+	var profiles []launcher.Profile
+	for _, spec := range specs {
+		profile, err := launcher.ParseProfile(spec)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
 func setConfigOpts(configOpts *configOpts) {
 	configOpts.certPath = viper.GetString("cert-path")
 	configOpts.keyPath = viper.GetString("key-path")
 	configOpts.url = viper.GetString("url")
 	configOpts.source = viper.GetString("source")
+	configOpts.rVersions.Label = viper.GetString("r-versions-label")
+	configOpts.rVersions.Module = viper.GetString("r-versions-module")
+	configOpts.rVersions.Script = viper.GetString("r-versions-script")
+	configOpts.rVersions.DisableScan = viper.GetBool("r-versions-disable-scan")
+	configOpts.rVersions.SetDefault = viper.GetBool("r-versions-set-default")
+	configOpts.auth.Type = strings.ToLower(viper.GetString("auth-type"))
+	configOpts.auth.MetadataURL = viper.GetString("auth-metadata-url")
+	configOpts.auth.Issuer = viper.GetString("auth-issuer")
+	configOpts.auth.ClientID = viper.GetString("auth-client-id")
+	configOpts.auth.ClientSecret = viper.GetString("auth-client-secret")
+	configOpts.clientSecretFile = viper.GetString("auth-client-secret-file")
+	configOpts.auth.UsernameClaim = viper.GetString("auth-username-claim")
+	configOpts.auth.SignInURL = viper.GetString("auth-sign-in-url")
+	configOpts.auth.UserHeader = viper.GetString("auth-user-header")
+	configOpts.ad.Domain = viper.GetString("ad-domain")
+	configOpts.ad.JoinUser = viper.GetString("ad-join-user")
+	configOpts.ad.ComputerOU = viper.GetString("ad-computer-ou")
+	configOpts.ad.VerifyUser = viper.GetString("ad-verify-user")
+	configOpts.database.Provider = strings.ToLower(viper.GetString("database-provider"))
+	configOpts.database.Host = viper.GetString("database-host")
+	configOpts.database.Port = viper.GetString("database-port")
+	configOpts.database.Database = viper.GetString("database-name")
+	configOpts.database.User = viper.GetString("database-user")
+	configOpts.database.EncryptPassword = viper.GetBool("database-encrypt-password")
+	configOpts.database.MigrateSQLite = viper.GetBool("database-migrate-sqlite")
+	configOpts.passwordFile = viper.GetString("password-file")
+	configOpts.loadBalancer.SharedStoragePath = viper.GetString("lb-shared-storage-path")
+	configOpts.loadBalancer.ClusterDir = viper.GetString("lb-cluster-dir")
+	configOpts.loadBalancer.Balancer = strings.ToLower(viper.GetString("lb-balancer"))
+	configOpts.loadBalancer.HostName = viper.GetString("lb-host-name")
+	configOpts.launcher.apiURL = viper.GetString("k8s-api-url")
+	configOpts.launcher.namespace = viper.GetString("k8s-namespace")
+	configOpts.launcher.tokenFile = viper.GetString("k8s-token-file")
+	configOpts.launcher.kubeconfig = viper.GetString("k8s-kubeconfig")
+	configOpts.launcher.caCertPath = viper.GetString("k8s-ca-cert")
+	configOpts.launcher.insecureSkipVerify = viper.GetBool("k8s-insecure-skip-verify")
+	configOpts.launcher.binPath = viper.GetString("slurm-bin-path")
+	configOpts.launcher.slurmConf = viper.GetString("slurm-conf")
+	configOpts.launcher.serviceUser = viper.GetString("slurm-service-user")
+	configOpts.launcher.partition = viper.GetString("slurm-partition")
+	configOpts.launcher.callbackAddress = viper.GetString("launcher-callback-address")
+	configOpts.profiles = viper.GetStringSlice("launcher-profiles")
 }
 
 func (opts *configOpts) Validate(args []string) error {
 	// check args lengths
 	if len(args) == 0 {
 		return fmt.Errorf("no arguments provided, please provide one argument")
+	} else if args[0] == "launcher" {
+		// launcher takes the plugin to configure as a second argument
+		if len(args) == 1 || !lo.Contains(launcher.Plugins, strings.ToLower(args[1])) {
+			return fmt.Errorf("the launcher item requires a plugin, please provide one of the following: %s", strings.Join(launcher.Plugins, ", "))
+		} else if len(args) > 2 {
+			return fmt.Errorf("too many arguments provided, please provide only the launcher item and a plugin")
+		}
 	} else if len(args) > 1 {
 		return fmt.Errorf("too many arguments provided, please provide only one argument")
 	}
@@ -110,6 +332,185 @@ func (opts *configOpts) Validate(args []string) error {
 		return fmt.Errorf("the source flag only allows cran and pypi")
 	}
 
+	// the label, module, script, disable-scan and set-default flags are only valid for r-versions
+	if opts.rVersions != (languages.RVersionsOptions{}) && args[0] != "r-versions" {
+		return fmt.Errorf("the label, module, script, disable-scan and set-default flags are only valid for r-versions")
+	}
+
+	// the type, metadata-url, issuer, client-id, client-secret, username-claim, sign-in-url and user-header flags are only valid for auth
+	if opts.auth != (workbench.AuthOptions{}) && args[0] != "auth" {
+		return fmt.Errorf("the type, metadata-url, issuer, client-id, client-secret, username-claim, sign-in-url and user-header flags are only valid for auth")
+	}
+
+	// the client-secret-file flag is only valid for auth
+	if opts.clientSecretFile != "" && args[0] != "auth" {
+		return fmt.Errorf("the client-secret-file flag is only valid for auth")
+	}
+
+	// the domain, join-user, computer-ou and verify-user flags are only valid for ad
+	if opts.ad != (activedirectory.Options{}) && args[0] != "ad" {
+		return fmt.Errorf("the domain, join-user, computer-ou and verify-user flags are only valid for ad")
+	}
+	if args[0] == "ad" {
+		err := opts.ad.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the provider, host, port, database, user, encrypt-password and migrate-sqlite flags are only valid for database
+	if opts.database != (workbench.DatabaseOptions{}) && args[0] != "database" {
+		return fmt.Errorf("the provider, host, port, database, user, encrypt-password and migrate-sqlite flags are only valid for database")
+	}
+
+	// the password-file flag is only valid for ad and database
+	if opts.passwordFile != "" && args[0] != "ad" && args[0] != "database" {
+		return fmt.Errorf("the password-file flag is only valid for ad and database")
+	}
+
+	// the shared-storage-path, cluster-dir, balancer and host-name flags are only valid for load-balancer
+	if opts.loadBalancer != (workbench.LoadBalancerOptions{}) && args[0] != "load-balancer" {
+		return fmt.Errorf("the shared-storage-path, cluster-dir, balancer and host-name flags are only valid for load-balancer")
+	}
+	// the shared-storage-path and cluster-dir flags are required for load-balancer
+	if (opts.loadBalancer.SharedStoragePath == "" || opts.loadBalancer.ClusterDir == "") && args[0] == "load-balancer" {
+		return fmt.Errorf("the shared-storage-path and cluster-dir flags are required for load-balancer")
+	}
+	// the only balancer flags allowed are sessions, user-sessions and system-load
+	if opts.loadBalancer.Balancer != "" && !lo.Contains(workbench.Balancers, opts.loadBalancer.Balancer) {
+		return fmt.Errorf("the balancer flag only allows %s", strings.Join(workbench.Balancers, ", "))
+	}
+
+	// the launcher flags are only valid for launcher
+	if (opts.launcher != (launcherFlags{}) || len(opts.profiles) > 0) && args[0] != "launcher" {
+		return fmt.Errorf("the api-url, namespace, token-file, kubeconfig, ca-cert, insecure-skip-verify, bin-path, slurm-conf, service-user, partition, callback-address and profile flags are only valid for launcher")
+	}
+
+	if args[0] == "auth" {
+		return opts.validateAuth()
+	}
+	if args[0] == "launcher" {
+		return opts.validateLauncher(strings.ToLower(args[1]))
+	}
+	if args[0] == "database" {
+		return opts.validateDatabase()
+	}
+	return nil
+}
+
+// validateAuth checks the flags needed for the authentication type are provided, without a type the rest is prompted for
+func (opts *configOpts) validateAuth() error {
+	auth := opts.auth
+	// the client-secret and client-secret-file flags both provide the client secret
+	if auth.ClientSecret != "" && opts.clientSecretFile != "" {
+		return fmt.Errorf("the client-secret and client-secret-file flags can't be used together")
+	}
+	if auth.Type == "" {
+		return nil
+	}
+	if !lo.Contains(workbench.AuthTypes, auth.Type) {
+		return fmt.Errorf("invalid type provided, please provide one of the following: %s", strings.Join(workbench.AuthTypes, ", "))
+	}
+
+	// the metadata-url flag is required for saml
+	if auth.Type == workbench.AuthSAML && auth.MetadataURL == "" {
+		return fmt.Errorf("the metadata-url flag is required for saml")
+	}
+	// the issuer, client-id and client-secret-file flags are required for openid, the deprecated client-secret flag is
+	// still accepted in place of client-secret-file
+	if auth.Type == workbench.AuthOpenID && (auth.Issuer == "" || auth.ClientID == "" || (auth.ClientSecret == "" && opts.clientSecretFile == "")) {
+		return fmt.Errorf("the issuer, client-id and client-secret-file flags are required for openid")
+	}
+	// the sign-in-url flag is required for proxy
+	if auth.Type == workbench.AuthProxy && auth.SignInURL == "" {
+		return fmt.Errorf("the sign-in-url flag is required for proxy")
+	}
+
+	// each flag is only valid for the types that use it
+	if auth.MetadataURL != "" && auth.Type != workbench.AuthSAML {
+		return fmt.Errorf("the metadata-url flag is only valid for saml")
+	}
+	if (auth.Issuer != "" || auth.ClientID != "" || auth.ClientSecret != "") && auth.Type != workbench.AuthOpenID {
+		return fmt.Errorf("the issuer, client-id and client-secret flags are only valid for openid")
+	}
+	if opts.clientSecretFile != "" && auth.Type != workbench.AuthOpenID {
+		return fmt.Errorf("the client-secret-file flag is only valid for openid")
+	}
+	if auth.UsernameClaim != "" && auth.Type != workbench.AuthSAML && auth.Type != workbench.AuthOpenID {
+		return fmt.Errorf("the username-claim flag is only valid for saml and openid")
+	}
+	if (auth.SignInURL != "" || auth.UserHeader != "") && auth.Type != workbench.AuthProxy {
+		return fmt.Errorf("the sign-in-url and user-header flags are only valid for proxy")
+	}
+
+	return nil
+}
+
+// validateDatabase checks the flags needed for the database provider are provided, without a provider the rest is
+// prompted for
+func (opts *configOpts) validateDatabase() error {
+	database := opts.database
+	if database.Provider == "" {
+		return nil
+	}
+	if !lo.Contains(workbench.DatabaseProviders, database.Provider) {
+		return fmt.Errorf("invalid provider provided, please provide one of the following: %s", strings.Join(workbench.DatabaseProviders, ", "))
+	}
+
+	if database.Provider == workbench.DatabaseSQLite {
+		if database.Host != "" || database.Port != "" || database.Database != "" || database.User != "" || database.EncryptPassword || database.MigrateSQLite || opts.passwordFile != "" {
+			return fmt.Errorf("the host, port, database, user, password-file, encrypt-password and migrate-sqlite flags are only valid for postgresql")
+		}
+		return nil
+	}
+
+	// the host, database, user and password-file flags are required for postgresql
+	if database.Host == "" || database.Database == "" || database.User == "" || opts.passwordFile == "" {
+		return fmt.Errorf("the host, database, user and password-file flags are required for postgresql")
+	}
+	if database.Port != "" {
+		port, err := strconv.Atoi(database.Port)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("the port flag must be a number between 1 and 65535")
+		}
+	}
+	return nil
+}
+
+// validateLauncher checks each profile is valid for the plugin and only the plugin's flags are provided. Kubernetes
+// needs the API and a token, directly or through a kubeconfig, while Slurm finds its commands and slurm.conf.
+func (opts *configOpts) validateLauncher(plugin string) error {
+	profiles, err := parseProfiles(opts.profiles)
+	if err != nil {
+		return err
+	}
+	if err := launcher.ValidateProfiles(plugin, profiles); err != nil {
+		return err
+	}
+
+	kubernetesFlags := opts.launcher.apiURL != "" || opts.launcher.namespace != "" || opts.launcher.tokenFile != "" ||
+		opts.launcher.kubeconfig != "" || opts.launcher.caCertPath != "" || opts.launcher.insecureSkipVerify
+	slurmFlags := opts.launcher.binPath != "" || opts.launcher.slurmConf != "" || opts.launcher.serviceUser != "" || opts.launcher.partition != ""
+
+	if plugin == launcher.PluginSlurm {
+		// the api-url, namespace, token-file, kubeconfig, ca-cert and insecure-skip-verify flags are only valid for kubernetes
+		if kubernetesFlags {
+			return fmt.Errorf("the api-url, namespace, token-file, kubeconfig, ca-cert and insecure-skip-verify flags are only valid for kubernetes")
+		}
+		return nil
+	}
+
+	// the bin-path, slurm-conf, service-user and partition flags are only valid for slurm
+	if slurmFlags {
+		return fmt.Errorf("the bin-path, slurm-conf, service-user and partition flags are only valid for slurm")
+	}
+	// the api-url and token-file flags are required for kubernetes unless a kubeconfig is provided
+	if opts.launcher.kubeconfig == "" && (opts.launcher.apiURL == "" || opts.launcher.tokenFile == "") {
+		return fmt.Errorf("the api-url and token-file flags, or the kubeconfig flag, are required for kubernetes")
+	}
+	if opts.launcher.caCertPath != "" && opts.launcher.insecureSkipVerify {
+		return fmt.Errorf("the ca-cert and insecure-skip-verify flags cannot be used together")
+	}
 	return nil
 }
 
@@ -129,11 +530,46 @@ func newConfigCmd() *configCmd {
 		"",
 		"To configure a default Posit Connect server:",
 		"  wbi config connect-url --url [CONNECT-SERVER-URL]",
+		"",
+		"To list the versions of R installed in /opt/R in /etc/rstudio/r-versions, keeping any existing entries:",
+		"  wbi config r-versions --label \"R {version}\"",
+		"",
+		"To only offer those versions and default to the version of R symlinked to /usr/local/bin/R:",
+		"  wbi config r-versions --disable-scan --set-default",
+		"",
+		"To configure authentication interactively:",
+		"  wbi config auth",
+		"",
+		"To configure SAML or OpenID Connect single sign-on:",
+		"  wbi config auth --type saml --metadata-url [IDP-METADATA-URL]",
+		"  wbi config auth --type openid --issuer [ISSUER-URL] --client-id [CLIENT-ID] --client-secret-file [PATH-TO-CLIENT-SECRET-FILE]",
+		"",
+		"To configure proxied authentication, or return to PAM:",
+		"  wbi config auth --type proxy --sign-in-url [PROXY-SIGN-IN-URL]",
+		"  wbi config auth --type pam",
+		"",
+		"To join an Active Directory domain with realmd and SSSD, prompting for anything not provided:",
+		"  wbi config ad --domain [DOMAIN] --join-user [USER] --verify-user [DOMAIN-USER]",
+		"",
+		"To store Workbench's data in an external PostgreSQL database, encrypting the password and migrating the existing SQLite data:",
+		"  wbi config database --provider postgresql --host [HOST] --database [DATABASE] --user [USER] --password-file [PATH-TO-PASSWORD-FILE] --encrypt-password --migrate-sqlite",
+		"",
+		"To load balance Workbench across several nodes sharing an NFS directory, then join the other nodes with wbi join:",
+		"  wbi config load-balancer --shared-storage-path [NFS-DIRECTORY] --cluster-dir [NFS-DIRECTORY]/wbi-cluster",
+		"",
+		"To run sessions on Kubernetes with the Job Launcher, limiting a group's resources and images:",
+		"  wbi config launcher kubernetes --api-url [API-URL] --token-file [PATH-TO-TOKEN-FILE] --ca-cert [PATH-TO-CA-CERTIFICATE] --callback-address [WORKBENCH-URL] --profile \"@[GROUP]:max-cpus=4,max-mem-mb=8192,image=[IMAGE]\"",
+		"",
+		"To use the current context of a kubeconfig instead:",
+		"  wbi config launcher kubernetes --kubeconfig [PATH-TO-KUBECONFIG] --callback-address [WORKBENCH-URL]",
+		"",
+		"To run sessions on Slurm with the Job Launcher, checking the partition and setting everyone's default and maximum resources:",
+		"  wbi config launcher slurm --partition [PARTITION] --callback-address [WORKBENCH-URL] --profile \"*:default-cpus=1,default-mem-mb=2048,max-cpus=8,max-mem-mb=32768\"",
 	}
 
 	cmd := &cobra.Command{
-		Use:     "config [item]",
-		Short:   "Configure SSL, package repos, or a Connect server in Posit Workbench",
+		Use:     "config [item] [plugin]",
+		Short:   "Configure SSL, package repos, a Connect server, R versions, authentication, Active Directory, the database, load balancing or the Job Launcher in Posit Workbench",
 		Example: strings.Join(exampleText, "\n"),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			setConfigOpts(&root.opts)
@@ -144,7 +580,11 @@ func newConfigCmd() *configCmd {
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
 			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("config-opts")
-			if err := newConfig(root.opts, strings.ToLower(args[0])); err != nil {
+			plugin := ""
+			if len(args) > 1 {
+				plugin = strings.ToLower(args[1])
+			}
+			if err := newConfig(root.opts, strings.ToLower(args[0]), plugin); err != nil {
 				return err
 			}
 			return nil
@@ -164,6 +604,134 @@ func newConfigCmd() *configCmd {
 	cmd.Flags().StringP("source", "s", "", "Repository source (cran or pypi)")
 	viper.BindPFlag("source", cmd.Flags().Lookup("source"))
 
+	cmd.Flags().StringP("label", "", "", "Label for each new r-versions entry, {version} is replaced with the version of R")
+	viper.BindPFlag("r-versions-label", cmd.Flags().Lookup("label"))
+
+	cmd.Flags().StringP("module", "", "", "Environment module loaded by each new r-versions entry, {version} is replaced with the version of R")
+	viper.BindPFlag("r-versions-module", cmd.Flags().Lookup("module"))
+
+	cmd.Flags().StringP("script", "", "", "Environment script run by each new r-versions entry, {version} is replaced with the version of R")
+	viper.BindPFlag("r-versions-script", cmd.Flags().Lookup("script"))
+
+	cmd.Flags().BoolP("disable-scan", "", false, "Only offer the versions of R listed in r-versions (sets r-versions-scan=0)")
+	viper.BindPFlag("r-versions-disable-scan", cmd.Flags().Lookup("disable-scan"))
+
+	cmd.Flags().BoolP("set-default", "", false, "Set rsession-which-r to the version of R symlinked to /usr/local/bin/R")
+	viper.BindPFlag("r-versions-set-default", cmd.Flags().Lookup("set-default"))
+
+	cmd.Flags().StringP("type", "", "", "Authentication type ("+strings.Join(workbench.AuthTypes, ", ")+"), prompted for when not provided")
+	viper.BindPFlag("auth-type", cmd.Flags().Lookup("type"))
+
+	cmd.Flags().StringP("metadata-url", "", "", "URL of the SAML identity provider metadata")
+	viper.BindPFlag("auth-metadata-url", cmd.Flags().Lookup("metadata-url"))
+
+	cmd.Flags().StringP("issuer", "", "", "OpenID Connect issuer URL")
+	viper.BindPFlag("auth-issuer", cmd.Flags().Lookup("issuer"))
+
+	cmd.Flags().StringP("client-id", "", "", "OpenID Connect client ID")
+	viper.BindPFlag("auth-client-id", cmd.Flags().Lookup("client-id"))
+
+	cmd.Flags().StringP("client-secret", "", "", "OpenID Connect client secret")
+	viper.BindPFlag("auth-client-secret", cmd.Flags().Lookup("client-secret"))
+	// the secret would be visible in the process list and shell history
+	cmd.Flags().MarkDeprecated("client-secret", "use --client-secret-file so the secret stays out of the process list and shell history")
+
+	cmd.Flags().StringP("client-secret-file", "", "", "File containing the OpenID Connect client secret")
+	viper.BindPFlag("auth-client-secret-file", cmd.Flags().Lookup("client-secret-file"))
+
+	cmd.Flags().StringP("username-claim", "", "", "OpenID Connect claim or SAML attribute to use as the username")
+	viper.BindPFlag("auth-username-claim", cmd.Flags().Lookup("username-claim"))
+
+	cmd.Flags().StringP("sign-in-url", "", "", "URL of the authenticating proxy's sign in page")
+	viper.BindPFlag("auth-sign-in-url", cmd.Flags().Lookup("sign-in-url"))
+
+	cmd.Flags().StringP("user-header", "", "", "Header the authenticating proxy sets to the username")
+	viper.BindPFlag("auth-user-header", cmd.Flags().Lookup("user-header"))
+
+	cmd.Flags().StringP("domain", "", "", "Active Directory domain to join, such as ad.example.com")
+	viper.BindPFlag("ad-domain", cmd.Flags().Lookup("domain"))
+
+	cmd.Flags().StringP("join-user", "", "", "Domain user allowed to join computers to the domain")
+	viper.BindPFlag("ad-join-user", cmd.Flags().Lookup("join-user"))
+
+	cmd.Flags().StringP("password-file", "", "", "File containing the password of the join user or database user, prompted for when not provided")
+	viper.BindPFlag("password-file", cmd.Flags().Lookup("password-file"))
+
+	cmd.Flags().StringP("computer-ou", "", "", "Organizational unit for the computer account")
+	viper.BindPFlag("ad-computer-ou", cmd.Flags().Lookup("computer-ou"))
+
+	cmd.Flags().StringP("verify-user", "", "", "Domain user to look up once joined, and to verify Workbench with when it is installed")
+	viper.BindPFlag("ad-verify-user", cmd.Flags().Lookup("verify-user"))
+
+	cmd.Flags().StringP("provider", "", "", "Database provider ("+strings.Join(workbench.DatabaseProviders, ", ")+"), prompted for when not provided")
+	viper.BindPFlag("database-provider", cmd.Flags().Lookup("provider"))
+
+	cmd.Flags().StringP("host", "", "", "PostgreSQL host")
+	viper.BindPFlag("database-host", cmd.Flags().Lookup("host"))
+
+	cmd.Flags().StringP("port", "", "", "PostgreSQL port (default "+workbench.DefaultPostgreSQLPort+")")
+	viper.BindPFlag("database-port", cmd.Flags().Lookup("port"))
+
+	cmd.Flags().StringP("database", "", "", "PostgreSQL database")
+	viper.BindPFlag("database-name", cmd.Flags().Lookup("database"))
+
+	cmd.Flags().StringP("user", "", "", "PostgreSQL user")
+	viper.BindPFlag("database-user", cmd.Flags().Lookup("user"))
+
+	cmd.Flags().BoolP("encrypt-password", "", false, "Store the database password encrypted with rstudio-server encrypt-password")
+	viper.BindPFlag("database-encrypt-password", cmd.Flags().Lookup("encrypt-password"))
+
+	cmd.Flags().BoolP("migrate-sqlite", "", false, "Keep the existing SQLite data for Workbench to migrate into PostgreSQL, otherwise it is moved aside")
+	viper.BindPFlag("database-migrate-sqlite", cmd.Flags().Lookup("migrate-sqlite"))
+
+	cmd.Flags().StringP("shared-storage-path", "", "", "NFS directory shared by every node, set as server-shared-storage-path")
+	viper.BindPFlag("lb-shared-storage-path", cmd.Flags().Lookup("shared-storage-path"))
+
+	cmd.Flags().StringP("cluster-dir", "", "", "Shared directory the configuration and keys are copied to for wbi join, readable only by root")
+	viper.BindPFlag("lb-cluster-dir", cmd.Flags().Lookup("cluster-dir"))
+
+	cmd.Flags().StringP("balancer", "", "", "Load balancing method ("+strings.Join(workbench.Balancers, ", ")+"), defaults to sessions")
+	viper.BindPFlag("lb-balancer", cmd.Flags().Lookup("balancer"))
+
+	cmd.Flags().StringP("host-name", "", "", "Address the other nodes reach this node at, set as www-host-name")
+	viper.BindPFlag("lb-host-name", cmd.Flags().Lookup("host-name"))
+
+	cmd.Flags().StringP("api-url", "", "", "Kubernetes API URL, such as https://kubernetes.example.com:6443")
+	viper.BindPFlag("k8s-api-url", cmd.Flags().Lookup("api-url"))
+
+	cmd.Flags().StringP("namespace", "", "", "Kubernetes namespace sessions and jobs run in (default "+launcher.DefaultKubernetesNamespace+")")
+	viper.BindPFlag("k8s-namespace", cmd.Flags().Lookup("namespace"))
+
+	cmd.Flags().StringP("token-file", "", "", "File containing the token of the Launcher's Kubernetes service account")
+	viper.BindPFlag("k8s-token-file", cmd.Flags().Lookup("token-file"))
+
+	cmd.Flags().StringP("kubeconfig", "", "", "kubeconfig whose current context provides the API URL, token, namespace and certificate authority not set with flags")
+	viper.BindPFlag("k8s-kubeconfig", cmd.Flags().Lookup("kubeconfig"))
+
+	cmd.Flags().StringP("ca-cert", "", "", "PEM certificate authority of the Kubernetes API, the system trust store is used when not provided")
+	viper.BindPFlag("k8s-ca-cert", cmd.Flags().Lookup("ca-cert"))
+
+	cmd.Flags().BoolP("insecure-skip-verify", "", false, "Do not verify the Kubernetes API certificate (sets verify-ssl-certs=0)")
+	viper.BindPFlag("k8s-insecure-skip-verify", cmd.Flags().Lookup("insecure-skip-verify"))
+
+	cmd.Flags().StringP("bin-path", "", "", "Directory of the Slurm commands, found from sinfo on the PATH when not provided")
+	viper.BindPFlag("slurm-bin-path", cmd.Flags().Lookup("bin-path"))
+
+	cmd.Flags().StringP("slurm-conf", "", "", "slurm.conf to check, found from SLURM_CONF or /etc/slurm when not provided")
+	viper.BindPFlag("slurm-conf", cmd.Flags().Lookup("slurm-conf"))
+
+	cmd.Flags().StringP("service-user", "", "", "Slurm user the Launcher queries every user's jobs as, set as slurm-service-user")
+	viper.BindPFlag("slurm-service-user", cmd.Flags().Lookup("service-user"))
+
+	cmd.Flags().StringP("partition", "", "", "Slurm partition to check and submit the validation job to, defaults to Slurm's default partition")
+	viper.BindPFlag("slurm-partition", cmd.Flags().Lookup("partition"))
+
+	cmd.Flags().StringP("callback-address", "", "", "URL sessions reach Workbench at, set as launcher-sessions-callback-address")
+	viper.BindPFlag("launcher-callback-address", cmd.Flags().Lookup("callback-address"))
+
+	cmd.Flags().StringArrayP("profile", "", nil, "Session profile for everyone (*), a group (@GROUP) or a user, such as \"@[GROUP]:default-cpus=1,max-cpus=4,max-mem-mb=8192,image=[IMAGE]\", can be repeated")
+	viper.BindPFlag("launcher-profiles", cmd.Flags().Lookup("profile"))
+
 	root.cmd = cmd
 	return root
 }
@@ -3,6 +3,9 @@ package cmd
 import (
 	"testing"
 
+	"github.com/sol-eng/wbi/internal/activedirectory"
+	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/workbench"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,6 +110,262 @@ func TestConfigParamsValidate(t *testing.T) {
 			flags:       configOpts{url: "https://packagemanager.posit.co", keyPath: "cert.key"},
 			expectError: "the key-path flag is only valid for ssl",
 		},
+		// r-versions argument tests
+		"r-versions argument only succeeds": {
+			args:        []string{"r-versions"},
+			flags:       configOpts{},
+			expectError: "",
+		},
+		"r-versions argument with label, module, script, disable-scan and set-default flags succeeds": {
+			args:        []string{"r-versions"},
+			flags:       configOpts{rVersions: languages.RVersionsOptions{Label: "R {version}", Module: "R/{version}", Script: "/etc/profile.d/r.sh", DisableScan: true, SetDefault: true}},
+			expectError: "",
+		},
+		"r-versions argument with a url flag fails": {
+			args:        []string{"r-versions"},
+			flags:       configOpts{url: "https://packagemanager.posit.co"},
+			expectError: "the url flag is only valid for repo, connect-url and url",
+		},
+		"connect-url argument with a disable-scan flag fails": {
+			args:        []string{"connect-url"},
+			flags:       configOpts{url: "https://colorado.posit.co/rsc", rVersions: languages.RVersionsOptions{DisableScan: true}},
+			expectError: "the label, module, script, disable-scan and set-default flags are only valid for r-versions",
+		},
+		// auth argument tests
+		"auth argument only succeeds": {
+			args:        []string{"auth"},
+			flags:       configOpts{},
+			expectError: "",
+		},
+		"auth argument with an invalid type fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "ldap"}},
+			expectError: "invalid type provided, please provide one of the following: saml, openid, pam, proxy",
+		},
+		"auth argument with saml type only fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "saml"}},
+			expectError: "the metadata-url flag is required for saml",
+		},
+		"auth argument with saml type and metadata-url flag succeeds": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "saml", MetadataURL: "https://idp.example.com/metadata"}},
+			expectError: "",
+		},
+		"auth argument with openid type and no client-secret fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "openid", Issuer: "https://idp.example.com", ClientID: "workbench"}},
+			expectError: "the issuer, client-id and client-secret-file flags are required for openid",
+		},
+		"auth argument with openid type and client-secret-file flag succeeds": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "openid", Issuer: "https://idp.example.com", ClientID: "workbench"}, clientSecretFile: "/root/client-secret"},
+			expectError: "",
+		},
+		"auth argument with client-secret and client-secret-file flags fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "openid", Issuer: "https://idp.example.com", ClientID: "workbench", ClientSecret: "secret"}, clientSecretFile: "/root/client-secret"},
+			expectError: "the client-secret and client-secret-file flags can't be used together",
+		},
+		"auth argument with saml type and client-secret-file flag fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "saml", MetadataURL: "https://idp.example.com/metadata"}, clientSecretFile: "/root/client-secret"},
+			expectError: "the client-secret-file flag is only valid for openid",
+		},
+		"ssl argument with a client-secret-file flag fails": {
+			args:        []string{"ssl"},
+			flags:       configOpts{certPath: "cert.crt", keyPath: "cert.key", url: "myserverurl.com", clientSecretFile: "/root/client-secret"},
+			expectError: "the client-secret-file flag is only valid for auth",
+		},
+		"auth argument with openid type and a sign-in-url flag fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "openid", Issuer: "https://idp.example.com", ClientID: "workbench", ClientSecret: "secret", SignInURL: "https://proxy.example.com"}},
+			expectError: "the sign-in-url and user-header flags are only valid for proxy",
+		},
+		"auth argument with pam type and an issuer flag fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "pam", Issuer: "https://idp.example.com"}},
+			expectError: "the issuer, client-id and client-secret flags are only valid for openid",
+		},
+		"auth argument with proxy type only fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "proxy"}},
+			expectError: "the sign-in-url flag is required for proxy",
+		},
+		"ssl argument with a type flag fails": {
+			args:        []string{"ssl"},
+			flags:       configOpts{certPath: "cert.crt", keyPath: "cert.key", url: "myserverurl.com", auth: workbench.AuthOptions{Type: "pam"}},
+			expectError: "the type, metadata-url, issuer, client-id, client-secret, username-claim, sign-in-url and user-header flags are only valid for auth",
+		},
+		// ad argument tests
+		"ad argument only succeeds": {
+			args:        []string{"ad"},
+			flags:       configOpts{},
+			expectError: "",
+		},
+		"ad argument with domain, join-user, password-file and verify-user flags succeeds": {
+			args:        []string{"ad"},
+			flags:       configOpts{ad: activedirectory.Options{Domain: "ad.example.com", JoinUser: "administrator", VerifyUser: "jdoe"}, passwordFile: "/root/ad-password"},
+			expectError: "",
+		},
+		"ad argument with a domain that is not a DNS name fails": {
+			args:        []string{"ad"},
+			flags:       configOpts{ad: activedirectory.Options{Domain: "ad.example.com; reboot"}},
+			expectError: "invalid domain \"ad.example.com; reboot\", please provide a DNS domain name such as ad.example.com",
+		},
+		"ad argument with a join-user that is not a sAMAccountName fails": {
+			args:        []string{"ad"},
+			flags:       configOpts{ad: activedirectory.Options{Domain: "ad.example.com", JoinUser: "admin;reboot"}},
+			expectError: "invalid join user \"admin;reboot\", please provide the user's sAMAccountName such as administrator",
+		},
+		"auth argument with a domain flag fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{auth: workbench.AuthOptions{Type: "pam"}, ad: activedirectory.Options{Domain: "ad.example.com"}},
+			expectError: "the domain, join-user, computer-ou and verify-user flags are only valid for ad",
+		},
+		"r-versions argument with a password-file flag fails": {
+			args:        []string{"r-versions"},
+			flags:       configOpts{passwordFile: "/root/ad-password"},
+			expectError: "the password-file flag is only valid for ad and database",
+		},
+		// database argument tests
+		"database argument only succeeds": {
+			args:        []string{"database"},
+			flags:       configOpts{},
+			expectError: "",
+		},
+		"database argument with postgresql flags succeeds": {
+			args:        []string{"database"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Provider: "postgresql", Host: "db.example.com", Port: "5433", Database: "rstudio", User: "rstudio", EncryptPassword: true, MigrateSQLite: true}, passwordFile: "/root/db-password"},
+			expectError: "",
+		},
+		"database argument with sqlite provider succeeds": {
+			args:        []string{"database"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Provider: "sqlite"}},
+			expectError: "",
+		},
+		"database argument with an invalid provider fails": {
+			args:        []string{"database"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Provider: "mysql"}},
+			expectError: "invalid provider provided, please provide one of the following: postgresql, sqlite",
+		},
+		"database argument with postgresql and no password-file fails": {
+			args:        []string{"database"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Provider: "postgresql", Host: "db.example.com", Database: "rstudio", User: "rstudio"}},
+			expectError: "the host, database, user and password-file flags are required for postgresql",
+		},
+		"database argument with an invalid port fails": {
+			args:        []string{"database"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Provider: "postgresql", Host: "db.example.com", Port: "postgres", Database: "rstudio", User: "rstudio"}, passwordFile: "/root/db-password"},
+			expectError: "the port flag must be a number between 1 and 65535",
+		},
+		"database argument with sqlite and a host flag fails": {
+			args:        []string{"database"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Provider: "sqlite", Host: "db.example.com"}},
+			expectError: "the host, port, database, user, password-file, encrypt-password and migrate-sqlite flags are only valid for postgresql",
+		},
+		// load-balancer argument tests
+		"load-balancer argument with shared-storage-path and cluster-dir flags succeeds": {
+			args:        []string{"load-balancer"},
+			flags:       configOpts{loadBalancer: workbench.LoadBalancerOptions{SharedStoragePath: "/shared/rstudio", ClusterDir: "/shared/wbi-cluster", Balancer: "user-sessions", HostName: "node1.example.com"}},
+			expectError: "",
+		},
+		"load-balancer argument only fails": {
+			args:        []string{"load-balancer"},
+			flags:       configOpts{},
+			expectError: "the shared-storage-path and cluster-dir flags are required for load-balancer",
+		},
+		"load-balancer argument with an invalid balancer fails": {
+			args:        []string{"load-balancer"},
+			flags:       configOpts{loadBalancer: workbench.LoadBalancerOptions{SharedStoragePath: "/shared/rstudio", ClusterDir: "/shared/wbi-cluster", Balancer: "round-robin"}},
+			expectError: "the balancer flag only allows sessions, user-sessions, system-load",
+		},
+		"database argument with a cluster-dir flag fails": {
+			args:        []string{"database"},
+			flags:       configOpts{loadBalancer: workbench.LoadBalancerOptions{ClusterDir: "/shared/wbi-cluster"}},
+			expectError: "the shared-storage-path, cluster-dir, balancer and host-name flags are only valid for load-balancer",
+		},
+		"auth argument with a host flag fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{database: workbench.DatabaseOptions{Host: "db.example.com"}},
+			expectError: "the provider, host, port, database, user, encrypt-password and migrate-sqlite flags are only valid for database",
+		},
+		// launcher argument tests
+		"launcher kubernetes arguments with api-url, token-file and profile flags succeeds": {
+			args:        []string{"launcher", "kubernetes"},
+			flags:       configOpts{launcher: launcherFlags{apiURL: "https://kubernetes.example.com:6443", tokenFile: "/root/token", callbackAddress: "https://workbench.example.com"}, profiles: []string{"@data-science:max-cpus=4,max-mem-mb=8192,image=rstudio/r-session-complete:jammy"}},
+			expectError: "",
+		},
+		"launcher kubernetes arguments with a kubeconfig flag succeeds": {
+			args:        []string{"launcher", "Kubernetes"},
+			flags:       configOpts{launcher: launcherFlags{kubeconfig: "/root/.kube/config"}},
+			expectError: "",
+		},
+		"launcher argument only fails": {
+			args:        []string{"launcher"},
+			flags:       configOpts{},
+			expectError: "the launcher item requires a plugin, please provide one of the following: kubernetes, slurm",
+		},
+		"launcher argument with an invalid plugin fails": {
+			args:        []string{"launcher", "nomad"},
+			flags:       configOpts{},
+			expectError: "the launcher item requires a plugin, please provide one of the following: kubernetes, slurm",
+		},
+		"launcher kubernetes arguments with too many arguments fails": {
+			args:        []string{"launcher", "kubernetes", "slurm"},
+			flags:       configOpts{launcher: launcherFlags{kubeconfig: "/root/.kube/config"}},
+			expectError: "too many arguments provided, please provide only the launcher item and a plugin",
+		},
+		"launcher kubernetes arguments without a token-file flag fails": {
+			args:        []string{"launcher", "kubernetes"},
+			flags:       configOpts{launcher: launcherFlags{apiURL: "https://kubernetes.example.com:6443"}},
+			expectError: "the api-url and token-file flags, or the kubeconfig flag, are required for kubernetes",
+		},
+		"launcher kubernetes arguments with ca-cert and insecure-skip-verify flags fails": {
+			args:        []string{"launcher", "kubernetes"},
+			flags:       configOpts{launcher: launcherFlags{kubeconfig: "/root/.kube/config", caCertPath: "/root/ca.crt", insecureSkipVerify: true}},
+			expectError: "the ca-cert and insecure-skip-verify flags cannot be used together",
+		},
+		"launcher kubernetes arguments with an invalid profile fails": {
+			args:        []string{"launcher", "kubernetes"},
+			flags:       configOpts{launcher: launcherFlags{kubeconfig: "/root/.kube/config"}, profiles: []string{"@data-science:max-gpus=1"}},
+			expectError: "unknown setting max-gpus",
+		},
+		"launcher slurm arguments only succeeds": {
+			args:        []string{"launcher", "slurm"},
+			flags:       configOpts{},
+			expectError: "",
+		},
+		"launcher slurm arguments with partition, service-user and profile flags succeeds": {
+			args:        []string{"launcher", "slurm"},
+			flags:       configOpts{launcher: launcherFlags{partition: "cpu", serviceUser: "slurm", binPath: "/usr/bin", callbackAddress: "https://workbench.example.com"}, profiles: []string{"*:default-cpus=1,default-mem-mb=2048,max-cpus=8"}},
+			expectError: "",
+		},
+		"launcher slurm arguments with an image profile fails": {
+			args:        []string{"launcher", "slurm"},
+			flags:       configOpts{profiles: []string{"*:image=rstudio/r-session-complete:jammy"}},
+			expectError: "image is only valid for kubernetes",
+		},
+		"launcher slurm arguments with a namespace flag fails": {
+			args:        []string{"launcher", "slurm"},
+			flags:       configOpts{launcher: launcherFlags{namespace: "rstudio"}},
+			expectError: "the api-url, namespace, token-file, kubeconfig, ca-cert and insecure-skip-verify flags are only valid for kubernetes",
+		},
+		"launcher kubernetes arguments with a partition flag fails": {
+			args:        []string{"launcher", "kubernetes"},
+			flags:       configOpts{launcher: launcherFlags{kubeconfig: "/root/.kube/config", partition: "cpu"}},
+			expectError: "the bin-path, slurm-conf, service-user and partition flags are only valid for slurm",
+		},
+		"ssl argument with a namespace flag fails": {
+			args:        []string{"ssl"},
+			flags:       configOpts{certPath: "/path/to/cert", keyPath: "/path/to/key", url: "https://example.com", launcher: launcherFlags{namespace: "rstudio"}},
+			expectError: "the api-url, namespace, token-file, kubeconfig, ca-cert, insecure-skip-verify, bin-path, slurm-conf, service-user, partition, callback-address and profile flags are only valid for launcher",
+		},
+		"auth argument with a profile flag fails": {
+			args:        []string{"auth"},
+			flags:       configOpts{profiles: []string{"*:max-cpus=2"}},
+			expectError: "the api-url, namespace, token-file, kubeconfig, ca-cert, insecure-skip-verify, bin-path, slurm-conf, service-user, partition, callback-address and profile flags are only valid for launcher",
+		},
 	}
 
 	for name, tc := range tests {
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/doctor"
+	"github.com/sol-eng/wbi/internal/output"
+	"github.com/spf13/cobra"
+)
+
+type doctorCmd struct {
+	cmd  *cobra.Command
+	opts doctorOpts
+}
+
+type doctorOpts struct {
+}
+
+func newDoctor(doctorOpts doctorOpts) error {
+	results := doctor.Run(doctor.Checks())
+	if output.Structured() {
+		err := output.Print(results)
+		if err != nil {
+			return err
+		}
+	} else {
+		doctor.WriteReport(os.Stdout, results)
+	}
+
+	// the exit code reflects the worst result, 1 for warnings and 2 for failures
+	worst := doctor.Worst(results)
+	if worst != doctor.Pass {
+		return &exitCodeError{code: int(worst), err: fmt.Errorf("the health report has checks with the status %s", worst)}
+	}
+	return nil
+}
+
+func setDoctorOpts(doctorOpts *doctorOpts) {
+
+}
+
+func (opts *doctorOpts) Validate(args []string) error {
+	// check args lengths
+	if len(args) > 0 {
+		return fmt.Errorf("too many arguments provided, doctor does not take any arguments")
+	}
+	return nil
+}
+
+func newDoctorCmd() *doctorCmd {
+	var doctorOpts doctorOpts
+
+	root := &doctorCmd{opts: doctorOpts}
+
+	// adding two spaces to have consistent formatting
+	exampleText := []string{
+		"To check the health of Workbench, the languages and integrations it uses, and its configuration:",
+		"  wbi doctor",
+	}
+
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "Report the health of every component in a single run",
+		Example: strings.Join(exampleText, "\n"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			setDoctorOpts(&root.opts)
+			if err := root.opts.Validate(args); err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("doctor-opts")
+			if err := newDoctor(root.opts); err != nil {
+				return err
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		// the report already describes the failures
+		SilenceErrors: true,
+	}
+
+	root.cmd = cmd
+	return root
+}
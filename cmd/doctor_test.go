@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDoctorParamsValidate tests the doctor command parameters
+func TestDoctorParamsValidate(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		flags       doctorOpts
+		expectError string
+	}{
+		"no argument succeeds": {
+			args:        []string{},
+			flags:       doctorOpts{},
+			expectError: "",
+		},
+		"an argument fails": {
+			args:        []string{"workbench"},
+			flags:       doctorOpts{},
+			expectError: "too many arguments provided, doctor does not take any arguments",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			doctorCmd := newDoctorCmd()
+			// set the flags
+			doctorCmd.opts = tc.flags
+			// run validation
+			err := doctorCmd.opts.Validate(tc.args)
+
+			if err != nil && tc.expectError != "" {
+				// if we expect an error, check that it contains the expected error
+				assert.Containsf(t, err.Error(), tc.expectError, "expected error containing %q, got %s", tc.expectError, err)
+			} else if err != nil && tc.expectError == "" {
+				// if we expect no error but get one then fail
+				t.Fatalf("expected no error, but got %s", err)
+			} else if err == nil && tc.expectError != "" {
+				// if we expect an error but don't get one then fail
+				t.Fatalf("expected error containing %q, but the command ran without error", tc.expectError)
+			}
+			// otherwise we expect the command to succeed so pass the test
+		})
+	}
+}
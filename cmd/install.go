@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/bundle"
+	"github.com/sol-eng/wbi/internal/config"
 	"github.com/sol-eng/wbi/internal/jupyter"
 	"github.com/sol-eng/wbi/internal/languages"
 	"github.com/sol-eng/wbi/internal/operatingsystem"
@@ -27,6 +29,7 @@ type installOpts struct {
 	path      string
 	symlink   bool
 	addToPATH bool
+	bundle    string
 }
 
 func newInstall(installOpts installOpts, program string) error {
@@ -35,6 +38,10 @@ func newInstall(installOpts installOpts, program string) error {
 	if err != nil {
 		return err
 	}
+	err = bundle.CheckOS(osType, config.HostArchitecture())
+	if err != nil {
+		return err
+	}
 
 	if program == "r" {
 		// install prereqs
@@ -154,6 +161,7 @@ func setInstallOpts(installOpts *installOpts) {
 	installOpts.path = viper.GetString("path")
 	installOpts.symlink = viper.GetBool("symlink")
 	installOpts.addToPATH = viper.GetBool("add-to-path")
+	installOpts.bundle = viper.GetString("install-bundle")
 }
 
 func (opts *installOpts) Validate(args []string) error {
@@ -179,6 +187,11 @@ func (opts *installOpts) Validate(args []string) error {
 		return fmt.Errorf("the add-to-path flag is only supported for python")
 	}
 
+	// ensure the bundle exists
+	if opts.bundle != "" && !system.VerifyFileExists(opts.bundle) {
+		return fmt.Errorf("the bundle provided does not exist")
+	}
+
 	// ensure versions are valid if provided for r, python or quarto
 	if args[0] == "r" && len(opts.versions) != 0 {
 		err := languages.ValidateRVersions(opts.versions)
@@ -259,6 +272,9 @@ func newInstallCmd() *installCmd {
 		"",
 		"To install Jupyter to a specific Python location:",
 		"  wbi install jupyter --path /path/to/python",
+		"",
+		"To install from an offline bundle on an air-gapped server:",
+		"  wbi install r --version 4.3.2 --bundle wbi-bundle-jammy.tar",
 	}
 
 	cmd := &cobra.Command{
@@ -267,6 +283,12 @@ func newInstallCmd() *installCmd {
 		Example: strings.Join(exampleText, "\n"),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			setInstallOpts(&root.opts)
+			// the bundle is loaded before validating so versions are checked against the bundle
+			if root.opts.bundle != "" && system.VerifyFileExists(root.opts.bundle) {
+				if err := bundle.Load(root.opts.bundle); err != nil {
+					return err
+				}
+			}
 			if err := root.opts.Validate(args); err != nil {
 				return err
 			}
@@ -295,6 +317,9 @@ func newInstallCmd() *installCmd {
 	cmd.Flags().BoolP("add-to-path", "a", false, "Adds the first Python version specified to users PATH by adding a file in /etc/profile.d/.")
 	viper.BindPFlag("add-to-path", cmd.Flags().Lookup("add-to-path"))
 
+	cmd.Flags().StringP("bundle", "b", "", "Path to an offline bundle created with wbi bundle create to install from instead of the network")
+	viper.BindPFlag("install-bundle", cmd.Flags().Lookup("bundle"))
+
 	root.cmd = cmd
 	return root
 }
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/workbench"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type joinCmd struct {
+	cmd  *cobra.Command
+	opts joinOpts
+}
+
+type joinOpts struct {
+	from     string
+	hostName string
+}
+
+func newJoin(joinOpts joinOpts) error {
+	err := workbench.JoinCluster(joinOpts.from, joinOpts.hostName)
+	if err != nil {
+		return fmt.Errorf("issue joining the load balanced cluster: %w", err)
+	}
+	return nil
+}
+
+func setJoinOpts(joinOpts *joinOpts) {
+	joinOpts.from = viper.GetString("join-from")
+	joinOpts.hostName = viper.GetString("join-host-name")
+}
+
+func (opts *joinOpts) Validate(args []string) error {
+	// join takes no arguments
+	if len(args) > 0 {
+		return fmt.Errorf("no arguments are supported for join")
+	}
+	// the from flag is required
+	if opts.from == "" {
+		return fmt.Errorf("the from flag is required")
+	}
+	return nil
+}
+
+func newJoinCmd() *joinCmd {
+	var joinOpts joinOpts
+
+	root := &joinCmd{opts: joinOpts}
+
+	// adding two spaces to have consistent formatting
+	exampleText := []string{
+		"To configure this node the same way as the first node of a load balanced cluster:",
+		"  wbi join --from [CLUSTER-DIR]",
+		"",
+		"To also set the address the other nodes reach this node at:",
+		"  wbi join --from [CLUSTER-DIR] --host-name [NODE-ADDRESS]",
+	}
+
+	cmd := &cobra.Command{
+		Use:     "join",
+		Short:   "Join this node to a load balanced Workbench cluster created with wbi config load-balancer",
+		Example: strings.Join(exampleText, "\n"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			setJoinOpts(&root.opts)
+			if err := root.opts.Validate(args); err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("join-opts")
+			if err := newJoin(root.opts); err != nil {
+				return err
+			}
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringP("from", "f", "", "Cluster directory written by wbi config load-balancer on the first node")
+	viper.BindPFlag("join-from", cmd.Flags().Lookup("from"))
+
+	cmd.Flags().StringP("host-name", "", "", "Address the other nodes reach this node at, set as www-host-name")
+	viper.BindPFlag("join-host-name", cmd.Flags().Lookup("host-name"))
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJoinParamsValidate tests the join command parameters
+func TestJoinParamsValidate(t *testing.T) {
+
+	tests := map[string]struct {
+		args        []string
+		flags       joinOpts
+		expectError string
+	}{
+		"no from flag": {
+			args:        []string{},
+			flags:       joinOpts{},
+			expectError: "the from flag is required",
+		},
+		"an argument": {
+			args:        []string{"cluster"},
+			flags:       joinOpts{from: "/shared/wbi-cluster"},
+			expectError: "no arguments are supported for join",
+		},
+		"from flag": {
+			args:        []string{},
+			flags:       joinOpts{from: "/shared/wbi-cluster"},
+			expectError: "",
+		},
+		"from and host-name flags": {
+			args:        []string{},
+			flags:       joinOpts{from: "/shared/wbi-cluster", hostName: "node2.example.com"},
+			expectError: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			joinCmd := newJoinCmd()
+			// set the flags
+			joinCmd.opts = tc.flags
+			// run validation
+			err := joinCmd.opts.Validate(tc.args)
+
+			if err != nil {
+				// if we expect an error, check that it contains the expected error
+				assert.Containsf(t, err.Error(), tc.expectError, "expected error containing %q, got %s", tc.expectError, err)
+				if tc.expectError == "" {
+					t.Fatalf("expected no error, but got %s", err)
+				}
+			} else if tc.expectError != "" {
+				// if we expect an error but don't get one then fail
+				t.Fatalf("expected error containing %q, but the command ran without error", tc.expectError)
+			}
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/install"
+	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/mirrors"
+	"github.com/sol-eng/wbi/internal/output"
+	"github.com/sol-eng/wbi/internal/system"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,6 +20,20 @@ import (
 type settings struct {
 	// logrus log level
 	loglevel string
+	// format results are printed in, text, json or yaml
+	output string
+	// directory downloaded installers are cached in
+	cacheDir string
+	// proxy used for HTTP and HTTPS requests
+	proxy string
+	// PEM file of additional trusted certificate authorities
+	caBundle string
+	// file remapping upstream sources to mirrors
+	mirrorsFile string
+	// name=url mirrors overriding the mirrors file
+	mirrors []string
+	// record mutating actions in a plan instead of executing them
+	dryRun bool
 }
 
 type rootCmd struct {
@@ -26,7 +47,14 @@ func Execute(version string, args []string) {
 
 func (cmd *rootCmd) Execute(args []string) {
 	cmd.cmd.SetArgs(args)
-	if err := cmd.cmd.Execute(); err != nil {
+	err := cmd.cmd.Execute()
+	printDryRunPlan()
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		log.Error(exitErr.Error())
+		os.Exit(exitErr.code)
+	}
+	if err != nil {
 		// if get to this point and don't fatally log in the subcommand,
 		// the Usage help will be printed before the error,
 		// which may or may not be the desired behavior
@@ -34,23 +62,83 @@ func (cmd *rootCmd) Execute(args []string) {
 	}
 }
 
-func setGlobalSettings(cfg *settings) {
+// printDryRunPlan prints the plan of a dry run with the other messages, so it stays out of structured output
+func printDryRunPlan() {
+	if system.DryRun() {
+		system.PrintPlan(system.MessageOutput())
+	}
+}
+
+// exitCodeError is returned by commands that report their result through the exit code
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+func setGlobalSettings(cfg *settings, version string) error {
 	cfg.loglevel = viper.GetString("loglevel")
 	setLogLevel(cfg.loglevel)
 	setUpLogger()
+	cfg.output = viper.GetString("output")
+	err := setOutput(cfg.output)
+	if err != nil {
+		return err
+	}
+	cfg.proxy = viper.GetString("proxy")
+	cfg.caBundle = viper.GetString("ca-bundle")
+	err = httpclient.Configure(httpclient.Options{Proxy: cfg.proxy, CABundle: cfg.caBundle, Version: version})
+	if err != nil {
+		return err
+	}
+	cfg.mirrorsFile = viper.GetString("mirrors-file")
+	cfg.mirrors = viper.GetStringSlice("mirror")
+	err = mirrors.Configure(cfg.mirrorsFile, cfg.mirrors)
+	if err != nil {
+		return err
+	}
+	cfg.cacheDir = viper.GetString("cache-dir")
+	install.SetCacheDir(cfg.cacheDir)
+	cfg.dryRun = viper.GetBool("dry-run")
+	system.SetDryRun(cfg.dryRun)
+	if cfg.dryRun {
+		cmdlog.Disable()
+	}
+	return nil
+}
+
+// setOutput sets the output format, structured results keep stdout to themselves so messages, the output of commands
+// and the dry-run plan are printed to stderr
+func setOutput(name string) error {
+	err := output.Set(name)
+	if err != nil {
+		return err
+	}
+	if output.Structured() {
+		system.SetMessageOutput(os.Stderr)
+	}
+	return nil
 }
+
 func newRootCmd(version string) *rootCmd {
 	root := &rootCmd{cfg: &settings{}}
 	cmd := &cobra.Command{
 		Use:   "wbi",
 		Short: "workbench installer",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// need to set the config values here as the viper values
 			// will not be processed until Execute, so can't
 			// set them in the initializer.
 			// If persistentPreRun is used elsewhere, should
 			// remember to setGlobalSettings in the initializer
-			setGlobalSettings(root.cfg)
+			return setGlobalSettings(root.cfg, version)
 		},
 	}
 	cmd.Version = version
@@ -59,12 +147,32 @@ func newRootCmd(version string) *rootCmd {
 	cmd.SetVersionTemplate(`{{printf "%s\n" .Version}}`)
 	cmd.PersistentFlags().String("loglevel", "info", "log level")
 	viper.BindPFlag("loglevel", cmd.PersistentFlags().Lookup("loglevel"))
+	cmd.PersistentFlags().String("output", string(output.Text), "Format results are printed in: text, json or yaml")
+	viper.BindPFlag("output", cmd.PersistentFlags().Lookup("output"))
+	cmd.PersistentFlags().String("proxy", "", "Proxy URL for wbi's HTTP and HTTPS requests, overriding the HTTP_PROXY and HTTPS_PROXY environment variables")
+	viper.BindPFlag("proxy", cmd.PersistentFlags().Lookup("proxy"))
+	cmd.PersistentFlags().String("ca-bundle", "", "PEM file of certificate authorities to trust in addition to the system trust store, such as a TLS-intercepting proxy's CA")
+	viper.BindPFlag("ca-bundle", cmd.PersistentFlags().Lookup("ca-bundle"))
+	cmd.PersistentFlags().String("mirrors-file", mirrors.DefaultFile, "YAML file mapping upstream sources to internal mirrors")
+	viper.BindPFlag("mirrors-file", cmd.PersistentFlags().Lookup("mirrors-file"))
+	cmd.PersistentFlags().StringSlice("mirror", []string{}, "Mirror for an upstream source as name=url, such as posit-cdn=https://mirror.example.com/posit, can be repeated")
+	viper.BindPFlag("mirror", cmd.PersistentFlags().Lookup("mirror"))
+	cmd.PersistentFlags().String("cache-dir", install.DefaultCacheDir, "Directory downloaded installers are cached in, set to an empty string to disable the cache")
+	viper.BindPFlag("cache-dir", cmd.PersistentFlags().Lookup("cache-dir"))
+	cmd.PersistentFlags().Bool("dry-run", false, "Print a plan of the commands, file changes, downloads and service restarts wbi would make without making them")
+	viper.BindPFlag("dry-run", cmd.PersistentFlags().Lookup("dry-run"))
 	cmd.AddCommand(newSetupCmd().cmd)
 	cmd.AddCommand(newVerifyCmd().cmd)
 	cmd.AddCommand(newConfigCmd().cmd)
 	cmd.AddCommand(newInstallCmd().cmd)
+	cmd.AddCommand(newUninstallCmd().cmd)
 	cmd.AddCommand(newScanCmd().cmd)
 	cmd.AddCommand(newActivateCmd().cmd)
+	cmd.AddCommand(newBundleCmd().cmd)
+	cmd.AddCommand(newCacheCmd().cmd)
+	cmd.AddCommand(newDoctorCmd().cmd)
+	cmd.AddCommand(newStatusCmd().cmd)
+	cmd.AddCommand(newJoinCmd().cmd)
 
 	root.cmd = cmd
 	return root
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/output"
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStructuredOutputKeepsStdout tests that only the structured result is printed to stdout
+func TestStructuredOutputKeepsStdout(t *testing.T) {
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	require.NoError(t, err)
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = originalStdout, originalStderr
+		output.Set(string(output.Text))
+		system.SetMessageOutput(originalStdout)
+		system.SetDryRun(false)
+	})
+
+	require.NoError(t, setOutput(string(output.JSON)))
+	system.PrintAndLogInfo("Checking the server")
+	require.NoError(t, system.RunCommand("echo child output", true, 0, false))
+	require.NoError(t, output.Print(map[string]string{"status": "ok"}))
+	system.SetDryRun(true)
+	require.NoError(t, system.RunCommand("systemctl restart rstudio-server", true, 0, true))
+	printDryRunPlan()
+
+	stdoutContent, err := os.ReadFile(stdout.Name())
+	require.NoError(t, err)
+	var result map[string]string
+	require.NoError(t, json.Unmarshal(stdoutContent, &result), "stdout is not JSON: %s", stdoutContent)
+	assert.Equal(t, map[string]string{"status": "ok"}, result)
+
+	stderrContent, err := os.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(stderrContent), "Checking the server")
+	assert.Contains(t, string(stderrContent), "child output")
+	assert.Contains(t, string(stderrContent), "systemctl restart rstudio-server")
+}
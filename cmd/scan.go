@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/jupyter"
 	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/output"
+	"github.com/sol-eng/wbi/internal/quarto"
 	"github.com/sol-eng/wbi/internal/system"
 	"github.com/spf13/cobra"
 )
@@ -19,25 +24,79 @@ type scanOpts struct {
 }
 
 func newScan(scanOpts scanOpts, language string) error {
-	if language == "r" {
-		rVersions, err := languages.ScanForRVersions()
+	if language == "jupyter" {
+		installations, err := jupyter.ScanJupyterInstallations()
 		if err != nil {
-			return fmt.Errorf("issue occured in scanning for R versions: %w", err)
+			return fmt.Errorf("issue occured in scanning for Jupyter installations: %w", err)
 		}
-		system.PrintAndLogInfo(strings.Join(rVersions, "\n"))
-	} else if language == "python" {
-		pythonVersions, err := languages.ScanForPythonVersions()
-		if err != nil {
-			return fmt.Errorf("issue occured in scanning for Python versions: %w", err)
+		if output.Structured() {
+			return output.Print(installations)
 		}
-		system.PrintAndLogInfo(strings.Join(pythonVersions, "\n"))
+		printJupyterInstallations(installations)
+		return nil
+	}
+
+	var installations []languages.Installation
+	var err error
+	if language == "r" {
+		installations, err = languages.ScanRInstallations()
+	} else if language == "python" {
+		installations, err = languages.ScanPythonInstallations()
+	} else if language == "quarto" {
+		installations, err = quarto.ScanQuartoInstallations()
 	} else {
 		return fmt.Errorf("language %s is not supported", language)
 	}
-
+	if err != nil {
+		return fmt.Errorf("issue occured in scanning for %s versions: %w", language, err)
+	}
+	if output.Structured() {
+		return output.Print(installations)
+	}
+	printInstallations(installations)
 	return nil
 }
 
+// printInstallations prints a table of installations with the version each one reported when it was run
+func printInstallations(installations []languages.Installation) {
+	if len(installations) == 0 {
+		system.PrintAndLogInfo("No installations were found")
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PATH\tVERSION\tARCH\tSOURCE\tSTATUS")
+	for _, installation := range installations {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", installation.Path, installation.Version, installation.Architecture, installation.Source, installationStatus(installation.Broken, installation.Symlinked, installation.Error))
+	}
+	writer.Flush()
+}
+
+// printJupyterInstallations prints a table of Jupyter installations, each followed by its kernels
+func printJupyterInstallations(installations []jupyter.JupyterInstallation) {
+	if len(installations) == 0 {
+		system.PrintAndLogInfo("No installations were found")
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PATH\tVERSION\tCONFIGURED\tSTATUS")
+	for _, installation := range installations {
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\n", installation.Path, installation.Version, installation.Configured, installationStatus(installation.Broken, false, installation.Error))
+		for _, kernel := range installation.Kernels {
+			fmt.Fprintf(writer, "  kernel %s (%s)\t%s\t\t%s\n", kernel.Name, kernel.DisplayName, kernel.Language, kernel.Executable)
+		}
+	}
+	writer.Flush()
+}
+
+func installationStatus(broken bool, symlinked bool, errMessage string) string {
+	if broken {
+		return "broken: " + errMessage
+	} else if symlinked {
+		return "symlinked"
+	}
+	return "ok"
+}
+
 func setScanOpts(scanOpts *scanOpts) {
 
 }
@@ -50,9 +109,9 @@ func (opts *scanOpts) Validate(args []string) error {
 		return fmt.Errorf("too many arguments provided, please provide only one argument")
 	}
 
-	// ensure only r or python is provided
-	if args[0] != "r" && args[0] != "python" {
-		return fmt.Errorf("invalid language provided, please provide one of the following: r, python")
+	// ensure only a supported language is provided
+	if args[0] != "r" && args[0] != "python" && args[0] != "quarto" && args[0] != "jupyter" {
+		return fmt.Errorf("invalid language provided, please provide one of the following: r, python, quarto, jupyter")
 	}
 	return nil
 }
@@ -64,14 +123,21 @@ func newScanCmd() *scanCmd {
 
 	// adding two spaces to have consistent formatting
 	exampleText := []string{
-		"To scan for existing R and Python installations:",
+		"To scan for existing R, Python and Quarto installations, running each one to report its version:",
 		"  wbi scan r",
 		"  wbi scan python",
+		"  wbi scan quarto",
+		"",
+		"To scan for Jupyter installations and the kernels registered with them:",
+		"  wbi scan jupyter",
+		"",
+		"To list R installations with their version, architecture, source and status as JSON:",
+		"  wbi scan r --output json",
 	}
 
 	cmd := &cobra.Command{
 		Use:     "scan [lanaguage]",
-		Short:   "Scan for installed versions of R or Python",
+		Short:   "Scan for installed versions of R, Python, Quarto or Jupyter",
 		Example: strings.Join(exampleText, "\n"),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			setScanOpts(&root.opts)
@@ -36,11 +36,22 @@ func TestScanParamsValidate(t *testing.T) {
 			flags:       scanOpts{},
 			expectError: "",
 		},
-		// non r or python argument test
-		"non r or python argument only fails": {
+		// quarto and jupyter argument tests
+		"quarto argument only succeeds": {
+			args:        []string{"quarto"},
+			flags:       scanOpts{},
+			expectError: "",
+		},
+		"jupyter argument only succeeds": {
+			args:        []string{"jupyter"},
+			flags:       scanOpts{},
+			expectError: "",
+		},
+		// unsupported argument test
+		"unsupported argument only fails": {
 			args:        []string{"workbench"},
 			flags:       scanOpts{},
-			expectError: "invalid language provided, please provide one of the following: r, python",
+			expectError: "invalid language provided, please provide one of the following: r, python, quarto, jupyter",
 		},
 	}
 
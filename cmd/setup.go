@@ -6,6 +6,10 @@ import (
 
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/activedirectory"
+	"github.com/sol-eng/wbi/internal/answers"
+	"github.com/sol-eng/wbi/internal/bundle"
+	"github.com/sol-eng/wbi/internal/conffile"
 	"github.com/sol-eng/wbi/internal/config"
 	"github.com/sol-eng/wbi/internal/connect"
 	"github.com/sol-eng/wbi/internal/jupyter"
@@ -28,10 +32,33 @@ type setupCmd struct {
 }
 
 type setupOpts struct {
-	step string
+	step                string
+	answersPath         string
+	generateAnswersPath string
+	bundlePath          string
 }
 
 func newSetup(setupOpts setupOpts) error {
+	// resolve version lists and downloads from an offline bundle if provided
+	if setupOpts.bundlePath != "" {
+		err := bundle.Load(setupOpts.bundlePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// write an answers template and exit if requested
+	if setupOpts.generateAnswersPath != "" {
+		return generateAnswers(setupOpts.generateAnswersPath)
+	}
+
+	// answer every prompt from the answers file if provided
+	if setupOpts.answersPath != "" {
+		err := answers.Load(setupOpts.answersPath)
+		if err != nil {
+			return err
+		}
+	}
 
 	// define step either "" if no flag or a step if flag is set
 	step := setupOpts.step
@@ -44,10 +71,12 @@ func newSetup(setupOpts setupOpts) error {
 		step = "prereqs"
 	}
 
-	// Check if running as root
-	err := operatingsystem.CheckIfRunningAsRoot()
-	if err != nil {
-		return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step start\"", err)
+	// Check if running as root, a dry run only reads the server so it can be planned as any user
+	if !system.DryRun() {
+		err := operatingsystem.CheckIfRunningAsRoot()
+		if err != nil {
+			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step start\"", err)
+		}
 	}
 
 	// Determine OS and install pre-requisites
@@ -55,6 +84,10 @@ func newSetup(setupOpts setupOpts) error {
 	if err != nil {
 		return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step start\"", err)
 	}
+	err = bundle.CheckOS(osType, config.HostArchitecture())
+	if err != nil {
+		return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step start\"", err)
+	}
 
 	if step == "prereqs" {
 		ConfirmInstall, err := operatingsystem.PromptInstallPrereqs()
@@ -74,49 +107,41 @@ func newSetup(setupOpts setupOpts) error {
 	}
 
 	if step == "firewall" {
-		// Determine if we should disable the local firewall, then disable it
-		// TODO: Add support for Ubuntu ufw
-		firewalldEnabled, err := operatingsystem.CheckFirewallStatus(osType)
+		// Open the Workbench ports in the local firewall, or disable it
+		err := operatingsystem.PromptAndConfigureFirewall(osType)
 		if err != nil {
 			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step firewall\"", err)
 		}
-
-		if firewalldEnabled {
-			disableFirewall, err := operatingsystem.FirewallPrompt()
-			if err != nil {
-				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step firewall\"", err)
-			}
-
-			if disableFirewall {
-				err = operatingsystem.DisableFirewall(osType)
-				if err != nil {
-					return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step firewall\"", err)
-				}
-			}
-		}
 		step = "security"
 	}
 
 	if step == "security" {
-		// Determine Linux security status for the OS, then disable it
-		// TODO: Add support for Ubuntu AppArmor
+		// Determine Linux security status for the OS, then disable SELinux or relax the AppArmor profiles that confine Workbench
 		selinuxEnabled, err := operatingsystem.CheckLinuxSecurityStatus(osType)
 		if err != nil {
 			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step security\"", err)
 		}
 
 		if selinuxEnabled {
-			disableSELinux, err := operatingsystem.LinuxSecurityPrompt(osType)
+			selinuxChoice, err := operatingsystem.LinuxSecurityPrompt(osType)
 			if err != nil {
 				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step security\"", err)
 			}
 
-			if disableSELinux {
+			switch selinuxChoice {
+			case operatingsystem.SELinuxEnforcing:
+				err = operatingsystem.ApplySELinuxPolicy(osType)
+			case operatingsystem.SELinuxDisable:
 				err = operatingsystem.DisableLinuxSecurity()
-				if err != nil {
-					return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step security\"", err)
-				}
 			}
+			if err != nil {
+				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step security\"", err)
+			}
+		}
+
+		err = operatingsystem.PromptAndConfigureAppArmor(osType)
+		if err != nil {
+			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step security\"", err)
 		}
 		step = "languages"
 	}
@@ -166,6 +191,15 @@ func newSetup(setupOpts setupOpts) error {
 		if err != nil {
 			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step license\"", err)
 		}
+		step = "r-versions"
+	}
+
+	if step == "r-versions" {
+		// R versions offered by Workbench
+		err = languages.PromptAndConfigureRVersions()
+		if err != nil {
+			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step r-versions\"", err)
+		}
 		step = "quarto"
 	}
 
@@ -211,7 +245,12 @@ func newSetup(setupOpts setupOpts) error {
 			if err != nil {
 				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step ssl\"", err)
 			}
-			workbench.WriteSSLConfig(certPath, keyPath, serverURL)
+			err = workbench.WriteSSLConfig(certPath, keyPath, serverURL)
+			if err != nil {
+				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step ssl\"", err)
+			}
+			// Workbench now serves HTTPS on port 443, which a local firewall also needs to allow
+			err = operatingsystem.PromptAndConfigureFirewall(osType)
 			if err != nil {
 				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step ssl\"", err)
 			}
@@ -251,23 +290,55 @@ func newSetup(setupOpts setupOpts) error {
 				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step connect\"", err)
 			}
 		}
+		step = "ad"
+	}
+
+	if step == "ad" {
+		// Active Directory
+		err = activedirectory.PromptAndJoinDomain(osType)
+		if err != nil {
+			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step ad\"", err)
+		}
 		step = "restart"
 	}
 
 	if step == "restart" {
+		// label everything installed since the security step when SELinux was configured for Workbench
+		selinuxPolicyApplied, err := operatingsystem.SELinuxPolicyApplied(osType)
+		if err != nil {
+			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step restart\"", err)
+		}
+		if selinuxPolicyApplied {
+			err = operatingsystem.RelabelSELinux()
+			if err != nil {
+				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step restart\"", err)
+			}
+		}
+
 		system.PrintAndLogInfo("\nRestarting RStudio Server and Launcher...")
 
 		err = workbench.RestartRStudioServerAndLauncher()
 		if err != nil {
 			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step restart\"", err)
 		}
+
+		if selinuxPolicyApplied {
+			err = operatingsystem.ReportSELinuxDenials()
+			if err != nil {
+				return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step restart\"", err)
+			}
+		}
 		step = "status"
 	}
 
 	if step == "status" {
 		system.PrintAndLogInfo("\nPrinting the status of RStudio Server and Launcher...")
 
-		err = workbench.StatusRStudioServerAndLauncher()
+		statuses, err := workbench.StatusRStudioServerAndLauncher()
+		if err != nil {
+			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step status\"", err)
+		}
+		err = printServiceStatuses(statuses)
 		if err != nil {
 			return fmt.Errorf("%w.\nTo return to this step in the setup process use \"wbi setup --step status\"", err)
 		}
@@ -296,16 +367,17 @@ func newSetup(setupOpts setupOpts) error {
 
 	var adDocURL string
 	switch osType {
-	case config.Ubuntu20, config.Ubuntu22:
+	case config.Ubuntu20, config.Ubuntu22, config.Ubuntu24:
 		adDocURL = "https://support.posit.co/hc/en-us/articles/360024137174-Integrating-Ubuntu-with-Active-Directory-for-RStudio-Workbench-RStudio-Server-Pro"
 	case config.Redhat7, config.Redhat8, config.Redhat9:
 		adDocURL = "https://support.posit.co/hc/en-us/articles/360016587973-Integrating-RStudio-Workbench-RStudio-Server-Pro-with-Active-Directory-using-CentOS-RHEL"
 	}
 
 	var sslEnabled bool
-	matched, err := system.CheckStringExists("ssl-enabled=1", "/etc/rstudio/rserver.conf")
-	if err == nil && matched {
-		sslEnabled = true
+	rserverConf, err := conffile.Load("/etc/rstudio/rserver.conf")
+	if err == nil {
+		sslValue, _ := rserverConf.Get("ssl-enabled")
+		sslEnabled = sslValue == "1"
 	}
 	var serverAccessMessage string
 	if sslEnabled {
@@ -318,17 +390,50 @@ func newSetup(setupOpts setupOpts) error {
 		"Workbench is now configured using the default PAM authentication method. Users with local Linux accounts and home directories should be able to log in to Workbench. \n\n" +
 		serverAccessMessage +
 		"Workbench integrates with a variety of Authentication types. To learn more about specific integrations, visit the documentation links below:\n" +
-		"For more information on PAM authentication https://docs.posit.co/ide/server-pro/authenticating_users/pam_authentication.html. \n" + "For more information on Active Directory authentication " + adDocURL + ". \n" +
+		"For more information on PAM authentication https://docs.posit.co/ide/server-pro/authenticating_users/pam_authentication.html. \n" + "For more information on Active Directory authentication " + adDocURL + ", or use \"wbi config ad\" to join a domain. \n" +
 		"For more information on SAML Single Sign-On authentication https://docs.posit.co/ide/server-pro/authenticating_users/saml_sso.html. \n" +
 		"For more information on OpenID Connect Single Sign-On authentication https://docs.posit.co/ide/server-pro/authenticating_users/openid_connect_authentication.html. \n" +
-		"For more information on Proxied Authentication https://docs.posit.co/ide/server-pro/authenticating_users/proxied_authentication.html."
+		"For more information on Proxied Authentication https://docs.posit.co/ide/server-pro/authenticating_users/proxied_authentication.html. \n\n" +
+		"To configure SAML, OpenID Connect or proxied authentication use \"wbi config auth\"."
 
 	system.PrintAndLogInfo(finalMessage)
 	return nil
 }
 
+// generateAnswers writes an answers template filled with the latest available versions
+func generateAnswers(path string) error {
+	var defaults answers.TemplateDefaults
+
+	// the latest versions are a convenience, the template is still written without them
+	rVersions, err := languages.RetrieveValidRVersions()
+	if err == nil && len(rVersions) > 0 {
+		defaults.RVersion = rVersions[0]
+	}
+	osType, err := operatingsystem.DetectOS()
+	if err == nil {
+		pythonVersions, err := languages.RetrieveValidPythonVersions(osType)
+		if err == nil && len(pythonVersions) > 0 {
+			defaults.PythonVersion = pythonVersions[0]
+		}
+	}
+	quartoVersions, err := quarto.RetrieveValidQuartoVersions()
+	if err == nil && len(quartoVersions) > 0 {
+		defaults.QuartoVersion = quartoVersions[0]
+	}
+
+	err = answers.WriteTemplate(path, defaults)
+	if err != nil {
+		return fmt.Errorf("issue generating the answers file: %w", err)
+	}
+	system.PrintAndLogInfo("An answers file with the recommended defaults has been written to " + path + "\nReview it, then run \"wbi setup --answers " + path + "\"")
+	return nil
+}
+
 func setSetupOpts(setupOpts *setupOpts) {
 	setupOpts.step = viper.GetString("step")
+	setupOpts.answersPath = viper.GetString("answers")
+	setupOpts.generateAnswersPath = viper.GetString("generate-answers")
+	setupOpts.bundlePath = viper.GetString("setup-bundle")
 }
 
 func (opts *setupOpts) Validate(args []string) error {
@@ -338,11 +443,28 @@ func (opts *setupOpts) Validate(args []string) error {
 	}
 
 	// ensure step is valid
-	validSteps := []string{"start", "prereqs", "firewall", "security", "languages", "r", "python", "workbench", "license", "quarto", "jupyter", "prodrivers", "ssl", "packagemanager", "connect", "restart", "status", "verify"}
+	validSteps := []string{"start", "prereqs", "firewall", "security", "languages", "r", "python", "workbench", "license", "r-versions", "quarto", "jupyter", "prodrivers", "ssl", "packagemanager", "connect", "ad", "restart", "status", "verify"}
 	if opts.step != "" && !lo.Contains(validSteps, opts.step) {
 		return fmt.Errorf("invalid step: %s", opts.step)
 	}
 
+	// the answers and generate-answers flags cannot be used together
+	if opts.answersPath != "" && opts.generateAnswersPath != "" {
+		return fmt.Errorf("the answers and generate-answers flags cannot be used together")
+	}
+	// the step flag is not used when generating an answers file
+	if opts.step != "" && opts.generateAnswersPath != "" {
+		return fmt.Errorf("the step flag cannot be used with the generate-answers flag")
+	}
+	// ensure the answers file exists
+	if opts.answersPath != "" && !system.VerifyFileExists(opts.answersPath) {
+		return fmt.Errorf("the answers file provided does not exist")
+	}
+	// ensure the bundle exists
+	if opts.bundlePath != "" && !system.VerifyFileExists(opts.bundlePath) {
+		return fmt.Errorf("the bundle provided does not exist")
+	}
+
 	return nil
 }
 
@@ -356,6 +478,15 @@ func newSetupCmd() *setupCmd {
 		"",
 		"To start an interactive setup process for Workbench at a certain step:",
 		"  wbi setup --step [STEP]",
+		"",
+		"To write an answers file with the recommended defaults:",
+		"  wbi setup --generate-answers answers.yaml",
+		"",
+		"To run the setup process non-interactively from an answers file:",
+		"  wbi setup --answers answers.yaml",
+		"",
+		"To run the setup process on an air-gapped server from an offline bundle:",
+		"  wbi setup --bundle wbi-bundle-jammy.tar",
 	}
 
 	cmd := &cobra.Command{
@@ -380,11 +511,20 @@ func newSetupCmd() *setupCmd {
 		SilenceUsage: true,
 	}
 
-	stepHelp := `The step to start at. Valid steps are: start, prereqs, firewall, security, languages, r, python, workbench, license, quarto, jupyter, prodrivers, ssl, packagemanager, connect, restart, status, verify.`
+	stepHelp := `The step to start at. Valid steps are: start, prereqs, firewall, security, languages, r, python, workbench, license, r-versions, quarto, jupyter, prodrivers, ssl, packagemanager, connect, ad, restart, status, verify.`
 
 	cmd.Flags().StringP("step", "s", "", stepHelp)
 	viper.BindPFlag("step", cmd.Flags().Lookup("step"))
 
+	cmd.Flags().StringP("answers", "a", "", "Path to an answers file used to answer every prompt non-interactively")
+	viper.BindPFlag("answers", cmd.Flags().Lookup("answers"))
+
+	cmd.Flags().StringP("generate-answers", "", "", "Write an answers file with the recommended defaults to this path and exit")
+	viper.BindPFlag("generate-answers", cmd.Flags().Lookup("generate-answers"))
+
+	cmd.Flags().StringP("bundle", "b", "", "Path to an offline bundle created with wbi bundle create to install from instead of the network")
+	viper.BindPFlag("setup-bundle", cmd.Flags().Lookup("bundle"))
+
 	root.cmd = cmd
 	return root
 }
@@ -34,6 +34,31 @@ func TestSetupParamsValidate(t *testing.T) {
 			flags:       setupOpts{step: "configure"},
 			expectError: "invalid step: configure",
 		},
+		"answers flag with a missing file fails": {
+			args:        []string{},
+			flags:       setupOpts{answersPath: "does-not-exist.yaml"},
+			expectError: "the answers file provided does not exist",
+		},
+		"bundle flag with a missing file fails": {
+			args:        []string{},
+			flags:       setupOpts{bundlePath: "does-not-exist.tar"},
+			expectError: "the bundle provided does not exist",
+		},
+		"generate-answers flag succeeds": {
+			args:        []string{},
+			flags:       setupOpts{generateAnswersPath: "answers.yaml"},
+			expectError: "",
+		},
+		"answers and generate-answers flags together fail": {
+			args:        []string{},
+			flags:       setupOpts{answersPath: "answers.yaml", generateAnswersPath: "answers.yaml"},
+			expectError: "the answers and generate-answers flags cannot be used together",
+		},
+		"generate-answers flag with a step fails": {
+			args:        []string{},
+			flags:       setupOpts{step: "r", generateAnswersPath: "answers.yaml"},
+			expectError: "the step flag cannot be used with the generate-answers flag",
+		},
 	}
 
 	for name, tc := range tests {
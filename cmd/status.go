@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/output"
+	"github.com/sol-eng/wbi/internal/workbench"
+	"github.com/spf13/cobra"
+)
+
+type statusCmd struct {
+	cmd  *cobra.Command
+	opts statusOpts
+}
+
+type statusOpts struct {
+}
+
+func newStatus(statusOpts statusOpts) error {
+	statuses, err := workbench.StatusRStudioServerAndLauncher()
+	if err != nil {
+		return fmt.Errorf("issue checking the status of rstudio-server and rstudio-launcher: %w", err)
+	}
+	return printServiceStatuses(statuses)
+}
+
+// printServiceStatuses prints the raw status output as text, or the parsed service states as structured output
+func printServiceStatuses(statuses []workbench.ServiceStatus) error {
+	if output.Structured() {
+		return output.Print(statuses)
+	}
+	for _, status := range statuses {
+		workbench.PrintServiceStatus(status)
+	}
+	return nil
+}
+
+func setStatusOpts(statusOpts *statusOpts) {
+
+}
+
+func (opts *statusOpts) Validate(args []string) error {
+	// check args lengths
+	if len(args) > 0 {
+		return fmt.Errorf("too many arguments provided, status does not take any arguments")
+	}
+	return nil
+}
+
+func newStatusCmd() *statusCmd {
+	var statusOpts statusOpts
+
+	root := &statusCmd{opts: statusOpts}
+
+	// adding two spaces to have consistent formatting
+	exampleText := []string{
+		"To print the status of rstudio-server and rstudio-launcher:",
+		"  wbi status",
+		"",
+		"To print whether each service is running as JSON:",
+		"  wbi status --output json",
+	}
+
+	cmd := &cobra.Command{
+		Use:     "status",
+		Short:   "Print the status of rstudio-server and rstudio-launcher",
+		Example: strings.Join(exampleText, "\n"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			setStatusOpts(&root.opts)
+			if err := root.opts.Validate(args); err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("status-opts")
+			if err := newStatus(root.opts); err != nil {
+				return err
+			}
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStatusParamsValidate tests the status command parameters
+func TestStatusParamsValidate(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		flags       statusOpts
+		expectError string
+	}{
+		"no argument succeeds": {
+			args:        []string{},
+			flags:       statusOpts{},
+			expectError: "",
+		},
+		"an argument fails": {
+			args:        []string{"workbench"},
+			flags:       statusOpts{},
+			expectError: "too many arguments provided, status does not take any arguments",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			statusCmd := newStatusCmd()
+			// set the flags
+			statusCmd.opts = tc.flags
+			// run validation
+			err := statusCmd.opts.Validate(tc.args)
+
+			if err != nil && tc.expectError != "" {
+				// if we expect an error, check that it contains the expected error
+				assert.Containsf(t, err.Error(), tc.expectError, "expected error containing %q, got %s", tc.expectError, err)
+			} else if err != nil && tc.expectError == "" {
+				// if we expect no error but get one then fail
+				t.Fatalf("expected no error, but got %s", err)
+			} else if err == nil && tc.expectError != "" {
+				// if we expect an error but don't get one then fail
+				t.Fatalf("expected error containing %q, but the command ran without error", tc.expectError)
+			}
+			// otherwise we expect the command to succeed so pass the test
+		})
+	}
+}
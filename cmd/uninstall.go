@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/operatingsystem"
+	"github.com/sol-eng/wbi/internal/quarto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// uninstallVersionPattern matches versions such as 4.2.3, v1.3.340 or 3.12.0rc1, which are also the names of their
+// install directories
+var uninstallVersionPattern = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-.]?[0-9A-Za-z]+)*$`)
+
+type uninstallCmd struct {
+	cmd  *cobra.Command
+	opts uninstallOpts
+}
+
+type uninstallOpts struct {
+	versions []string
+	force    bool
+}
+
+func newUninstall(uninstallOpts uninstallOpts, program string) error {
+	if program == "quarto" {
+		for _, quartoVersion := range uninstallOpts.versions {
+			err := quarto.UninstallQuarto(quartoVersion)
+			if err != nil {
+				return fmt.Errorf("issue uninstalling Quarto versions: %w", err)
+			}
+		}
+		return nil
+	}
+
+	// Determine OS
+	osType, err := operatingsystem.DetectOS()
+	if err != nil {
+		return err
+	}
+
+	if program == "r" {
+		for _, rVersion := range uninstallOpts.versions {
+			err = languages.UninstallR(rVersion, osType)
+			if err != nil {
+				return fmt.Errorf("issue uninstalling R versions: %w", err)
+			}
+		}
+	} else if program == "python" {
+		for _, pythonVersion := range uninstallOpts.versions {
+			err = languages.UninstallPython(pythonVersion, osType, uninstallOpts.force)
+			if err != nil {
+				return fmt.Errorf("issue uninstalling Python versions: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func setUninstallOpts(uninstallOpts *uninstallOpts) {
+	uninstallOpts.versions = viper.GetStringSlice("uninstall-version")
+	uninstallOpts.force = viper.GetBool("force")
+}
+
+func (opts *uninstallOpts) Validate(args []string) error {
+	// check args lengths
+	if len(args) == 0 {
+		return fmt.Errorf("no arguments provided, please provide one argument")
+	} else if len(args) > 1 {
+		return fmt.Errorf("too many arguments provided, please provide only one argument")
+	}
+
+	// ensure program is valid
+	if args[0] != "r" && args[0] != "python" && args[0] != "quarto" {
+		return fmt.Errorf("invalid argument provided")
+	}
+
+	// a version is required so nothing is removed by accident
+	if len(opts.versions) == 0 {
+		return fmt.Errorf("the version flag is required")
+	}
+
+	// each version names a directory that is removed, so it must look like a version
+	for _, v := range opts.versions {
+		if strings.Contains(v, "/") || strings.Contains(v, "..") {
+			return fmt.Errorf("invalid version %q, versions can't contain / or ..", v)
+		}
+		// R also installs the next and devel builds from Posit
+		if args[0] == "r" && (v == "next" || v == "devel") {
+			continue
+		}
+		if !uninstallVersionPattern.MatchString(v) {
+			return fmt.Errorf("invalid version %q, please provide a version such as 4.2.3", v)
+		}
+	}
+
+	// only the flag for force is supported for python
+	if opts.force && args[0] != "python" {
+		return fmt.Errorf("the force flag is only supported for python")
+	}
+
+	return nil
+}
+
+func newUninstallCmd() *uninstallCmd {
+	var uninstallOpts uninstallOpts
+
+	root := &uninstallCmd{opts: uninstallOpts}
+
+	// adding two spaces to have consistent formatting
+	exampleText := []string{
+		"To uninstall a specific R, Python or Quarto version:",
+		"  wbi uninstall r --version 4.1.3",
+		"  wbi uninstall python --version 3.10.10",
+		"  wbi uninstall quarto --version 1.2.475",
+		"",
+		"To uninstall multiple R, Python or Quarto versions:",
+		"  wbi uninstall r --version 4.1.3,4.0.5",
+		"",
+		"To uninstall the Python version Jupyter is configured to use in /etc/rstudio/jupyter.conf:",
+		"  wbi uninstall python --version 3.10.10 --force",
+	}
+
+	cmd := &cobra.Command{
+		Use:     "uninstall [program]",
+		Short:   "Uninstall R, Python or Quarto versions",
+		Example: strings.Join(exampleText, "\n"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			setUninstallOpts(&root.opts)
+			if err := root.opts.Validate(args); err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("uninstall-opts")
+			if err := newUninstall(root.opts, strings.ToLower(args[0])); err != nil {
+				return err
+			}
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringSliceP("version", "v", []string{}, "Version(s) of R, Python or Quarto to uninstall. Multiple values can be passed by seperating each version with a comma.")
+	viper.BindPFlag("uninstall-version", cmd.Flags().Lookup("version"))
+
+	cmd.Flags().BoolP("force", "f", false, "Uninstall a Python version even if Jupyter is configured to use it in /etc/rstudio/jupyter.conf.")
+	viper.BindPFlag("force", cmd.Flags().Lookup("force"))
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUninstallParamsValidate tests the uninstall command parameters
+func TestUninstallParamsValidate(t *testing.T) {
+
+	tests := map[string]struct {
+		args        []string
+		flags       uninstallOpts
+		expectError string
+	}{
+		"no argument": {
+			args:        []string{},
+			flags:       uninstallOpts{},
+			expectError: "no arguments provided, please provide one argument",
+		},
+		"too many arguments": {
+			args:        []string{"r", "python"},
+			flags:       uninstallOpts{versions: []string{"4.1.3"}},
+			expectError: "too many arguments provided, please provide only one argument",
+		},
+		"invalid argument": {
+			args:        []string{"workbench"},
+			flags:       uninstallOpts{versions: []string{"2023.03.0"}},
+			expectError: "invalid argument provided",
+		},
+		"r argument without a version flag fails": {
+			args:        []string{"r"},
+			flags:       uninstallOpts{},
+			expectError: "the version flag is required",
+		},
+		"r argument with a version flag succeeds": {
+			args:        []string{"r"},
+			flags:       uninstallOpts{versions: []string{"4.1.3"}},
+			expectError: "",
+		},
+		"r argument with a force flag fails": {
+			args:        []string{"r"},
+			flags:       uninstallOpts{versions: []string{"4.1.3"}, force: true},
+			expectError: "the force flag is only supported for python",
+		},
+		"python argument with a version and force flag succeeds": {
+			args:        []string{"python"},
+			flags:       uninstallOpts{versions: []string{"3.10.10"}, force: true},
+			expectError: "",
+		},
+		"r argument with the devel version succeeds": {
+			args:        []string{"r"},
+			flags:       uninstallOpts{versions: []string{"devel"}},
+			expectError: "",
+		},
+		"python argument with a release candidate succeeds": {
+			args:        []string{"python"},
+			flags:       uninstallOpts{versions: []string{"3.12.0rc1"}},
+			expectError: "",
+		},
+		"quarto argument with a bare v fails": {
+			args:        []string{"quarto"},
+			flags:       uninstallOpts{versions: []string{"v"}},
+			expectError: "invalid version \"v\", please provide a version such as 4.2.3",
+		},
+		"quarto argument with a parent directory fails": {
+			args:        []string{"quarto"},
+			flags:       uninstallOpts{versions: []string{".."}},
+			expectError: "invalid version \"..\", versions can't contain / or ..",
+		},
+		"r argument with a path fails": {
+			args:        []string{"r"},
+			flags:       uninstallOpts{versions: []string{"4.2.3/../../etc"}},
+			expectError: "versions can't contain / or ..",
+		},
+		"python argument with shell characters fails": {
+			args:        []string{"python"},
+			flags:       uninstallOpts{versions: []string{"3.11.4;reboot"}},
+			expectError: "please provide a version such as 4.2.3",
+		},
+		"python argument with the devel version fails": {
+			args:        []string{"python"},
+			flags:       uninstallOpts{versions: []string{"devel"}},
+			expectError: "please provide a version such as 4.2.3",
+		},
+		"quarto argument with an empty version fails": {
+			args:        []string{"quarto"},
+			flags:       uninstallOpts{versions: []string{""}},
+			expectError: "please provide a version such as 4.2.3",
+		},
+		"quarto argument with multiple versions succeeds": {
+			args:        []string{"quarto"},
+			flags:       uninstallOpts{versions: []string{"1.3.340", "v1.2.475"}},
+			expectError: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			uninstallCmd := newUninstallCmd()
+			// set the flags
+			uninstallCmd.opts = tc.flags
+			// run validation
+			err := uninstallCmd.opts.Validate(tc.args)
+
+			if err != nil && tc.expectError != "" {
+				// if we expect an error, check that it contains the expected error
+				assert.Containsf(t, err.Error(), tc.expectError, "expected error containing %q, got %s", tc.expectError, err)
+			} else if err != nil && tc.expectError == "" {
+				// if we expect no error but get one then fail
+				t.Fatalf("expected no error, but got %s", err)
+			} else if err == nil && tc.expectError != "" {
+				// if we expect an error but don't get one then fail
+				t.Fatalf("expected error containing %q, but the command ran without error", tc.expectError)
+			}
+			// otherwise we expect the command to succeed so pass the test
+		})
+	}
+
+}
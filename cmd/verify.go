@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/sol-eng/wbi/internal/connect"
 	"github.com/sol-eng/wbi/internal/license"
+	"github.com/sol-eng/wbi/internal/output"
 	"github.com/sol-eng/wbi/internal/packagemanager"
 	"github.com/sol-eng/wbi/internal/ssl"
+	"github.com/sol-eng/wbi/internal/system"
 	"github.com/sol-eng/wbi/internal/workbench"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,6 +30,34 @@ type verifyOpts struct {
 	keyPath  string
 }
 
+// verifyResult is the structured output of verify
+type verifyResult struct {
+	Item     string `json:"item" yaml:"item"`
+	Target   string `json:"target,omitempty" yaml:"target,omitempty"`
+	Verified bool   `json:"verified" yaml:"verified"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Cause is the innermost error, such as the HTTP or certificate error behind a failed check
+	Cause string `json:"cause,omitempty" yaml:"cause,omitempty"`
+}
+
+func newVerifyResult(verifyOpts verifyOpts, item string, err error) verifyResult {
+	result := verifyResult{Item: item, Verified: err == nil}
+	if item == "ssl" {
+		result.Target = verifyOpts.certPath
+	} else {
+		result.Target = verifyOpts.url
+	}
+	if err != nil {
+		result.Error = err.Error()
+		cause := err
+		for errors.Unwrap(cause) != nil {
+			cause = errors.Unwrap(cause)
+		}
+		result.Cause = cause.Error()
+	}
+	return result
+}
+
 func newVerify(verifyOpts verifyOpts, item string) error {
 
 	if item == "packagemanager" {
@@ -85,12 +116,15 @@ func newVerify(verifyOpts verifyOpts, item string) error {
 			return fmt.Errorf("failure while trying to verify server trust of the SSL cert: %w", err)
 		}
 
-		fmt.Println("SSL successfully verified")
+		system.PrintAndLogInfo("SSL successfully verified")
 	} else if item == "license" {
-		_, err := license.CheckLicenseActivation()
+		activated, err := license.CheckLicenseActivation()
 		if err != nil {
 			return fmt.Errorf("issue in checking for license activation: %w", err)
 		}
+		if !activated {
+			return fmt.Errorf("no active Workbench license was detected")
+		}
 	}
 
 	return nil
@@ -188,6 +222,9 @@ func newVerifyCmd() *verifyCmd {
 		"",
 		"To verify a license is activated:",
 		"  wbi verify license",
+		"",
+		"To print the result and any error details as JSON:",
+		"  wbi verify workbench --output json",
 	}
 
 	cmd := &cobra.Command{
@@ -204,10 +241,14 @@ func newVerifyCmd() *verifyCmd {
 		RunE: func(_ *cobra.Command, args []string) error {
 			//TODO: Add your logic to gather config to pass code here
 			log.WithField("opts", fmt.Sprintf("%+v", root.opts)).Trace("verify-opts")
-			if err := newVerify(root.opts, strings.ToLower(args[0])); err != nil {
-				return err
+			item := strings.ToLower(args[0])
+			err := newVerify(root.opts, item)
+			if output.Structured() {
+				if printErr := output.Print(newVerifyResult(root.opts, item, err)); printErr != nil {
+					return printErr
+				}
 			}
-			return nil
+			return err
 		},
 		SilenceUsage: true,
 	}
@@ -0,0 +1,327 @@
+package activedirectory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/config"
+	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/operatingsystem"
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/sol-eng/wbi/internal/workbench"
+)
+
+// userExcludedChars is the class of characters userRegex rejects
+const userExcludedChars = `"/\\\[\]:;|=,+*?<>@\s$'&!(){}` + "`"
+
+var (
+	sssdConfPath = "/etc/sssd/sssd.conf"
+	// runDiscover runs realm discover, which only reads, so it also runs in a dry run
+	runDiscover = runWithOutput
+	// runJoin runs realm join with the password on standard input, so it never appears in a command line or log
+	runJoin = runWithStdin
+	// domainRegex matches DNS domain names such as ad.example.com
+	domainRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+	// userRegex matches sAMAccountNames, which are at most 20 characters and can't contain "/\[]:;|=,+*?<> or @. The
+	// characters a shell expands, such as $ and `, are also rejected since the verify user is passed to verify-installation.
+	userRegex = regexp.MustCompile(`^[^-` + userExcludedChars + `][^` + userExcludedChars + `]{0,19}$`)
+	// safeArgRegex matches command arguments that don't need quoting in the command log
+	safeArgRegex = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+)
+
+// Options describes the Active Directory domain to join
+type Options struct {
+	// Domain is the Active Directory domain, such as ad.example.com
+	Domain string
+	// JoinUser is an account allowed to join computers to the domain
+	JoinUser string
+	// Password is the password of JoinUser
+	Password string
+	// ComputerOU is the organizational unit the computer account is created in, the domain default is used when empty
+	ComputerOU string
+	// VerifyUser is a domain user that is looked up, and used to verify Workbench when it is installed, once the server has joined
+	VerifyUser string
+}
+
+// Validate checks the domain is a DNS name and the users are sAMAccountNames, fields that are empty are not checked
+func (opts Options) Validate() error {
+	if opts.Domain != "" && !domainRegex.MatchString(opts.Domain) {
+		return fmt.Errorf("invalid domain %q, please provide a DNS domain name such as ad.example.com", opts.Domain)
+	}
+	if opts.JoinUser != "" && !userRegex.MatchString(opts.JoinUser) {
+		return fmt.Errorf("invalid join user %q, please provide the user's sAMAccountName such as administrator", opts.JoinUser)
+	}
+	if opts.VerifyUser != "" && !userRegex.MatchString(opts.VerifyUser) {
+		return fmt.Errorf("invalid verify user %q, please provide the user's sAMAccountName such as jdoe", opts.VerifyUser)
+	}
+	if strings.ContainsAny(opts.ComputerOU, "\n\r") {
+		return fmt.Errorf("invalid computer OU %q, it can't span lines", opts.ComputerOU)
+	}
+	return nil
+}
+
+// Realm is the result of realm discover for a domain
+type Realm struct {
+	Name string
+	// Configured is "no" when this server hasn't joined the realm, or the type of membership, such as kerberos-member
+	Configured string
+}
+
+// Joined returns true when this server is already a member of the realm
+func (r Realm) Joined() bool {
+	return r.Configured != "" && r.Configured != "no"
+}
+
+// JoinDomain installs realmd, SSSD and adcli, joins the domain, configures SSSD for short usernames with home
+// directories created on first login, and checks a domain user can be found
+func JoinDomain(opts Options, osType config.OperatingSystem) error {
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+	err = InstallPackages(osType)
+	if err != nil {
+		return err
+	}
+
+	realm, err := DiscoverRealm(opts.Domain)
+	if err != nil {
+		return err
+	}
+	if realm.Joined() {
+		system.PrintAndLogInfo("This server has already joined " + realm.Name + " (" + realm.Configured + ")")
+	} else {
+		err = Join(opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ConfigureSSSD(realm.Name)
+	if err != nil {
+		return err
+	}
+	err = EnableMkhomedir(osType)
+	if err != nil {
+		return err
+	}
+
+	if opts.VerifyUser != "" {
+		err = VerifyDomainUser(opts.VerifyUser)
+		if err != nil {
+			return err
+		}
+	}
+	system.PrintAndLogInfo("\nThis server has successfully joined " + realm.Name + ", domain users can now log in to Workbench with their short usernames!")
+	return nil
+}
+
+// InstallPackages installs realmd, SSSD and adcli, and oddjob-mkhomedir on RHEL
+func InstallPackages(osType config.OperatingSystem) error {
+	var installCommand string
+	switch osType {
+	case config.Ubuntu20, config.Ubuntu22, config.Ubuntu24:
+		installCommand = "DEBIAN_FRONTEND=noninteractive apt-get install -y realmd sssd sssd-tools adcli libnss-sss libpam-sss packagekit"
+	case config.Redhat7, config.Redhat8, config.Redhat9:
+		installCommand = "yum install -y realmd sssd adcli oddjob oddjob-mkhomedir samba-common-tools"
+	default:
+		return errors.New("operating system not supported")
+	}
+	err := system.RunCommand(installCommand, true, 1, true)
+	if err != nil {
+		return fmt.Errorf("issue installing the Active Directory packages with the command '%s': %w", installCommand, err)
+	}
+	return nil
+}
+
+// DiscoverRealm finds the domain controllers of a domain through DNS and reports whether this server has joined it
+func DiscoverRealm(domain string) (Realm, error) {
+	args := []string{"realm", "discover", domain}
+	discoverCommand := commandLine(args)
+	system.PrintAndLogInfo("Running command: " + discoverCommand)
+	output, err := runDiscover(args[0], args[1:]...)
+	if err != nil {
+		return Realm{}, fmt.Errorf("issue discovering the domain %s with the command '%s', check that this server uses the domain's DNS servers: %w", domain, discoverCommand, err)
+	}
+	realm := parseRealmDiscover(output)
+	if realm.Name == "" {
+		if system.DryRun() {
+			return Realm{Name: domain, Configured: "no"}, nil
+		}
+		return Realm{}, fmt.Errorf("the domain %s could not be found with the command '%s', check that this server uses the domain's DNS servers", domain, discoverCommand)
+	}
+	return realm, nil
+}
+
+// parseRealmDiscover parses the first realm in the output of realm discover
+func parseRealmDiscover(output string) Realm {
+	var realm Realm
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		// realm names start in the first column and their details are indented
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			if realm.Name != "" {
+				break
+			}
+			realm.Name = strings.TrimSpace(line)
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		if key == "configured" {
+			realm.Configured = strings.TrimSpace(value)
+		}
+	}
+	return realm
+}
+
+// Join joins the domain with realm join, creating a computer account
+func Join(opts Options) error {
+	if opts.JoinUser == "" || opts.Password == "" {
+		return errors.New("a user and password allowed to join computers to the domain are required")
+	}
+	args := []string{"realm", "join", "--verbose", "--user=" + opts.JoinUser}
+	if opts.ComputerOU != "" {
+		args = append(args, "--computer-ou="+opts.ComputerOU)
+	}
+	args = append(args, opts.Domain)
+	joinCommand := commandLine(args)
+
+	if system.DryRun() {
+		system.RecordCommand(joinCommand)
+		return nil
+	}
+	system.PrintAndLogInfo("Running command: " + joinCommand)
+	err := runJoin(opts.Password+"\n", args[0], args[1:]...)
+	if err != nil {
+		return fmt.Errorf("issue joining the domain %s with the command '%s': %w", opts.Domain, joinCommand, err)
+	}
+	cmdlog.Info(joinCommand)
+	return nil
+}
+
+// commandLine returns a command as it would be typed in a shell, quoting the arguments that need it
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if safeArgRegex.MatchString(arg) {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// runWithOutput runs a command without a shell, returning its stdout
+func runWithOutput(name string, args ...string) (string, error) {
+	var errBuf bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &errBuf
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(errBuf.String()))
+	}
+	log.Info(string(output))
+	return string(output), nil
+}
+
+// runWithStdin runs a command without a shell, writing stdin to it
+func runWithStdin(stdin string, name string, args ...string) error {
+	var errBuf bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = system.MessageOutput()
+	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(errBuf.String()))
+	}
+	return nil
+}
+
+// ConfigureSSSD lets domain users log in with their short usernames, such as jdoe rather than jdoe@ad.example.com,
+// with home directories in /home
+func ConfigureSSSD(domain string) error {
+	sssdConf, err := conffile.Load(sssdConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	section := sssdConf.Section("domain/" + strings.ToLower(domain))
+	section.Set("use_fully_qualified_names", "False")
+	section.Set("fallback_homedir", "/home/%u")
+
+	// SSSD refuses to start unless sssd.conf is only readable by root
+	err = sssdConf.Save(0600)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	restartCommand := "systemctl restart sssd"
+	err = system.RunCommand(restartCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue restarting SSSD with the command '%s': %w", restartCommand, err)
+	}
+	return nil
+}
+
+// EnableMkhomedir creates the home directory of domain users through PAM the first time they log in
+func EnableMkhomedir(osType config.OperatingSystem) error {
+	var commands []string
+	switch osType {
+	case config.Ubuntu20, config.Ubuntu22, config.Ubuntu24:
+		commands = []string{"pam-auth-update --enable mkhomedir"}
+	case config.Redhat7:
+		commands = []string{"authconfig --enablemkhomedir --update", "systemctl enable --now oddjobd"}
+	case config.Redhat8, config.Redhat9:
+		commands = []string{"authselect enable-feature with-mkhomedir", "systemctl enable --now oddjobd"}
+	default:
+		return errors.New("operating system not supported")
+	}
+	for _, command := range commands {
+		err := system.RunCommand(command, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue enabling home directory creation with the command '%s': %w", command, err)
+		}
+	}
+	return nil
+}
+
+// VerifyDomainUser looks up a domain user through SSSD, then runs rstudio-server verify-installation as that
+// user when Workbench is installed
+func VerifyDomainUser(username string) error {
+	if system.DryRun() {
+		system.PrintAndLogInfo("Skipping the lookup of the domain user " + username + " in a dry run")
+		return nil
+	}
+	user, err := operatingsystem.UserLookup(username)
+	if err != nil {
+		return fmt.Errorf("the domain user %s cannot be found, check SSSD with 'sssctl user-checks %s': %w", username, username, err)
+	}
+	if user.Uid == "0" {
+		return fmt.Errorf("the user %s is root, a non-root domain user is required", username)
+	}
+	system.PrintAndLogInfo(fmt.Sprintf("The domain user %s was found with UID %s and home directory %s", username, user.Uid, user.HomeDir))
+
+	if !workbench.VerifyWorkbench() {
+		system.PrintAndLogInfo("Workbench is not installed yet, so verify-installation was not run as " + username)
+		return nil
+	}
+	err = workbench.VerifyInstallation(username)
+	if err != nil {
+		return fmt.Errorf("issue verifying Workbench as the domain user %s: %w", username, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package activedirectory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRealmDiscover(t *testing.T) {
+	discovered := `ad.example.com
+  type: kerberos
+  realm-name: AD.EXAMPLE.COM
+  domain-name: ad.example.com
+  configured: no
+  server-software: active-directory
+  client-software: sssd
+  required-package: sssd-tools
+  required-package: adcli
+other.example.com
+  type: kerberos
+  configured: kerberos-member
+`
+	realm := parseRealmDiscover(discovered)
+	assert.Equal(t, "ad.example.com", realm.Name)
+	assert.Equal(t, "no", realm.Configured)
+	assert.False(t, realm.Joined())
+
+	realm = parseRealmDiscover("ad.example.com\n  type: kerberos\n  configured: kerberos-member\n")
+	assert.True(t, realm.Joined())
+
+	assert.Equal(t, Realm{}, parseRealmDiscover(""))
+}
+
+func TestJoinPassesPasswordOnStdin(t *testing.T) {
+	var command []string
+	var stdin string
+	original := runJoin
+	runJoin = func(s string, name string, args ...string) error {
+		command, stdin = append([]string{name}, args...), s
+		return nil
+	}
+	t.Cleanup(func() { runJoin = original })
+
+	err := Join(Options{Domain: "ad.example.com", JoinUser: "administrator", Password: "p@ss", ComputerOU: "OU=O'Brien Servers,DC=ad,DC=example,DC=com"})
+	require.NoError(t, err)
+	// the computer OU is a single argument however it is quoted
+	assert.Equal(t, []string{"realm", "join", "--verbose", "--user=administrator", "--computer-ou=OU=O'Brien Servers,DC=ad,DC=example,DC=com", "ad.example.com"}, command)
+	assert.NotContains(t, command, "p@ss")
+	assert.Equal(t, "p@ss\n", stdin)
+
+	assert.Error(t, Join(Options{Domain: "ad.example.com", JoinUser: "administrator"}))
+}
+
+func TestDiscoverRealm(t *testing.T) {
+	var command []string
+	original := runDiscover
+	runDiscover = func(name string, args ...string) (string, error) {
+		command = append([]string{name}, args...)
+		return "ad.example.com\n  type: kerberos\n  configured: no\n", nil
+	}
+	t.Cleanup(func() { runDiscover = original })
+
+	realm, err := DiscoverRealm("ad.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"realm", "discover", "ad.example.com"}, command)
+	assert.Equal(t, Realm{Name: "ad.example.com", Configured: "no"}, realm)
+}
+
+func TestCommandLine(t *testing.T) {
+	assert.Equal(t, "realm join --user=administrator ad.example.com", commandLine([]string{"realm", "join", "--user=administrator", "ad.example.com"}))
+	assert.Equal(t, `realm join '--computer-ou=OU=O'\''Brien Servers,DC=ad' ad.example.com`, commandLine([]string{"realm", "join", "--computer-ou=OU=O'Brien Servers,DC=ad", "ad.example.com"}))
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := map[string]struct {
+		opts        Options
+		expectError string
+	}{
+		"valid options": {
+			opts: Options{Domain: "ad.example.com", JoinUser: "svc.join", ComputerOU: "OU=Servers,DC=ad,DC=example,DC=com", VerifyUser: "jdoe"},
+		},
+		"single label domain": {
+			opts: Options{Domain: "CORP"},
+		},
+		"nothing to check": {},
+		"domain with a command": {
+			opts:        Options{Domain: "ad.example.com && reboot"},
+			expectError: "invalid domain",
+		},
+		"domain starting with a hyphen": {
+			opts:        Options{Domain: "-ad.example.com"},
+			expectError: "invalid domain",
+		},
+		"user principal name": {
+			opts:        Options{JoinUser: "administrator@ad.example.com"},
+			expectError: "invalid join user",
+		},
+		"user starting with a hyphen": {
+			opts:        Options{JoinUser: "--help"},
+			expectError: "invalid join user",
+		},
+		"user longer than 20 characters": {
+			opts:        Options{JoinUser: "a-very-long-user-name1"},
+			expectError: "invalid join user",
+		},
+		"verify user with a command substitution": {
+			opts:        Options{VerifyUser: "jdoe$(reboot)"},
+			expectError: "invalid verify user",
+		},
+		"computer OU spanning lines": {
+			opts:        Options{ComputerOU: "OU=Servers\nDC=ad"},
+			expectError: "invalid computer OU",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package activedirectory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// PromptADChoice asks users if they would like to join this server to an Active Directory domain
+func PromptADChoice() (bool, error) {
+	if answers.Loaded() {
+		// the ad step is optional, so answers files may leave it out
+		if answers.Get().AD.Join == nil {
+			return false, nil
+		}
+		return answers.Require(answers.Get().AD.Join, "ad.join")
+	}
+	name := false
+	messageText := "Would you like to join this server to an Active Directory domain, so domain users can log in to Workbench?"
+	prompt := &survey.Confirm{
+		Message: messageText,
+	}
+	err := survey.AskOne(prompt, &name)
+	if err != nil {
+		return false, errors.New("there was an issue with the Active Directory prompt")
+	}
+	log.Info(messageText)
+	log.Info(fmt.Sprintf("%v", name))
+	return name, nil
+}
+
+// PromptOptions asks for the domain details not already provided. With an answers file the password is read
+// from ad.password_file, so it is never stored in the answers file itself.
+func PromptOptions(opts Options) (Options, error) {
+	if answers.Loaded() {
+		ad := answers.Get().AD
+		var err error
+		if opts.Domain, err = answers.Require(ad.Domain, "ad.domain"); err != nil {
+			return opts, err
+		}
+		if opts.JoinUser, err = answers.Require(ad.JoinUser, "ad.join_user"); err != nil {
+			return opts, err
+		}
+		passwordFile, err := answers.Require(ad.PasswordFile, "ad.password_file")
+		if err != nil {
+			return opts, err
+		}
+		if opts.Password, err = system.ReadPasswordFile(passwordFile); err != nil {
+			return opts, answers.InvalidError("ad.password_file", err.Error())
+		}
+		if ad.ComputerOU != nil {
+			opts.ComputerOU = *ad.ComputerOU
+		}
+		if ad.VerifyUser != nil {
+			opts.VerifyUser = *ad.VerifyUser
+		}
+		return opts, nil
+	}
+
+	inputs := []struct {
+		value       *string
+		messageText string
+		secret      bool
+		optional    bool
+	}{
+		{value: &opts.Domain, messageText: "Active Directory domain (for example, ad.example.com):"},
+		{value: &opts.JoinUser, messageText: "Domain user allowed to join computers to the domain:"},
+		{value: &opts.Password, messageText: "Password of the domain user:", secret: true},
+		{value: &opts.ComputerOU, messageText: "Organizational unit for the computer account (leave blank for the domain's default):", optional: true},
+		{value: &opts.VerifyUser, messageText: "Domain user to look up once this server has joined (leave blank to skip):", optional: true},
+	}
+	for _, input := range inputs {
+		if *input.value != "" {
+			continue
+		}
+		var prompt survey.Prompt = &survey.Input{Message: input.messageText}
+		if input.secret {
+			prompt = &survey.Password{Message: input.messageText}
+		}
+		var askOpts []survey.AskOpt
+		if !input.optional {
+			askOpts = append(askOpts, survey.WithValidator(survey.Required))
+		}
+		err := survey.AskOne(prompt, input.value, askOpts...)
+		if err != nil {
+			return opts, fmt.Errorf("issue prompting for Active Directory details: %w", err)
+		}
+		log.Info(input.messageText)
+		if !input.secret {
+			log.Info(*input.value)
+		}
+	}
+	return opts, nil
+}
+
+// PromptAndJoinDomain asks users whether to join an Active Directory domain, then joins it
+func PromptAndJoinDomain(osType config.OperatingSystem) error {
+	join, err := PromptADChoice()
+	if err != nil || !join {
+		return err
+	}
+	opts, err := PromptOptions(Options{})
+	if err != nil {
+		return err
+	}
+	err = JoinDomain(opts, osType)
+	if err != nil {
+		return fmt.Errorf("issue joining the Active Directory domain: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package answers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Answers contains a response for every decision in the wbi setup flow. When an
+// answers file has been loaded each prompt reads its response from here instead
+// of asking the user.
+type Answers struct {
+	Prereqs        Prereqs        `yaml:"prereqs"`
+	Firewall       Firewall       `yaml:"firewall"`
+	Security       Security       `yaml:"security"`
+	Languages      []string       `yaml:"languages"`
+	R              R              `yaml:"r"`
+	Python         Python         `yaml:"python"`
+	Workbench      Workbench      `yaml:"workbench"`
+	License        License        `yaml:"license"`
+	RVersions      RVersions      `yaml:"r_versions"`
+	Quarto         Quarto         `yaml:"quarto"`
+	Jupyter        Jupyter        `yaml:"jupyter"`
+	ProDrivers     ProDrivers     `yaml:"prodrivers"`
+	SSL            SSL            `yaml:"ssl"`
+	PackageManager PackageManager `yaml:"packagemanager"`
+	Connect        Connect        `yaml:"connect"`
+	AD             AD             `yaml:"ad"`
+	Verify         Verify         `yaml:"verify"`
+}
+
+// Prereqs contains the answers for the prereqs step
+type Prereqs struct {
+	// Confirm that the server is ready for Workbench to be installed
+	Confirm *bool `yaml:"confirm"`
+	// Whether the server runs in a public cloud (only asked on RHEL)
+	Cloud *bool `yaml:"cloud"`
+}
+
+// Firewall contains the answers for the firewall step
+type Firewall struct {
+	// What to do when a local firewall is active: open_ports, disable or skip
+	Action *string `yaml:"action"`
+	// Disable the local firewall if one is active, only used when action is not set
+	Disable *bool `yaml:"disable"`
+	// Check the opened ports are reachable from localhost (optional)
+	Verify *bool `yaml:"verify"`
+}
+
+// Security contains the answers for the security step
+type Security struct {
+	// What to do when SELinux is enforcing: enforcing (configure it for Workbench), disable or skip (only asked on RHEL)
+	SELinux *string `yaml:"selinux"`
+	// Disable SELinux if it is enforcing, only used when selinux is not set
+	DisableSELinux *bool `yaml:"disable_selinux"`
+	// Put the enforcing AppArmor profiles that confine Workbench, R or Python into complain mode (only asked on Ubuntu)
+	AppArmorComplain *bool `yaml:"apparmor_complain"`
+}
+
+// R contains the answers for the r step
+type R struct {
+	// Install additional versions of R
+	Install *bool `yaml:"install"`
+	// Versions of R to install, for example 4.3.2
+	Versions []string `yaml:"versions"`
+	// Symlink a version of R to /usr/local/bin/R and /usr/local/bin/Rscript
+	Symlink *bool `yaml:"symlink"`
+	// The R binary to symlink, for example /opt/R/4.3.2/bin/R
+	SymlinkPath *string `yaml:"symlink_path"`
+}
+
+// Python contains the answers for the python step
+type Python struct {
+	// Install additional versions of Python
+	Install *bool `yaml:"install"`
+	// Versions of Python to install, for example 3.11.7
+	Versions []string `yaml:"versions"`
+	// Add a version of Python to PATH in /etc/profile.d/wbi_python.sh
+	AddToPATH *bool `yaml:"add_to_path"`
+	// The Python bin directory to add to PATH, for example /opt/python/3.11.7/bin
+	PATHDir *string `yaml:"path_dir"`
+}
+
+// Workbench contains the answers for the workbench step
+type Workbench struct {
+	// Install Workbench if it is not already installed
+	Install *bool `yaml:"install"`
+}
+
+// License contains the answers for the license step
+type License struct {
+	// Activate Workbench with a license key
+	Activate *bool `yaml:"activate"`
+	// The license key in the form XXXX-XXXX-XXXX-XXXX-XXXX-XXXX-XXXX
+	Key *string `yaml:"key"`
+}
+
+// RVersions contains the answers for the r-versions step
+type RVersions struct {
+	// List the versions of R installed in /opt/R in /etc/rstudio/r-versions
+	Configure *bool `yaml:"configure"`
+	// Label for each new entry, {version} is replaced with the version of R (optional)
+	Label *string `yaml:"label"`
+	// Only offer the versions of R listed in r-versions (r-versions-scan=0)
+	DisableScan *bool `yaml:"disable_scan"`
+	// Use the version of R symlinked to /usr/local/bin/R as the default (rsession-which-r)
+	SetDefault *bool `yaml:"set_default"`
+}
+
+// Quarto contains the answers for the quarto step
+type Quarto struct {
+	// Install versions of Quarto in addition to the version bundled with Workbench
+	Install *bool `yaml:"install"`
+	// Versions of Quarto to install, for example v1.4.550
+	Versions []string `yaml:"versions"`
+	// Symlink a version of Quarto to /usr/local/bin/quarto
+	Symlink *bool `yaml:"symlink"`
+	// The Quarto binary to symlink, for example /opt/quarto/v1.4.550/bin/quarto
+	SymlinkPath *string `yaml:"symlink_path"`
+}
+
+// Jupyter contains the answers for the jupyter step
+type Jupyter struct {
+	// Install Jupyter
+	Install *bool `yaml:"install"`
+	// The Python binary to install Jupyter into, for example /opt/python/3.11.7/bin/python
+	PythonPath *string `yaml:"python_path"`
+	// Additional Python binaries to register as Jupyter kernels (optional)
+	AdditionalKernels []string `yaml:"additional_kernels"`
+}
+
+// ProDrivers contains the answers for the prodrivers step
+type ProDrivers struct {
+	// Install the Posit Pro Drivers
+	Install *bool `yaml:"install"`
+}
+
+// SSL contains the answers for the ssl step
+type SSL struct {
+	// Configure Workbench to use SSL
+	Enabled *bool `yaml:"enabled"`
+	// The URL users will use to access Workbench, for example https://workbench.example.com
+	ServerURL *string `yaml:"server_url"`
+	// Path to the SSL certificate
+	CertPath *string `yaml:"cert_path"`
+	// Path to the SSL certificate key
+	KeyPath *string `yaml:"key_path"`
+	// Proceed when the certificate does not match the hostname of this server
+	AllowHostnameMismatch *bool `yaml:"allow_hostname_mismatch"`
+	// Proceed when the certificate chain does not include a root CA
+	AllowMissingRootCA *bool `yaml:"allow_missing_root_ca"`
+	// Add an untrusted root CA to the system trust store
+	TrustRootCA *bool `yaml:"trust_root_ca"`
+}
+
+// PackageManager contains the answers for the packagemanager step
+type PackageManager struct {
+	// One of private (your own Posit Package Manager), public (Posit Public Package Manager) or skip
+	Source *string `yaml:"source"`
+	// The repository languages to configure for a private Package Manager, r and/or python
+	Languages []string `yaml:"languages"`
+	// The base URL of a private Package Manager, for example https://packagemanager.example.com
+	URL *string `yaml:"url"`
+	// The name of the R repository, for example prod-cran
+	RRepo *string `yaml:"r_repo"`
+	// The name of the Python repository, for example pypi
+	PythonRepo *string `yaml:"python_repo"`
+}
+
+// Connect contains the answers for the connect step
+type Connect struct {
+	// Configure a default Connect server
+	Enabled *bool `yaml:"enabled"`
+	// The Connect server URL, for example https://connect.example.com
+	URL *string `yaml:"url"`
+}
+
+// AD contains the answers for the ad step
+type AD struct {
+	// Join this server to an Active Directory domain (optional, the step is skipped when not set)
+	Join *bool `yaml:"join"`
+	// The Active Directory domain, for example ad.example.com
+	Domain *string `yaml:"domain"`
+	// A domain user allowed to join computers to the domain
+	JoinUser *string `yaml:"join_user"`
+	// A file containing the password of join_user, so the password isn't stored in the answers file
+	PasswordFile *string `yaml:"password_file"`
+	// The organizational unit for the computer account (optional)
+	ComputerOU *string `yaml:"computer_ou"`
+	// A domain user to look up once joined, and to verify Workbench with when it is installed (optional)
+	VerifyUser *string `yaml:"verify_user"`
+}
+
+// Verify contains the answers for the verify step
+type Verify struct {
+	// Run the Workbench verify-installation check
+	Enabled *bool `yaml:"enabled"`
+	// A non-root local Linux user to run the verification as
+	User *string `yaml:"user"`
+}
+
+var (
+	loaded     *Answers
+	loadedPath string
+)
+
+// Load reads an answers file so prompts are answered from it instead of interactively
+func Load(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the answers file: %w", err)
+	}
+
+	var a Answers
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&a)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse the answers file %s: %w", path, err)
+	}
+
+	loaded = &a
+	loadedPath = path
+	log.Info("Loaded answers file " + path)
+	return nil
+}
+
+// Loaded returns true when prompts should be answered from an answers file
+func Loaded() bool {
+	return loaded != nil
+}
+
+// Get returns the loaded answers
+func Get() *Answers {
+	return loaded
+}
+
+// Require returns an answer or a clear error when it is missing from the answers file
+func Require[T any](value *T, key string) (T, error) {
+	var zero T
+	if value == nil {
+		return zero, missingError(key)
+	}
+	if s, ok := any(*value).(string); ok && strings.TrimSpace(s) == "" {
+		return zero, missingError(key)
+	}
+	log.Info(fmt.Sprintf("answers file: %s = %v", key, *value))
+	return *value, nil
+}
+
+// RequireList returns a list answer or a clear error when it is missing from the answers file
+func RequireList(values []string, key string) ([]string, error) {
+	if values == nil {
+		return []string{}, missingError(key)
+	}
+	log.Info(fmt.Sprintf("answers file: %s = %s", key, strings.Join(values, ", ")))
+	return values, nil
+}
+
+// RequireOneOf returns a string answer after ensuring it is one of the valid options
+func RequireOneOf(value *string, key string, options []string) (string, error) {
+	answer, err := Require(value, key)
+	if err != nil {
+		return "", err
+	}
+	if !lo.Contains(options, answer) {
+		return "", fmt.Errorf("the answer %q for %s in the answers file %s is not one of the valid options: %s", answer, key, loadedPath, strings.Join(options, ", "))
+	}
+	return answer, nil
+}
+
+// RequireSubsetOf returns a list answer after ensuring each element is one of the valid options
+func RequireSubsetOf(values []string, key string, options []string) ([]string, error) {
+	answer, err := RequireList(values, key)
+	if err != nil {
+		return []string{}, err
+	}
+	for _, value := range answer {
+		if !lo.Contains(options, value) {
+			return []string{}, fmt.Errorf("the answer %q for %s in the answers file %s is not one of the valid options: %s", value, key, loadedPath, strings.Join(options, ", "))
+		}
+	}
+	return answer, nil
+}
+
+// InvalidError returns an error for an answer that was provided but could not be used
+func InvalidError(key string, reason string) error {
+	return fmt.Errorf("the answer for %s in the answers file %s is not valid: %s", key, loadedPath, reason)
+}
+
+func missingError(key string) error {
+	return fmt.Errorf("the answers file %s is missing a required answer for %s", loadedPath, key)
+}
@@ -0,0 +1,178 @@
+package answers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadTestAnswers writes an answers file to a temporary directory and loads it
+func loadTestAnswers(t *testing.T, content string) (string, error) {
+	path := filepath.Join(t.TempDir(), "answers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	t.Cleanup(func() {
+		loaded, loadedPath = nil, ""
+	})
+	return path, Load(path)
+}
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		content     string
+		expectError string
+	}{
+		"known fields": {
+			content: "prereqs:\n  confirm: true\nlanguages:\n  - R\nr:\n  versions:\n    - 4.3.2\n",
+		},
+		"empty file": {
+			content: "",
+		},
+		"unknown section": {
+			content:     "prereq:\n  confirm: true\n",
+			expectError: "field prereq not found",
+		},
+		"unknown key": {
+			content:     "r:\n  version: 4.3.2\n",
+			expectError: "field version not found",
+		},
+		"wrong type": {
+			content:     "prereqs:\n  confirm: maybe\n",
+			expectError: "cannot unmarshal",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := loadTestAnswers(t, tc.content)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, "failed to parse the answers file "+path)
+				assert.ErrorContains(t, err, tc.expectError)
+				assert.False(t, Loaded())
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, Loaded())
+		})
+	}
+
+	_, err := loadTestAnswers(t, "prereqs:\n  confirm: true\n")
+	require.NoError(t, err)
+	require.NotNil(t, Get().Prereqs.Confirm)
+	assert.True(t, *Get().Prereqs.Confirm)
+	assert.Nil(t, Get().Prereqs.Cloud)
+}
+
+func TestRequire(t *testing.T) {
+	path, err := loadTestAnswers(t, "")
+	require.NoError(t, err)
+	yes := true
+	blank := "  "
+	key := "ABCD-1234"
+
+	confirm, err := Require(&yes, "prereqs.confirm")
+	require.NoError(t, err)
+	assert.True(t, confirm)
+
+	_, err = Require[bool](nil, "prereqs.cloud")
+	assert.EqualError(t, err, "the answers file "+path+" is missing a required answer for prereqs.cloud")
+
+	_, err = Require(&blank, "license.key")
+	assert.EqualError(t, err, "the answers file "+path+" is missing a required answer for license.key")
+
+	answer, err := Require(&key, "license.key")
+	require.NoError(t, err)
+	assert.Equal(t, key, answer)
+
+	_, err = RequireList(nil, "r.versions")
+	assert.EqualError(t, err, "the answers file "+path+" is missing a required answer for r.versions")
+
+	// an empty list is an answer, such as no additional Jupyter kernels
+	versions, err := RequireList([]string{}, "jupyter.additional_kernels")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestRequireOneOf(t *testing.T) {
+	path, err := loadTestAnswers(t, "")
+	require.NoError(t, err)
+	options := []string{"open_ports", "disable", "skip"}
+
+	tests := map[string]struct {
+		value       *string
+		expected    string
+		expectError string
+	}{
+		"valid option": {
+			value:    stringPointer("disable"),
+			expected: "disable",
+		},
+		"missing": {
+			expectError: "the answers file " + path + " is missing a required answer for firewall.action",
+		},
+		"invalid option": {
+			value:       stringPointer("open"),
+			expectError: `the answer "open" for firewall.action in the answers file ` + path + " is not one of the valid options: open_ports, disable, skip",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			answer, err := RequireOneOf(tc.value, "firewall.action", options)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, answer)
+		})
+	}
+}
+
+func TestRequireSubsetOf(t *testing.T) {
+	path, err := loadTestAnswers(t, "")
+	require.NoError(t, err)
+	options := []string{"r", "python"}
+
+	tests := map[string]struct {
+		values      []string
+		expected    []string
+		expectError string
+	}{
+		"valid options": {
+			values:   []string{"python", "r"},
+			expected: []string{"python", "r"},
+		},
+		"missing": {
+			expectError: "the answers file " + path + " is missing a required answer for packagemanager.languages",
+		},
+		"invalid option": {
+			values:      []string{"r", "julia"},
+			expectError: `the answer "julia" for packagemanager.languages in the answers file ` + path + " is not one of the valid options: r, python",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			answer, err := RequireSubsetOf(tc.values, "packagemanager.languages", options)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, answer)
+		})
+	}
+}
+
+func TestInvalidError(t *testing.T) {
+	path, err := loadTestAnswers(t, "")
+	require.NoError(t, err)
+	assert.EqualError(t, InvalidError("ssl.cert_path", "the file does not exist"), "the answer for ssl.cert_path in the answers file "+path+" is not valid: the file does not exist")
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
@@ -0,0 +1,175 @@
+package answers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// TemplateDefaults contains the latest versions used to fill in the generated answers template
+type TemplateDefaults struct {
+	RVersion      string
+	PythonVersion string
+	QuartoVersion string
+}
+
+const answersTemplate = `# wbi setup answers file
+# Generated by "wbi setup --generate-answers". Use it with "wbi setup --answers <file>".
+# Each key answers one prompt of the interactive setup. Keys are only read when the
+# setup flow reaches the matching prompt, for example prereqs.cloud is only read on RHEL.
+# wbi setup fails with a clear message if an answer it needs is missing.
+
+prereqs:
+  # confirm the server is ready for Workbench to be installed
+  confirm: true
+  # whether this server runs in a public cloud such as AWS, Azure or GCP (RHEL only)
+  cloud: false
+
+firewall:
+  # when a local firewall (firewalld or ufw) is active: open_ports, disable or skip
+  action: open_ports
+  # check the opened ports are reachable from localhost
+  verify: false
+
+security:
+  # when SELinux is enforcing: enforcing (keep it and configure it for Workbench), disable or skip (RHEL only)
+  selinux: enforcing
+  # put AppArmor profiles that confine Workbench, R or Python into complain mode (Ubuntu only)
+  apparmor_complain: true
+
+# languages Workbench will be used with, R is required
+languages:
+  - R
+  - python
+
+r:
+  # install version(s) of R into /opt/R
+  install: true
+  versions:
+{{- if .RVersion }}
+    - {{ .RVersion }}
+{{- else }}
+    [] # add versions of R to install, for example 4.3.2
+{{- end }}
+  # symlink a version of R to /usr/local/bin/R and /usr/local/bin/Rscript
+  symlink: true
+  symlink_path: {{ if .RVersion }}/opt/R/{{ .RVersion }}/bin/R{{ else }}"" # for example /opt/R/4.3.2/bin/R{{ end }}
+
+python:
+  # install version(s) of Python into /opt/python
+  install: true
+  versions:
+{{- if .PythonVersion }}
+    - {{ .PythonVersion }}
+{{- else }}
+    [] # add versions of Python to install, for example 3.11.7
+{{- end }}
+  # add a Python bin directory to PATH in /etc/profile.d/wbi_python.sh
+  add_to_path: true
+  path_dir: {{ if .PythonVersion }}/opt/python/{{ .PythonVersion }}/bin{{ else }}"" # for example /opt/python/3.11.7/bin{{ end }}
+
+workbench:
+  # install Workbench if it is not already installed
+  install: true
+
+license:
+  # activate Workbench with a license key
+  activate: true
+  # replace with your license key, XXXX-XXXX-XXXX-XXXX-XXXX-XXXX-XXXX
+  key: ""
+
+r_versions:
+  # list the versions of R installed in /opt/R in /etc/rstudio/r-versions, existing entries are kept
+  configure: true
+  # label for each new entry, {version} is replaced with the version of R
+  label: "R {version}"
+  # only offer the versions of R listed in r-versions (r-versions-scan=0)
+  disable_scan: false
+  # use the version of R symlinked to /usr/local/bin/R as the default (rsession-which-r)
+  set_default: true
+
+quarto:
+  # install version(s) of Quarto in addition to the version bundled with Workbench
+  install: false
+  versions: []{{ if .QuartoVersion }} # for example {{ .QuartoVersion }}{{ end }}
+  # symlink a version of Quarto to /usr/local/bin/quarto
+  symlink: true
+  symlink_path: {{ if .QuartoVersion }}/opt/quarto/{{ .QuartoVersion }}/bin/quarto{{ else }}"" # for example /opt/quarto/v1.4.550/bin/quarto{{ end }}
+
+jupyter:
+  # install Jupyter and configure it in /etc/rstudio/jupyter.conf
+  install: true
+  python_path: {{ if .PythonVersion }}/opt/python/{{ .PythonVersion }}/bin/python{{ else }}"" # for example /opt/python/3.11.7/bin/python{{ end }}
+  # other Python binaries to register as Jupyter kernels
+  additional_kernels: []
+
+prodrivers:
+  # install the Posit Pro Drivers
+  install: true
+
+ssl:
+  # configure Workbench to use SSL, the remaining keys are only read when enabled
+  enabled: false
+  server_url: "" # for example https://workbench.example.com
+  cert_path: ""
+  key_path: ""
+  # proceed when the certificate does not match the hostname of this server
+  allow_hostname_mismatch: false
+  # proceed when the certificate chain does not include a root CA
+  allow_missing_root_ca: false
+  # add an untrusted root CA to the system trust store
+  trust_root_ca: true
+
+packagemanager:
+  # private (your own Posit Package Manager), public (Posit Public Package Manager) or skip
+  source: public
+  # the keys below are only read when source is private
+  languages:
+    - r
+    - python
+  url: "" # for example https://packagemanager.example.com
+  r_repo: "" # for example prod-cran
+  python_repo: "" # for example pypi
+
+connect:
+  # configure a default Connect server
+  enabled: false
+  url: "" # for example https://connect.example.com
+
+ad:
+  # join this server to an Active Directory domain with realmd and SSSD
+  join: false
+  domain: "" # for example ad.example.com
+  join_user: ""
+  # file containing the password of join_user
+  password_file: ""
+  computer_ou: ""
+  # domain user to look up once joined
+  verify_user: ""
+
+verify:
+  # run rstudio-server verify-installation as a non-root local user
+  enabled: false
+  user: ""
+`
+
+// WriteTemplate writes an answers file containing the recommended defaults
+func WriteTemplate(path string, defaults TemplateDefaults) error {
+	tmpl, err := template.New("answers").Parse(answersTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse the answers template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, defaults)
+	if err != nil {
+		return fmt.Errorf("failed to render the answers template: %w", err)
+	}
+
+	err = os.WriteFile(path, buf.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write the answers file: %w", err)
+	}
+	return nil
+}
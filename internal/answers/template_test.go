@@ -0,0 +1,66 @@
+package answers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteTemplate tests the generated answers file loads back with every key known
+func TestWriteTemplate(t *testing.T) {
+	t.Cleanup(func() {
+		loaded, loadedPath = nil, ""
+	})
+
+	tests := map[string]struct {
+		defaults TemplateDefaults
+		check    func(t *testing.T, a *Answers)
+	}{
+		"latest versions": {
+			defaults: TemplateDefaults{RVersion: "4.3.2", PythonVersion: "3.11.7", QuartoVersion: "v1.4.550"},
+			check: func(t *testing.T, a *Answers) {
+				assert.Equal(t, []string{"4.3.2"}, a.R.Versions)
+				require.NotNil(t, a.R.SymlinkPath)
+				assert.Equal(t, "/opt/R/4.3.2/bin/R", *a.R.SymlinkPath)
+				assert.Equal(t, []string{"3.11.7"}, a.Python.Versions)
+				require.NotNil(t, a.Python.PATHDir)
+				assert.Equal(t, "/opt/python/3.11.7/bin", *a.Python.PATHDir)
+				// Quarto is only installed in addition to the bundled version when asked for
+				assert.Empty(t, a.Quarto.Versions)
+				require.NotNil(t, a.Quarto.SymlinkPath)
+				assert.Equal(t, "/opt/quarto/v1.4.550/bin/quarto", *a.Quarto.SymlinkPath)
+			},
+		},
+		"no versions": {
+			check: func(t *testing.T, a *Answers) {
+				assert.Empty(t, a.R.Versions)
+				require.NotNil(t, a.R.SymlinkPath)
+				assert.Equal(t, "", *a.R.SymlinkPath)
+				assert.Empty(t, a.Python.Versions)
+				assert.Empty(t, a.Quarto.Versions)
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "answers.yaml")
+			require.NoError(t, WriteTemplate(path, tc.defaults))
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			require.NoError(t, Load(path))
+			a := Get()
+			require.NotNil(t, a.Prereqs.Confirm)
+			assert.True(t, *a.Prereqs.Confirm)
+			firewallAction, err := RequireOneOf(a.Firewall.Action, "firewall.action", []string{"open_ports", "disable", "skip"})
+			require.NoError(t, err)
+			assert.Equal(t, "open_ports", firewallAction)
+			tc.check(t, a)
+		})
+	}
+}
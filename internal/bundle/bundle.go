@@ -0,0 +1,314 @@
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/mirrors"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+const manifestName = "manifest.json"
+
+// FormatVersion is the version of the bundle layout, bumped when the manifest changes incompatibly
+const FormatVersion = 1
+
+// Manifest describes the contents of an offline bundle and is stored as manifest.json in the archive
+type Manifest struct {
+	FormatVersion int    `json:"format_version"`
+	CreatedAt     string `json:"created_at"`
+	OS            string `json:"os"`
+	// Arch is amd64 or arm64, bundles created before arm64 was supported leave it empty and are amd64
+	Arch           string   `json:"arch,omitempty"`
+	RVersions      []string `json:"r_versions"`
+	PythonVersions []string `json:"python_versions"`
+	QuartoVersions []string `json:"quarto_versions"`
+	Workbench      bool     `json:"workbench"`
+	ProDrivers     bool     `json:"prodrivers"`
+	Jupyter        bool     `json:"jupyter"`
+	Files          []File   `json:"files"`
+	// Missing are URLs that did not exist when the bundle was created, such as the checksums file of Quarto releases
+	// that don't publish one, so they are answered offline instead of from the network
+	Missing []string `json:"missing,omitempty"`
+}
+
+// File is a single file in a bundle. Files with a URL are served in place of that URL, files without
+// one (pip wheels) are only used from disk.
+type File struct {
+	URL    string `json:"url,omitempty"`
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+var (
+	loaded     *Manifest
+	extractDir string
+	urlPaths   = map[string]string{}
+	missing    = map[string]bool{}
+	layerAdded bool
+)
+
+// osNames maps the names accepted by wbi bundle create to operating systems
+var osNames = map[string]config.OperatingSystem{
+	"focal": config.Ubuntu20,
+	"jammy": config.Ubuntu22,
+	"noble": config.Ubuntu24,
+	"rhel7": config.Redhat7,
+	"rhel8": config.Redhat8,
+	"rhel9": config.Redhat9,
+}
+
+// ValidOSNames returns the operating system names a bundle can be created for
+func ValidOSNames() []string {
+	return []string{"focal", "jammy", "noble", "rhel7", "rhel8", "rhel9"}
+}
+
+// ParseOS converts an operating system name such as jammy or rhel9 to an operating system
+func ParseOS(name string) (config.OperatingSystem, error) {
+	osType, ok := osNames[strings.ToLower(name)]
+	if !ok {
+		return config.Unknown, fmt.Errorf("invalid operating system %s, valid options are: %s", name, strings.Join(ValidOSNames(), ", "))
+	}
+	return osType, nil
+}
+
+// Load extracts a bundle and serves every URL it contains from disk instead of the network
+func Load(path string) error {
+	system.PrintAndLogInfo("Extracting the offline bundle " + path)
+	archive, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the bundle: %w", err)
+	}
+	defer archive.Close()
+
+	dir, err := os.MkdirTemp("", "wbi-bundle-")
+	if err != nil {
+		return fmt.Errorf("failed to create a directory to extract the bundle to: %w", err)
+	}
+
+	var manifest *Manifest
+	reader := tar.NewReader(archive)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if header.Name == manifestName {
+			manifest = &Manifest{}
+			err = json.NewDecoder(reader).Decode(manifest)
+			if err != nil {
+				return fmt.Errorf("failed to parse the bundle manifest: %w", err)
+			}
+			continue
+		}
+
+		err = extractFile(reader, dir, header.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if manifest == nil {
+		return errors.New("the bundle does not contain a " + manifestName + ", is it a wbi bundle?")
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return fmt.Errorf("the bundle format version %d is newer than this version of wbi supports (%d), please upgrade wbi", manifest.FormatVersion, FormatVersion)
+	}
+
+	paths := map[string]string{}
+	for _, file := range manifest.Files {
+		localPath := filepath.Join(dir, filepath.FromSlash(file.Path))
+		sum, err := fileSHA256(localPath)
+		if err != nil {
+			return fmt.Errorf("the bundle is missing %s: %w", file.Path, err)
+		}
+		if sum != file.SHA256 {
+			return fmt.Errorf("the checksum of %s in the bundle does not match the manifest, the bundle may be corrupt", file.Path)
+		}
+		if file.URL != "" {
+			paths[file.URL] = localPath
+		}
+	}
+
+	missingURLs := map[string]bool{}
+	for _, missingURL := range manifest.Missing {
+		missingURLs[missingURL] = true
+	}
+
+	loaded = manifest
+	extractDir = dir
+	urlPaths = paths
+	missing = missingURLs
+	// every HTTP client created afterwards reads from the bundle first
+	if !layerAdded {
+		httpclient.AddLayer(func(next http.RoundTripper) http.RoundTripper {
+			return &transport{fallback: next}
+		})
+		layerAdded = true
+	}
+
+	system.PrintAndLogInfo(fmt.Sprintf("Loaded the offline bundle for %s created %s", manifest.OS, manifest.CreatedAt))
+	return nil
+}
+
+// extractFile writes a file from the archive into the extraction directory
+func extractFile(reader io.Reader, dir string, name string) error {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return fmt.Errorf("the bundle contains an invalid path: %s", name)
+	}
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	defer out.Close()
+	_, err = io.Copy(out, reader)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	return nil
+}
+
+// Active returns true when an offline bundle has been loaded
+func Active() bool {
+	return loaded != nil
+}
+
+// Get returns the manifest of the loaded bundle
+func Get() *Manifest {
+	return loaded
+}
+
+// CheckOS returns an error when the loaded bundle was created for a different operating system or architecture
+func CheckOS(osType config.OperatingSystem, arch config.Architecture) error {
+	if !Active() {
+		return nil
+	}
+	bundleOS, err := ParseOS(loaded.OS)
+	if err != nil {
+		return err
+	}
+	if bundleOS != osType {
+		return fmt.Errorf("the bundle was created for %s but this server is running %s", loaded.OS, osType.ToString())
+	}
+	bundleArch := config.AMD64
+	if loaded.Arch != "" {
+		bundleArch, err = config.ParseArchitecture(loaded.Arch)
+		if err != nil {
+			return err
+		}
+	}
+	if bundleArch != arch {
+		return fmt.Errorf("the bundle was created for %s but this server is %s", bundleArch.ToString(), arch.ToString())
+	}
+	return nil
+}
+
+// Lookup returns the local path of a URL in the loaded bundle. Bundles record upstream URLs, so a
+// bundle is used the same way whichever mirrors are configured.
+func Lookup(url string) (string, bool) {
+	path, ok := urlPaths[mirrors.Canonical(url)]
+	return path, ok
+}
+
+// Missing returns true when a URL did not exist when the loaded bundle was created
+func Missing(url string) bool {
+	return missing[mirrors.Canonical(url)]
+}
+
+// PipIndexArgs returns the pip install arguments that install packages from the bundled wheels
+// instead of PyPI, or an empty string when no bundle is loaded
+func PipIndexArgs() string {
+	if !Active() {
+		return ""
+	}
+	return " --no-index --find-links=" + filepath.Join(extractDir, "wheels")
+}
+
+// transport serves GET requests for bundled URLs from disk, answers URLs the bundle records as missing with
+// 404 Not Found and sends everything else to the network, such as requests to an internal Package Manager or
+// Connect server
+type transport struct {
+	fallback http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		if path, ok := Lookup(req.URL.String()); ok {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %s from the bundle: %w", req.URL.String(), err)
+			}
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to open %s from the bundle: %w", req.URL.String(), err)
+			}
+			log.Info("Serving " + req.URL.String() + " from the offline bundle")
+			return &http.Response{
+				Status:        "200 OK",
+				StatusCode:    http.StatusOK,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{},
+				Body:          file,
+				ContentLength: info.Size(),
+				Request:       req,
+			}, nil
+		}
+		if Missing(req.URL.String()) {
+			log.Info(req.URL.String() + " did not exist when the offline bundle was created")
+			return &http.Response{
+				Status:     "404 Not Found",
+				StatusCode: http.StatusNotFound,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+	}
+	log.Info(req.URL.String() + " is not in the offline bundle, requesting it from the network")
+	return t.fallback.RoundTrip(req)
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,56 @@
+package bundle
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateAndLoad creates a bundle, loads it and checks bundled URLs are served from it
+func TestCreateAndLoad(t *testing.T) {
+	builder, err := NewBuilder("jammy", config.AMD64)
+	require.NoError(t, err)
+	defer builder.Cleanup()
+
+	versionsURL := "https://cdn.posit.co/r/versions.json"
+	err = builder.AddContent(versionsURL, "metadata/r-versions.json", []byte(`{"r_versions":["4.3.2"]}`))
+	require.NoError(t, err)
+	builder.Manifest().RVersions = []string{"4.3.2"}
+	checksumsURL := "https://github.com/quarto-dev/quarto-cli/releases/download/v1.3.450/quarto-1.3.450-checksums.txt"
+	builder.AddMissing(checksumsURL)
+
+	archivePath := filepath.Join(t.TempDir(), "wbi-bundle-jammy.tar")
+	require.NoError(t, builder.Write(archivePath))
+	require.NoError(t, Load(archivePath))
+
+	assert.True(t, Active())
+	assert.Equal(t, []string{"4.3.2"}, Get().RVersions)
+	assert.Equal(t, "amd64", Get().Arch)
+	assert.NoError(t, CheckOS(config.Ubuntu22, config.AMD64))
+	assert.Error(t, CheckOS(config.Redhat9, config.AMD64))
+	assert.ErrorContains(t, CheckOS(config.Ubuntu22, config.ARM64), "the bundle was created for amd64 but this server is arm64")
+
+	res, err := httpclient.New().Get(versionsURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"r_versions":["4.3.2"]}`, string(body))
+
+	// URLs that didn't exist when the bundle was created are answered without the network
+	assert.True(t, Missing(checksumsURL))
+	res, err = httpclient.New().Get(checksumsURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	_, ok := Lookup("https://cdn.posit.co/python/versions.json")
+	assert.False(t, ok)
+	assert.False(t, Missing("https://cdn.posit.co/python/versions.json"))
+}
@@ -0,0 +1,239 @@
+package bundle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/install"
+	"github.com/sol-eng/wbi/internal/mirrors"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// Builder collects installers, version metadata and pip wheels into an offline bundle
+type Builder struct {
+	manifest   Manifest
+	arch       config.Architecture
+	stagingDir string
+}
+
+// NewBuilder creates a builder for a bundle targeting an operating system name such as jammy on an architecture
+func NewBuilder(osName string, arch config.Architecture) (*Builder, error) {
+	_, err := ParseOS(osName)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "wbi-bundle-create-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a staging directory: %w", err)
+	}
+	return &Builder{
+		manifest: Manifest{
+			FormatVersion: FormatVersion,
+			CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+			OS:            strings.ToLower(osName),
+			Arch:          arch.ToString(),
+		},
+		arch:       arch,
+		stagingDir: dir,
+	}, nil
+}
+
+// Manifest returns the manifest of the bundle being built so the versions and components can be recorded
+func (b *Builder) Manifest() *Manifest {
+	return &b.manifest
+}
+
+// AddURL downloads a URL into the bundle so it is served from the bundle in place of the network,
+// verifying the SHA-256 digest of the download when one is provided
+func (b *Builder) AddURL(name string, fileURL string, sha256 string) error {
+	parsed, err := url.Parse(fileURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %s: %w", fileURL, err)
+	}
+	downloadPath, err := install.DownloadFile(name, fileURL, path.Base(parsed.Path), sha256)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", fileURL, err)
+	}
+	defer install.RemoveDownload(downloadPath)
+
+	bundlePath := "files/" + path.Base(parsed.Path)
+	localPath, err := b.stagingPath(bundlePath)
+	if err != nil {
+		return err
+	}
+	err = copyFile(downloadPath, localPath)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", fileURL, err)
+	}
+	return b.addFile(fileURL, bundlePath)
+}
+
+// AddContent adds generated content to the bundle, served in place of a URL when one is provided
+func (b *Builder) AddContent(fileURL string, bundlePath string, content []byte) error {
+	localPath, err := b.stagingPath(bundlePath)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(localPath, content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	return b.addFile(fileURL, bundlePath)
+}
+
+// AddMissing records a URL that does not exist, so the bundle answers it with 404 Not Found in place of the network
+func (b *Builder) AddMissing(fileURL string) {
+	b.manifest.Missing = append(b.manifest.Missing, mirrors.Canonical(fileURL))
+}
+
+// AddWheels downloads pip wheels for the packages and their dependencies for a Python version, such as 3.11.7
+func (b *Builder) AddWheels(pythonVersion string, packages []string) error {
+	parts := strings.Split(pythonVersion, ".")
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid Python version %s", pythonVersion)
+	}
+	wheelDir := filepath.Join(b.stagingDir, "wheels")
+	existing, _ := os.ReadDir(wheelDir)
+
+	downloadCommand := "python3 -m pip download --disable-pip-version-check --only-binary=:all: --platform manylinux2014_" + b.arch.RPM() + " --python-version " +
+		parts[0] + "." + parts[1] + " --dest " + wheelDir + " " + strings.Join(packages, " ")
+	err := system.RunCommand(downloadCommand, true, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue downloading pip wheels with the command '%s': %w", downloadCommand, err)
+	}
+
+	known := map[string]bool{}
+	for _, entry := range existing {
+		known[entry.Name()] = true
+	}
+	entries, err := os.ReadDir(wheelDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", wheelDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || known[entry.Name()] {
+			continue
+		}
+		err = b.addFile("", "wheels/"+entry.Name())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stagingPath returns the location a file is staged at before it is written to the archive
+func (b *Builder) stagingPath(bundlePath string) (string, error) {
+	for _, file := range b.manifest.Files {
+		if file.Path == bundlePath {
+			return "", fmt.Errorf("the bundle already contains %s", bundlePath)
+		}
+	}
+	localPath := filepath.Join(b.stagingDir, filepath.FromSlash(bundlePath))
+	err := os.MkdirAll(filepath.Dir(localPath), 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	return localPath, nil
+}
+
+func copyFile(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func (b *Builder) addFile(fileURL string, bundlePath string) error {
+	localPath := filepath.Join(b.stagingDir, filepath.FromSlash(bundlePath))
+	sum, err := fileSHA256(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", bundlePath, err)
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", bundlePath, err)
+	}
+	b.manifest.Files = append(b.manifest.Files, File{URL: mirrors.Canonical(fileURL), Path: bundlePath, SHA256: sum, Size: info.Size()})
+	return nil
+}
+
+// Write creates the tar archive with the manifest first, followed by every file in the bundle
+func (b *Builder) Write(archivePath string) error {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", archivePath, err)
+	}
+	defer out.Close()
+	writer := tar.NewWriter(out)
+
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to create the bundle manifest: %w", err)
+	}
+	err = writer.WriteHeader(&tar.Header{Name: manifestName, Mode: 0644, Size: int64(len(manifest)), ModTime: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to write the bundle manifest: %w", err)
+	}
+	_, err = writer.Write(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to write the bundle manifest: %w", err)
+	}
+
+	files := append([]File{}, b.manifest.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	for _, file := range files {
+		err = b.writeFile(writer, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish writing %s: %w", archivePath, err)
+	}
+	return nil
+}
+
+func (b *Builder) writeFile(writer *tar.Writer, file File) error {
+	localPath := filepath.Join(b.stagingDir, filepath.FromSlash(file.Path))
+	in, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer in.Close()
+
+	err = writer.WriteHeader(&tar.Header{Name: file.Path, Mode: 0644, Size: file.Size, ModTime: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", file.Path, err)
+	}
+	_, err = io.Copy(writer, in)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", file.Path, err)
+	}
+	return nil
+}
+
+// Cleanup removes the staging directory
+func (b *Builder) Cleanup() {
+	os.RemoveAll(b.stagingDir)
+}
@@ -0,0 +1,315 @@
+package conffile
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// File is a key/value configuration file such as rserver.conf, rsession.conf,
+// repos.conf or jupyter.conf. INI style files such as pip.conf are supported
+// through Section. Comments, blank lines and ordering are preserved when saved.
+type File struct {
+	path     string
+	exists   bool
+	original string
+	lines    []*line
+}
+
+// Section is a named [section] of an INI style configuration file. Files such as launcher.conf repeat a section
+// name, index is the occurrence of the name, starting at 0.
+type Section struct {
+	file  *File
+	name  string
+	index int
+}
+
+type line struct {
+	raw     string
+	section string
+	// index counts earlier sections with the same name
+	index  int
+	header bool
+	key    string
+	sep    string
+	value  string
+	// modified lines are rewritten, all other lines are written exactly as they were read
+	modified bool
+}
+
+// Load reads and parses a configuration file, a file that does not exist is treated as empty
+func Load(path string) (*File, error) {
+	f := &File{path: path}
+
+	content, exists, err := system.ReadPlannedFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	f.exists = exists
+	f.original = content
+
+	section, index := "", 0
+	occurrences := map[string]int{}
+	for _, raw := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		if raw == "" && content == "" {
+			break
+		}
+		l := parseLine(raw, section)
+		if l.header {
+			section = l.section
+			index = occurrences[section]
+			occurrences[section]++
+		}
+		l.index = index
+		f.lines = append(f.lines, l)
+	}
+	return f, nil
+}
+
+func parseLine(raw string, section string) *line {
+	l := &line{raw: raw, section: section}
+	trimmed := strings.TrimSpace(raw)
+	switch {
+	case trimmed == "", strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, ";"):
+		return l
+	case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+		l.header = true
+		l.section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+		return l
+	}
+
+	index := strings.Index(raw, "=")
+	if index == -1 {
+		return l
+	}
+	key := strings.TrimRight(raw[:index], " \t")
+	value := strings.TrimLeft(raw[index+1:], " \t")
+	l.key = strings.TrimSpace(key)
+	l.sep = raw[len(key) : len(raw)-len(value)]
+	l.value = strings.TrimSpace(value)
+	return l
+}
+
+func (l *line) String() string {
+	if !l.modified {
+		return l.raw
+	}
+	return l.key + l.sep + l.value
+}
+
+// Path returns the location of the configuration file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Get returns the value of a key outside of any section
+func (f *File) Get(key string) (string, bool) {
+	return f.get("", 0, key)
+}
+
+// Set updates a key outside of any section in place, or adds it if it doesn't exist
+func (f *File) Set(key string, value string) {
+	f.set("", 0, key, value)
+}
+
+// Delete removes every occurrence of a key outside of any section
+func (f *File) Delete(key string) {
+	f.delete("", 0, key)
+}
+
+// Section returns the first [section] with a name, it is created when a key is first set in it
+func (f *File) Section(name string) *Section {
+	return &Section{file: f, name: name}
+}
+
+// Sections returns every [section] with a name, in the order they appear in the file
+func (f *File) Sections(name string) []*Section {
+	var sections []*Section
+	for _, l := range f.lines {
+		if l.header && l.section == name {
+			sections = append(sections, &Section{file: f, name: name, index: l.index})
+		}
+	}
+	return sections
+}
+
+// AddSection returns a new [section] with a name after any existing sections, it is created when a key is first set
+// in it
+func (f *File) AddSection(name string) *Section {
+	return &Section{file: f, name: name, index: len(f.Sections(name))}
+}
+
+// Get returns the value of a key in the section
+func (s *Section) Get(key string) (string, bool) {
+	return s.file.get(s.name, s.index, key)
+}
+
+// Set updates a key in the section in place, or adds it if it doesn't exist
+func (s *Section) Set(key string, value string) {
+	s.file.set(s.name, s.index, key, value)
+}
+
+// Delete removes every occurrence of a key in the section
+func (s *Section) Delete(key string) {
+	s.file.delete(s.name, s.index, key)
+}
+
+func (l *line) in(section string, index int) bool {
+	return l.section == section && l.index == index
+}
+
+func (f *File) get(section string, index int, key string) (string, bool) {
+	for _, l := range f.lines {
+		if l.in(section, index) && l.key == key {
+			return l.value, true
+		}
+	}
+	return "", false
+}
+
+func (f *File) set(section string, index int, key string, value string) {
+	found := false
+	kept := f.lines[:0]
+	for _, l := range f.lines {
+		if l.in(section, index) && l.key == key {
+			// keep the first occurrence and drop any duplicates
+			if found {
+				continue
+			}
+			found = true
+			if l.value != value {
+				l.value = value
+				l.modified = true
+			}
+		}
+		kept = append(kept, l)
+	}
+	f.lines = kept
+	if found {
+		return
+	}
+
+	newLine := &line{section: section, index: index, key: key, sep: "=", value: value, modified: true}
+	if section != "" {
+		// use the same separator style as the rest of the section, pip.conf commonly uses " = "
+		for _, l := range f.lines {
+			if l.section == section && l.key != "" {
+				newLine.sep = l.sep
+				break
+			}
+		}
+	}
+	f.insert(section, index, newLine)
+}
+
+// insert adds a line after the last key of a section, creating the section if needed
+func (f *File) insert(section string, index int, newLine *line) {
+	position := -1
+	sectionExists := section == ""
+	for i, l := range f.lines {
+		if l.header && l.in(section, index) {
+			sectionExists = true
+			position = i
+		}
+		if l.in(section, index) && !l.header && l.key != "" {
+			position = i
+		}
+	}
+
+	if !sectionExists {
+		if len(f.lines) > 0 && strings.TrimSpace(f.lines[len(f.lines)-1].raw) != "" {
+			f.lines = append(f.lines, &line{})
+		}
+		f.lines = append(f.lines, &line{raw: "[" + section + "]", section: section, index: index, header: true}, newLine)
+		return
+	}
+
+	if position == -1 {
+		if section == "" {
+			// keys outside of any section must come before the first section header
+			for i, l := range f.lines {
+				if l.header {
+					f.lines = append(f.lines[:i], append([]*line{newLine}, f.lines[i:]...)...)
+					return
+				}
+			}
+		}
+		f.lines = append(f.lines, newLine)
+		return
+	}
+	f.lines = append(f.lines[:position+1], append([]*line{newLine}, f.lines[position+1:]...)...)
+}
+
+func (f *File) delete(section string, index int, key string) {
+	kept := f.lines[:0]
+	for _, l := range f.lines {
+		if l.in(section, index) && l.key == key {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	f.lines = kept
+}
+
+// String returns the content of the file including any changes
+func (f *File) String() string {
+	if len(f.lines) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, l := range f.lines {
+		b.WriteString(l.String() + "\n")
+	}
+	return b.String()
+}
+
+// Changed returns true when the content differs from the file on disk
+func (f *File) Changed() bool {
+	return !f.exists || f.String() != f.original
+}
+
+// Save writes the file if it changed, an existing file is first backed up alongside
+// the original with a timestamp suffix. Saving a file that has not changed does nothing.
+func (f *File) Save(perm fs.FileMode) error {
+	return f.save(perm, nil)
+}
+
+// SaveSecret writes a file holding passwords or tokens like Save, but the values of the secret keys are left out
+// of the command log and the dry run plan, and the permissions are also set on an existing file
+func (f *File) SaveSecret(perm fs.FileMode, secretKeys ...string) error {
+	return f.save(perm, secretKeys)
+}
+
+func (f *File) save(perm fs.FileMode, secretKeys []string) error {
+	content := f.String()
+	var err error
+	if len(secretKeys) > 0 {
+		err = system.WriteSecretFileWithBackup(f.path, content, f.redacted(secretKeys), f.original, f.exists, perm)
+	} else {
+		err = system.WriteFileWithBackup(f.path, content, f.original, f.exists, perm)
+	}
+	if err != nil || system.DryRun() {
+		return err
+	}
+
+	f.exists = true
+	f.original = content
+	return nil
+}
+
+// redacted returns the content of the file with the values of the secret keys replaced
+func (f *File) redacted(secretKeys []string) string {
+	var b strings.Builder
+	for _, l := range f.lines {
+		value := l.String()
+		for _, key := range secretKeys {
+			if l.key == key {
+				value = l.key + l.sep + "********"
+			}
+		}
+		b.WriteString(value + "\n")
+	}
+	return b.String()
+}
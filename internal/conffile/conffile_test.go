@@ -0,0 +1,166 @@
+package conffile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdit(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		edit     func(f *File)
+		expected string
+	}{
+		"set adds a key to an empty file": {
+			content:  "",
+			edit:     func(f *File) { f.Set("CRAN", "https://packagemanager.posit.co/cran/latest") },
+			expected: "CRAN=https://packagemanager.posit.co/cran/latest\n",
+		},
+		"set updates an existing key in place": {
+			content:  "# rserver.conf\nssl-enabled=1\nssl-certificate=/old.crt\nwww-port=443\n",
+			edit:     func(f *File) { f.Set("ssl-certificate", "/new.crt") },
+			expected: "# rserver.conf\nssl-enabled=1\nssl-certificate=/new.crt\nwww-port=443\n",
+		},
+		"set ignores a commented out key": {
+			content:  "# jupyter-exe=/usr/local/bin/jupyter\n",
+			edit:     func(f *File) { f.Set("jupyter-exe", "/opt/python/jupyter/bin/jupyter") },
+			expected: "# jupyter-exe=/usr/local/bin/jupyter\njupyter-exe=/opt/python/jupyter/bin/jupyter\n",
+		},
+		"set removes duplicate keys": {
+			content:  "CRAN=https://a\nRSPM=https://b\nCRAN=https://c\n",
+			edit:     func(f *File) { f.Set("CRAN", "https://d") },
+			expected: "CRAN=https://d\nRSPM=https://b\n",
+		},
+		"set with the same value leaves the line untouched": {
+			content:  "ssl-enabled = 1\n",
+			edit:     func(f *File) { f.Set("ssl-enabled", "1") },
+			expected: "ssl-enabled = 1\n",
+		},
+		"delete removes every occurrence of a key": {
+			content:  "launcher-sessions-callback-address=http://a\n# comment\nlauncher-sessions-callback-address=http://b\nwww-port=80\n",
+			edit:     func(f *File) { f.Delete("launcher-sessions-callback-address") },
+			expected: "# comment\nwww-port=80\n",
+		},
+		"section set creates the section": {
+			content:  "",
+			edit:     func(f *File) { f.Section("global").Set("index-url", "https://pypi") },
+			expected: "[global]\nindex-url=https://pypi\n",
+		},
+		"section set updates the key in its section only": {
+			content:  "[global]\ntimeout = 60\nindex-url = https://old\n\n[install]\nindex-url = https://other\n",
+			edit:     func(f *File) { f.Section("global").Set("index-url", "https://new") },
+			expected: "[global]\ntimeout = 60\nindex-url = https://new\n\n[install]\nindex-url = https://other\n",
+		},
+		"section set adds the key after the last key of the section": {
+			content:  "[global]\ntimeout = 60\n\n[install]\nuser = true\n",
+			edit:     func(f *File) { f.Section("global").Set("index-url", "https://new") },
+			expected: "[global]\ntimeout = 60\nindex-url = https://new\n\n[install]\nuser = true\n",
+		},
+		"section set updates the first of repeated sections": {
+			content:  "[cluster]\nname=Local\ntype=Local\n\n[cluster]\nname=Kubernetes\ntype=Kubernetes\n",
+			edit:     func(f *File) { f.Section("cluster").Set("name", "Local2") },
+			expected: "[cluster]\nname=Local2\ntype=Local\n\n[cluster]\nname=Kubernetes\ntype=Kubernetes\n",
+		},
+		"sections updates a repeated section": {
+			content:  "[server]\nport=5559\n\n[cluster]\nname=Local\ntype=Local\n\n[cluster]\nname=Kubernetes\ntype=Kubernetes\n",
+			edit:     func(f *File) { f.Sections("cluster")[1].Set("config-file", "/etc/rstudio/launcher.kubernetes.conf") },
+			expected: "[server]\nport=5559\n\n[cluster]\nname=Local\ntype=Local\n\n[cluster]\nname=Kubernetes\ntype=Kubernetes\nconfig-file=/etc/rstudio/launcher.kubernetes.conf\n",
+		},
+		"add section appends a repeated section": {
+			content: "[cluster]\nname=Local\ntype=Local\n",
+			edit: func(f *File) {
+				cluster := f.AddSection("cluster")
+				cluster.Set("name", "Kubernetes")
+				cluster.Set("type", "Kubernetes")
+			},
+			expected: "[cluster]\nname=Local\ntype=Local\n\n[cluster]\nname=Kubernetes\ntype=Kubernetes\n",
+		},
+		"top level set is added before the first section": {
+			content:  "# pip.conf\n[global]\ntimeout = 60\n",
+			edit:     func(f *File) { f.Set("key", "value") },
+			expected: "# pip.conf\nkey=value\n[global]\ntimeout = 60\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.conf")
+			if tc.content != "" {
+				require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+			}
+
+			f, err := Load(path)
+			require.NoError(t, err)
+			tc.edit(f)
+			assert.Equal(t, tc.expected, f.String())
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rsession.conf")
+	require.NoError(t, os.WriteFile(path, []byte("default-rsconnect-server=https://old\n"), 0644))
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	f.Set("default-rsconnect-server", "https://new")
+	require.NoError(t, f.Save(0644))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "default-rsconnect-server=https://new\n", string(content))
+
+	backups, err := filepath.Glob(path + ".bak-*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "default-rsconnect-server=https://old\n", string(backup))
+
+	// saving again without changes is a no-op and does not create another backup
+	f, err = Load(path)
+	require.NoError(t, err)
+	f.Set("default-rsconnect-server", "https://new")
+	assert.False(t, f.Changed())
+	require.NoError(t, f.Save(0644))
+	backups, err = filepath.Glob(path + ".bak-*")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestSaveSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.conf")
+	require.NoError(t, os.WriteFile(path, []byte("provider=sqlite\n"), 0644))
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	f.Set("provider", "postgresql")
+	f.Set("password", "p@ss")
+	assert.Equal(t, "provider=postgresql\npassword=********\n", f.redacted([]string{"password"}))
+	require.NoError(t, f.SaveSecret(0600, "password"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "provider=postgresql\npassword=p@ss\n", string(content))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	system.SetDryRun(true)
+	t.Cleanup(func() { system.SetDryRun(false) })
+	f, err = Load(path)
+	require.NoError(t, err)
+	f.Set("password", "n3w p@ss")
+	require.NoError(t, f.SaveSecret(0600, "password"))
+	for _, step := range system.Plan() {
+		assert.NotContains(t, step.Description+step.Diff, "p@ss")
+	}
+	require.Len(t, system.Plan(), 2)
+	assert.Equal(t, "cat > "+path+" <<'EOF'\nprovider=postgresql\npassword=********\nEOF\nchmod 600 "+path, system.Plan()[1].Description)
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+type Architecture int
+
+const (
+	AMD64 Architecture = iota
+	ARM64
+)
+
+// host is the architecture of this server, read from the kernel like uname -m so an amd64 build of wbi running under
+// emulation on an arm64 server still installs arm64 packages
+var host = detectHostArchitecture()
+
+func detectHostArchitecture() Architecture {
+	var uname unix.Utsname
+	if unix.Uname(&uname) == nil {
+		arch, err := ParseArchitecture(unix.ByteSliceToString(uname.Machine[:]))
+		if err == nil {
+			return arch
+		}
+	}
+	return detectArchitecture(runtime.GOARCH)
+}
+
+func detectArchitecture(goarch string) Architecture {
+	if goarch == "arm64" {
+		return ARM64
+	}
+	return AMD64
+}
+
+// HostArchitecture returns the architecture of this server
+func HostArchitecture() Architecture {
+	return host
+}
+
+// ParseArchitecture converts a name such as amd64, x86_64, arm64 or aarch64 to an architecture
+func ParseArchitecture(name string) (Architecture, error) {
+	switch strings.ToLower(name) {
+	case "amd64", "x86_64":
+		return AMD64, nil
+	case "arm64", "aarch64":
+		return ARM64, nil
+	default:
+		return AMD64, fmt.Errorf("invalid architecture %s, valid options are: amd64, arm64", name)
+	}
+}
+
+func (arch Architecture) ToString() string {
+	switch arch {
+	case ARM64:
+		return "arm64"
+	default:
+		return "amd64"
+	}
+}
+
+// Deb returns the name used in Debian package file names, amd64 or arm64
+func (arch Architecture) Deb() string {
+	return arch.ToString()
+}
+
+// RPM returns the name used in RPM package file names and repository IDs, x86_64 or aarch64
+func (arch Architecture) RPM() string {
+	switch arch {
+	case ARM64:
+		return "aarch64"
+	default:
+		return "x86_64"
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchitecture(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		expected Architecture
+		deb      string
+		rpm      string
+	}{
+		"amd64":   {name: "amd64", expected: AMD64, deb: "amd64", rpm: "x86_64"},
+		"x86_64":  {name: "x86_64", expected: AMD64, deb: "amd64", rpm: "x86_64"},
+		"arm64":   {name: "arm64", expected: ARM64, deb: "arm64", rpm: "aarch64"},
+		"aarch64": {name: "AARCH64", expected: ARM64, deb: "arm64", rpm: "aarch64"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			arch, err := ParseArchitecture(tc.name)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, arch)
+			assert.Equal(t, tc.deb, arch.Deb())
+			assert.Equal(t, tc.rpm, arch.RPM())
+		})
+	}
+
+	_, err := ParseArchitecture("ppc64le")
+	assert.ErrorContains(t, err, "valid options are: amd64, arm64")
+}
+
+func TestDetectArchitecture(t *testing.T) {
+	assert.Equal(t, AMD64, detectArchitecture("amd64"))
+	assert.Equal(t, ARM64, detectArchitecture("arm64"))
+
+	machine, err := exec.Command("uname", "-m").Output()
+	require.NoError(t, err)
+	expected, err := ParseArchitecture(strings.TrimSpace(string(machine)))
+	if err != nil {
+		t.Skipf("wbi does not support the architecture %s", machine)
+	}
+	assert.Equal(t, expected, detectHostArchitecture())
+}
@@ -9,6 +9,7 @@ const (
 	Redhat7
 	Redhat8
 	Redhat9
+	Ubuntu24
 )
 
 func (os OperatingSystem) ToString() string {
@@ -17,6 +18,8 @@ func (os OperatingSystem) ToString() string {
 		return "Ubuntu 20"
 	case Ubuntu22:
 		return "Ubuntu 22"
+	case Ubuntu24:
+		return "Ubuntu 24"
 	case Redhat7:
 		return "RHEL 7"
 	case Redhat8:
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// osReleasePaths are read in order, /usr/lib/os-release is the fallback when /etc/os-release doesn't exist
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// rhelCompatibleIDs are the distributions built from the RHEL sources that use the RHEL installers
+var rhelCompatibleIDs = []string{"rhel", "centos", "rocky", "almalinux", "ol"}
+
+// Distribution is the Linux distribution described by /etc/os-release
+type Distribution struct {
+	// Family is debian for Ubuntu and Debian, rhel for RHEL and its rebuilds, or the ID otherwise
+	Family string
+	// ID is the distribution, such as ubuntu, rhel, rocky, almalinux or ol
+	ID string
+	// Version is the VERSION_ID, such as 22.04 or 9.3
+	Version string
+	// Codename is the VERSION_CODENAME, such as jammy, it is empty on RHEL 7
+	Codename string
+	// Name is the PRETTY_NAME, such as "Rocky Linux 9.3 (Blue Onyx)"
+	Name string
+}
+
+// DetectDistribution reads the distribution from /etc/os-release
+func DetectDistribution() (Distribution, error) {
+	for _, path := range osReleasePaths {
+		content, err := os.ReadFile(path)
+		if err == nil {
+			return ParseOSRelease(string(content)), nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return Distribution{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return Distribution{}, errors.New("unsupported operating system: /etc/os-release does not exist")
+}
+
+// ParseOSRelease parses the KEY=value lines of an os-release file
+func ParseOSRelease(content string) Distribution {
+	fields := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		fields[key] = strings.Trim(value, `"'`)
+	}
+
+	d := Distribution{
+		ID:       strings.ToLower(fields["ID"]),
+		Version:  fields["VERSION_ID"],
+		Codename: fields["VERSION_CODENAME"],
+		Name:     fields["PRETTY_NAME"],
+	}
+	if d.Name == "" {
+		d.Name = strings.TrimSpace(fields["NAME"] + " " + d.Version)
+	}
+
+	like := append([]string{d.ID}, strings.Fields(strings.ToLower(fields["ID_LIKE"]))...)
+	d.Family = d.ID
+	for _, id := range like {
+		if id == "debian" || id == "ubuntu" {
+			d.Family = "debian"
+			break
+		} else if lo.Contains(rhelCompatibleIDs, id) {
+			d.Family = "rhel"
+			break
+		}
+	}
+	return d
+}
+
+// MajorVersion returns the part of the version before the first dot, 9 for 9.3
+func (d Distribution) MajorVersion() string {
+	major, _, _ := strings.Cut(d.Version, ".")
+	return major
+}
+
+// Rebuild returns true for distributions built from the RHEL sources, such as Rocky Linux, AlmaLinux and
+// Oracle Linux. CentOS 7 is treated as RHEL 7, as it was supported before the rebuilds.
+func (d Distribution) Rebuild() bool {
+	return d.Family == "rhel" && d.ID != "rhel" && d.ID != "centos"
+}
+
+// OperatingSystem returns the installer target for the distribution, or Unknown if it isn't supported
+func (d Distribution) OperatingSystem() OperatingSystem {
+	switch {
+	case d.ID == "ubuntu":
+		switch d.Version {
+		case "20.04":
+			return Ubuntu20
+		case "22.04":
+			return Ubuntu22
+		case "24.04":
+			return Ubuntu24
+		}
+	case lo.Contains(rhelCompatibleIDs, d.ID):
+		switch d.MajorVersion() {
+		case "7":
+			return Redhat7
+		case "8":
+			return Redhat8
+		case "9":
+			return Redhat9
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		expected Distribution
+		osType   OperatingSystem
+		rebuild  bool
+	}{
+		"ubuntu 24.04": {
+			content:  "PRETTY_NAME=\"Ubuntu 24.04.1 LTS\"\nNAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nVERSION_CODENAME=noble\nID=ubuntu\nID_LIKE=debian\n",
+			expected: Distribution{Family: "debian", ID: "ubuntu", Version: "24.04", Codename: "noble", Name: "Ubuntu 24.04.1 LTS"},
+			osType:   Ubuntu24,
+		},
+		"ubuntu 20.04": {
+			content:  "NAME=\"Ubuntu\"\nVERSION_ID=\"20.04\"\nVERSION_CODENAME=focal\nID=ubuntu\nID_LIKE=debian\n",
+			expected: Distribution{Family: "debian", ID: "ubuntu", Version: "20.04", Codename: "focal", Name: "Ubuntu 20.04"},
+			osType:   Ubuntu20,
+		},
+		"rhel 8": {
+			content:  "NAME=\"Red Hat Enterprise Linux\"\nVERSION_ID=\"8.9\"\nID=\"rhel\"\nID_LIKE=\"fedora\"\nPRETTY_NAME=\"Red Hat Enterprise Linux 8.9 (Ootpa)\"\n",
+			expected: Distribution{Family: "rhel", ID: "rhel", Version: "8.9", Name: "Red Hat Enterprise Linux 8.9 (Ootpa)"},
+			osType:   Redhat8,
+		},
+		"centos 7": {
+			content:  "NAME=\"CentOS Linux\"\nID=\"centos\"\nID_LIKE=\"rhel fedora\"\nVERSION_ID=\"7\"\nPRETTY_NAME=\"CentOS Linux 7 (Core)\"\n",
+			expected: Distribution{Family: "rhel", ID: "centos", Version: "7", Name: "CentOS Linux 7 (Core)"},
+			osType:   Redhat7,
+		},
+		"rocky 9": {
+			content:  "NAME=\"Rocky Linux\"\nID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID=\"9.3\"\nPRETTY_NAME=\"Rocky Linux 9.3 (Blue Onyx)\"\n",
+			expected: Distribution{Family: "rhel", ID: "rocky", Version: "9.3", Name: "Rocky Linux 9.3 (Blue Onyx)"},
+			osType:   Redhat9,
+			rebuild:  true,
+		},
+		"almalinux 8": {
+			content:  "NAME=\"AlmaLinux\"\nID=\"almalinux\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID=\"8.9\"\nPRETTY_NAME=\"AlmaLinux 8.9 (Midnight Oncilla)\"\n",
+			expected: Distribution{Family: "rhel", ID: "almalinux", Version: "8.9", Name: "AlmaLinux 8.9 (Midnight Oncilla)"},
+			osType:   Redhat8,
+			rebuild:  true,
+		},
+		"oracle linux 9": {
+			content:  "NAME=\"Oracle Linux Server\"\nID=\"ol\"\nID_LIKE=\"fedora\"\nVERSION_ID=\"9.3\"\nPRETTY_NAME=\"Oracle Linux Server 9.3\"\n",
+			expected: Distribution{Family: "rhel", ID: "ol", Version: "9.3", Name: "Oracle Linux Server 9.3"},
+			osType:   Redhat9,
+			rebuild:  true,
+		},
+		"debian 12 is unsupported": {
+			content:  "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nID=debian\nVERSION_ID=\"12\"\nVERSION_CODENAME=bookworm\n",
+			expected: Distribution{Family: "debian", ID: "debian", Version: "12", Codename: "bookworm", Name: "Debian GNU/Linux 12 (bookworm)"},
+			osType:   Unknown,
+		},
+		"fedora is unsupported": {
+			content:  "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n",
+			expected: Distribution{Family: "fedora", ID: "fedora", Version: "39", Name: "Fedora Linux 39"},
+			osType:   Unknown,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			distro := ParseOSRelease(tc.content)
+			assert.Equal(t, tc.expected, distro)
+			assert.Equal(t, tc.osType, distro.OperatingSystem())
+			assert.Equal(t, tc.rebuild, distro.Rebuild())
+		})
+	}
+}
+
+func TestDetectDistribution(t *testing.T) {
+	dir := t.TempDir()
+	oldPaths := osReleasePaths
+	t.Cleanup(func() { osReleasePaths = oldPaths })
+
+	osReleasePaths = []string{filepath.Join(dir, "etc-os-release"), filepath.Join(dir, "usr-lib-os-release")}
+	_, err := DetectDistribution()
+	assert.ErrorContains(t, err, "unsupported operating system")
+
+	// /usr/lib/os-release is read when /etc/os-release doesn't exist
+	require.NoError(t, os.WriteFile(osReleasePaths[1], []byte("ID=ubuntu\nVERSION_ID=\"22.04\"\n"), 0644))
+	distro, err := DetectDistribution()
+	require.NoError(t, err)
+	assert.Equal(t, Ubuntu22, distro.OperatingSystem())
+}
@@ -7,12 +7,16 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/system"
 	"github.com/sol-eng/wbi/internal/workbench"
 )
 
 // Prompt users if they wish to add a default Connect URL to Workbench
 func PromptConnectChoice() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Connect.Enabled, "connect.enabled")
+	}
 	name := true
 	messageText := "Would you like to provide a default Connect URL for Workbench? You will need connectivity to the Connect server to use this option."
 	prompt := &survey.Confirm{
@@ -51,6 +55,8 @@ func PromptVerifyAndConfigConnect() error {
 
 		if goodURL {
 			break
+		} else if answers.Loaded() {
+			return answers.InvalidError("connect.url", "the Connect server could not be reached at "+rawConnectURL)
 		} else {
 			system.PrintAndLogInfo(`The URL you entered is not valid. Please try again. To skip this section type "skip".`)
 		}
@@ -67,6 +73,9 @@ func PromptVerifyAndConfigConnect() error {
 
 // Prompt users for a default Connect URL
 func PromptConnectURL() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Connect.URL, "connect.url")
+	}
 	target := ""
 	messageText := "Enter a default Connect URL:"
 	prompt := &survey.Input{
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"time"
 
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
@@ -23,9 +23,7 @@ func VerifyConnectURL(connectURL string) (string, error) {
 	cleanConnectURL := cleanConnectURL(connectURL)
 	fullTestURL := cleanConnectURL + "/__ping__"
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
 		http.MethodGet, fullTestURL, nil)
 	if err != nil {
@@ -0,0 +1,324 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/quarto"
+	"github.com/sol-eng/wbi/internal/ssl"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+var (
+	// rstudioConfDir holds rserver.conf, rsession.conf, repos.conf and jupyter.conf
+	rstudioConfDir = "/etc/rstudio"
+	pipConfPath    = "/etc/pip.conf"
+	// binDir is where R, Rscript and Quarto are symlinked
+	binDir = "/usr/local/bin"
+	// scanR, scanPython and scanQuarto find installations and run each one to check it works
+	scanR      = languages.ScanRInstallations
+	scanPython = languages.ScanPythonInstallations
+	scanQuarto = quarto.ScanQuartoInstallations
+	// certificateExpiryWarning is how long before it expires a certificate is reported
+	certificateExpiryWarning = 30 * 24 * time.Hour
+	// runCommand runs a read-only command and returns its output
+	runCommand = func(command string) (string, error) {
+		return system.RunCommandAndCaptureOutput(command, false, 0, false)
+	}
+)
+
+// Checks returns every health check in the order they are reported
+func Checks() []Check {
+	return []Check{
+		{Name: "Workbench installed", Run: checkWorkbenchInstalled},
+		{Name: "rstudio-server running", Run: func() Result { return checkServiceRunning("rstudio-server") }},
+		{Name: "rstudio-launcher running", Run: func() Result { return checkServiceRunning("rstudio-launcher") }},
+		{Name: "Workbench license", Run: checkLicense},
+		{Name: "R installed", Run: checkRInstalled},
+		{Name: "R symlinks", Run: func() Result { return checkSymlinks("R", "Rscript") }},
+		{Name: "Python installed", Run: checkPythonInstalled},
+		{Name: "Quarto installed", Run: checkQuartoInstalled},
+		{Name: "Quarto symlink", Run: func() Result { return checkSymlinks("quarto") }},
+		{Name: "Jupyter", Run: checkJupyter},
+		{Name: "CRAN repository", Run: checkCRANRepo},
+		{Name: "Python package repository", Run: checkPipRepo},
+		{Name: "Connect URL", Run: checkConnectURL},
+		{Name: "SSL certificate", Run: checkCertificate},
+	}
+}
+
+func workbenchVersion() (string, bool) {
+	version, err := runCommand("rstudio-server version")
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(version), true
+}
+
+func checkWorkbenchInstalled() Result {
+	version, installed := workbenchVersion()
+	if !installed {
+		return failed("Workbench is not installed", "wbi install workbench")
+	}
+	return passed("version " + version)
+}
+
+func checkServiceRunning(service string) Result {
+	if _, installed := workbenchVersion(); !installed {
+		return failed("skipped, Workbench is not installed", "wbi install workbench")
+	}
+	status, err := runCommand(service + " status | cat")
+	if err != nil {
+		return failed(fmt.Sprintf("could not check the status of %s: %s", service, err), "sudo systemctl status "+service)
+	}
+	if !strings.Contains(status, "active (running)") {
+		return failed(service+" is not running", "sudo systemctl start "+service+", then check the logs with journalctl -u "+service)
+	}
+	return passed(service + " is active (running)")
+}
+
+func checkLicense() Result {
+	if _, installed := workbenchVersion(); !installed {
+		return failed("skipped, Workbench is not installed", "wbi install workbench")
+	}
+	status, err := runCommand("rstudio-server license-manager status")
+	if err != nil {
+		return failed(fmt.Sprintf("could not check the license status: %s", err), "sudo rstudio-server license-manager status")
+	}
+	if strings.Contains(status, "Status: Activated") {
+		return passed("an active license was detected")
+	}
+	message := "no active license was detected"
+	for _, line := range strings.Split(status, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "Status:") {
+			message += " (" + strings.TrimSpace(line) + ")"
+			break
+		}
+	}
+	return failed(message, "wbi activate license --key [LICENSE-KEY]")
+}
+
+func checkRInstalled() Result {
+	installations, err := scanR()
+	if err != nil {
+		return failed(fmt.Sprintf("could not scan for R versions: %s", err), "wbi scan r")
+	}
+	if len(installations) == 0 {
+		return failed("no versions of R were found", "wbi install r --version [VERSION]")
+	}
+	return checkInstallations(installations, "wbi scan r")
+}
+
+func checkPythonInstalled() Result {
+	installations, err := scanPython()
+	if err != nil {
+		return failed(fmt.Sprintf("could not scan for Python versions: %s", err), "wbi scan python")
+	}
+	if len(installations) == 0 {
+		return warned("no versions of Python were found", "wbi install python --version [VERSION]")
+	}
+	return checkInstallations(installations, "wbi scan python")
+}
+
+func checkQuartoInstalled() Result {
+	installations, err := scanQuarto()
+	if err != nil {
+		return failed(fmt.Sprintf("could not scan for Quarto versions: %s", err), "wbi scan quarto")
+	}
+	if len(installations) == 0 {
+		return warned("no versions of Quarto were found", "wbi install quarto --version [VERSION]")
+	}
+	return checkInstallations(installations, "wbi scan quarto")
+}
+
+// checkInstallations lists the version of each installation and warns about any that do not run
+func checkInstallations(installations []languages.Installation, scanCommand string) Result {
+	var found []string
+	var broken []string
+	for _, installation := range installations {
+		if installation.Broken {
+			broken = append(broken, installation.Path)
+		} else {
+			found = append(found, installation.Path+" ("+installation.Version+")")
+		}
+	}
+	if len(broken) > 0 {
+		return warned(strings.Join(broken, ", ")+" did not run", "run "+scanCommand+" for the errors, then reinstall or remove the broken versions")
+	}
+	return passed(strings.Join(found, ", "))
+}
+
+// checkSymlinks checks that each program is linked into binDir and that the link resolves
+func checkSymlinks(programs ...string) Result {
+	var targets []string
+	for _, program := range programs {
+		link := filepath.Join(binDir, program)
+		if _, err := os.Lstat(link); err != nil {
+			return warned(link+" does not exist, so "+program+" is not on the default PATH", "sudo ln -s [PATH-TO-"+strings.ToUpper(program)+"] "+link)
+		}
+		target, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			return failed(link+" points to a file that does not exist", "sudo ln -sf [PATH-TO-"+strings.ToUpper(program)+"] "+link)
+		}
+		targets = append(targets, link+" -> "+target)
+	}
+	return passed(strings.Join(targets, ", "))
+}
+
+func checkJupyter() Result {
+	jupyterConf, err := conffile.Load(filepath.Join(rstudioConfDir, "jupyter.conf"))
+	if err != nil {
+		return failed(err.Error(), "check the permissions of "+filepath.Join(rstudioConfDir, "jupyter.conf"))
+	}
+	jupyterPath, _ := jupyterConf.Get("jupyter-exe")
+	if jupyterPath == "" {
+		return warned("jupyter.conf does not set jupyter-exe, so Jupyter sessions are unavailable", "wbi install jupyter")
+	}
+	version, err := runCommand(jupyterPath + " --version")
+	if err != nil {
+		return failed("jupyter-exe "+jupyterPath+" does not run", "wbi install jupyter, or set jupyter-exe in "+jupyterConf.Path()+" to a working Jupyter")
+	}
+	return passed(jupyterPath + " runs (" + firstLine(version) + ")")
+}
+
+func checkCRANRepo() Result {
+	reposConf, err := conffile.Load(filepath.Join(rstudioConfDir, "repos.conf"))
+	if err != nil {
+		return failed(err.Error(), "check the permissions of "+filepath.Join(rstudioConfDir, "repos.conf"))
+	}
+	cranURL, _ := reposConf.Get("CRAN")
+	if cranURL == "" {
+		return warned("repos.conf does not set a CRAN repository, so sessions use the R default", "wbi config repo --url [URL] --source cran")
+	}
+	err = reachable(strings.TrimSuffix(cranURL, "/") + "/src/contrib/PACKAGES")
+	if err != nil {
+		return failed(cranURL+" is not reachable: "+err.Error(), "check the URL in "+reposConf.Path()+" and this server's network access to it")
+	}
+	return passed(cranURL + " is reachable")
+}
+
+func checkPipRepo() Result {
+	pipConf, err := conffile.Load(pipConfPath)
+	if err != nil {
+		return failed(err.Error(), "check the permissions of "+pipConfPath)
+	}
+	indexURL, _ := pipConf.Section("global").Get("index-url")
+	if indexURL == "" {
+		return warned("pip.conf does not set an index-url, so pip uses PyPI", "wbi config repo --url [URL] --source pypi")
+	}
+	err = reachable(indexURL)
+	if err != nil {
+		return failed(indexURL+" is not reachable: "+err.Error(), "check the index-url in "+pipConfPath+" and this server's network access to it")
+	}
+	return passed(indexURL + " is reachable")
+}
+
+func checkConnectURL() Result {
+	rsessionConf, err := conffile.Load(filepath.Join(rstudioConfDir, "rsession.conf"))
+	if err != nil {
+		return failed(err.Error(), "check the permissions of "+filepath.Join(rstudioConfDir, "rsession.conf"))
+	}
+	connectURL, _ := rsessionConf.Get("default-rsconnect-server")
+	if connectURL == "" {
+		return warned("rsession.conf does not set a default Connect server", "wbi config connect-url --url [URL]")
+	}
+	err = reachable(strings.TrimSuffix(connectURL, "/") + "/__ping__")
+	if err != nil {
+		return failed(connectURL+" is not reachable: "+err.Error(), "check the URL in "+rsessionConf.Path()+" and this server's network access to it")
+	}
+	return passed(connectURL + " is reachable")
+}
+
+func checkCertificate() Result {
+	rserverConf, err := conffile.Load(filepath.Join(rstudioConfDir, "rserver.conf"))
+	if err != nil {
+		return failed(err.Error(), "check the permissions of "+filepath.Join(rstudioConfDir, "rserver.conf"))
+	}
+	configureSSL := "wbi config ssl --cert-path [CERT-PATH] --key-path [KEY-PATH] --url [URL]"
+	if enabled, _ := rserverConf.Get("ssl-enabled"); enabled != "1" {
+		return warned("SSL is not enabled, so Workbench is served over HTTP", configureSSL)
+	}
+	certPath, _ := rserverConf.Get("ssl-certificate")
+	keyPath, _ := rserverConf.Get("ssl-certificate-key")
+
+	certData, err := os.ReadFile(certPath)
+	if err != nil {
+		return failed("the certificate could not be read: "+err.Error(), configureSSL)
+	}
+	chain := ssl.DecodePemFiles(certData)
+	intermediates := x509.NewCertPool()
+	var serverCert *x509.Certificate
+	for _, der := range chain.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return failed(certPath+" contains an invalid certificate: "+err.Error(), configureSSL)
+		}
+		if cert.IsCA {
+			intermediates.AddCert(cert)
+		} else if serverCert == nil {
+			serverCert = cert
+		}
+	}
+	if serverCert == nil {
+		return failed(certPath+" does not contain a server certificate", configureSSL)
+	}
+
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return failed("the certificate and key do not match: "+err.Error(), configureSSL)
+	}
+
+	now := time.Now()
+	expires := serverCert.NotAfter.Format("2006-01-02")
+	if now.After(serverCert.NotAfter) {
+		return failed("the certificate expired on "+expires, "renew the certificate, then "+configureSSL)
+	}
+	if now.Before(serverCert.NotBefore) {
+		return failed("the certificate is not valid until "+serverCert.NotBefore.Format("2006-01-02"), "check the server's clock, or "+configureSSL)
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	_, err = serverCert.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	if err != nil {
+		return warned("the certificate is not trusted by this server: "+err.Error(), "add the root certificate to the system trust store, see wbi verify ssl --cert-path "+certPath+" --key-path "+keyPath)
+	}
+	if serverCert.NotAfter.Sub(now) < certificateExpiryWarning {
+		return warned("the certificate expires on "+expires, "renew the certificate, then "+configureSSL)
+	}
+	return passed("the certificate is valid until " + expires)
+}
+
+// reachable checks that a URL responds without an error status
+func reachable(url string) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	res, err := httpclient.New().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		return errors.New("HTTP status " + res.Status)
+	}
+	return nil
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
@@ -0,0 +1,93 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Status is the outcome of a check, ordered from best to worst
+type Status int
+
+const (
+	Pass Status = iota
+	Warn
+	Fail
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "pass"
+	case Warn:
+		return "warn"
+	default:
+		return "fail"
+	}
+}
+
+// MarshalText reports the status by name in JSON and YAML output
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Result is the outcome of a single check along with how to fix it
+type Result struct {
+	Name        string `json:"name" yaml:"name"`
+	Status      Status `json:"status" yaml:"status"`
+	Message     string `json:"message" yaml:"message"`
+	Remediation string `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+}
+
+// Check is a single health check
+type Check struct {
+	Name string
+	Run  func() Result
+}
+
+// Run runs every check in order, naming each result after its check
+func Run(checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		result := check.Run()
+		result.Name = check.Name
+		results = append(results, result)
+	}
+	return results
+}
+
+// Worst returns the worst status of the results, Pass when there are none
+func Worst(results []Result) Status {
+	worst := Pass
+	for _, result := range results {
+		if result.Status > worst {
+			worst = result.Status
+		}
+	}
+	return worst
+}
+
+// WriteReport writes the results as a human readable report followed by a summary
+func WriteReport(w io.Writer, results []Result) {
+	counts := map[Status]int{}
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Fprintf(w, "[%s] %s: %s\n", strings.ToUpper(result.Status.String()), result.Name, result.Message)
+		if result.Remediation != "" && result.Status != Pass {
+			fmt.Fprintf(w, "       fix: %s\n", result.Remediation)
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n", counts[Pass], counts[Warn], counts[Fail])
+}
+
+func passed(message string) Result {
+	return Result{Status: Pass, Message: message}
+}
+
+func warned(message string, remediation string) Result {
+	return Result{Status: Warn, Message: message, Remediation: remediation}
+}
+
+func failed(message string, remediation string) Result {
+	return Result{Status: Fail, Message: message, Remediation: remediation}
+}
@@ -0,0 +1,225 @@
+package doctor
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useConfDir points the checks at a temporary configuration directory
+func useConfDir(t *testing.T) string {
+	dir := t.TempDir()
+	oldConfDir, oldPipConf, oldBinDir := rstudioConfDir, pipConfPath, binDir
+	rstudioConfDir, pipConfPath, binDir = dir, filepath.Join(dir, "pip.conf"), dir
+	t.Cleanup(func() { rstudioConfDir, pipConfPath, binDir = oldConfDir, oldPipConf, oldBinDir })
+	return dir
+}
+
+// stubCommands replaces command execution with canned output, commands without output fail
+func stubCommands(t *testing.T, outputs map[string]string) {
+	old := runCommand
+	runCommand = func(command string) (string, error) {
+		if output, ok := outputs[command]; ok {
+			return output, nil
+		}
+		return "", errors.New("command not found")
+	}
+	t.Cleanup(func() { runCommand = old })
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestRunAndReport(t *testing.T) {
+	results := Run([]Check{
+		{Name: "first", Run: func() Result { return passed("ok") }},
+		{Name: "second", Run: func() Result { return warned("not configured", "configure it") }},
+	})
+	assert.Equal(t, "first", results[0].Name)
+	assert.Equal(t, Warn, Worst(results))
+	assert.Equal(t, Pass, Worst(nil))
+	assert.Equal(t, Fail, Worst(append(results, failed("broken", "fix it"))))
+
+	var out bytes.Buffer
+	WriteReport(&out, results)
+	assert.Contains(t, out.String(), "[PASS] first: ok\n")
+	assert.Contains(t, out.String(), "[WARN] second: not configured\n       fix: configure it\n")
+	assert.Contains(t, out.String(), "1 passed, 1 warnings, 0 failed")
+}
+
+func TestWorkbenchChecks(t *testing.T) {
+	stubCommands(t, map[string]string{})
+	assert.Equal(t, Fail, checkWorkbenchInstalled().Status)
+	assert.Contains(t, checkServiceRunning("rstudio-server").Message, "Workbench is not installed")
+
+	stubCommands(t, map[string]string{
+		"rstudio-server version":                "2023.03.0+386.pro1 Workbench\n",
+		"rstudio-server status | cat":           "Active: active (running) since Mon",
+		"rstudio-launcher status | cat":         "Active: inactive (dead)",
+		"rstudio-server license-manager status": "Status: Expired\n",
+	})
+	assert.Equal(t, passed("version 2023.03.0+386.pro1 Workbench"), checkWorkbenchInstalled())
+	assert.Equal(t, Pass, checkServiceRunning("rstudio-server").Status)
+	launcher := checkServiceRunning("rstudio-launcher")
+	assert.Equal(t, Fail, launcher.Status)
+	assert.Contains(t, launcher.Remediation, "systemctl start rstudio-launcher")
+	license := checkLicense()
+	assert.Equal(t, Fail, license.Status)
+	assert.Contains(t, license.Message, "Status: Expired")
+	assert.Contains(t, license.Remediation, "wbi activate license")
+}
+
+func TestInstallationChecks(t *testing.T) {
+	oldScanR, oldScanPython, oldScanQuarto := scanR, scanPython, scanQuarto
+	t.Cleanup(func() { scanR, scanPython, scanQuarto = oldScanR, oldScanPython, oldScanQuarto })
+
+	scanR = func() ([]languages.Installation, error) { return []languages.Installation{}, nil }
+	scanPython = func() ([]languages.Installation, error) { return []languages.Installation{}, nil }
+	scanQuarto = func() ([]languages.Installation, error) { return nil, errors.New("permission denied") }
+	assert.Equal(t, Fail, checkRInstalled().Status)
+	// Python is optional, so R-only servers only get a warning
+	assert.Equal(t, Warn, checkPythonInstalled().Status)
+	assert.Contains(t, checkQuartoInstalled().Message, "could not scan for Quarto versions")
+
+	scanR = func() ([]languages.Installation, error) {
+		return []languages.Installation{{Path: "/opt/R/4.3.2/bin/R", Version: "4.3.2"}}, nil
+	}
+	assert.Equal(t, passed("/opt/R/4.3.2/bin/R (4.3.2)"), checkRInstalled())
+
+	scanR = func() ([]languages.Installation, error) {
+		return []languages.Installation{
+			{Path: "/opt/R/4.3.2/bin/R", Version: "4.3.2"},
+			{Path: "/opt/R/devel/bin/R", Broken: true, Error: "exit status 127"},
+		}, nil
+	}
+	result := checkRInstalled()
+	assert.Equal(t, Warn, result.Status)
+	assert.Equal(t, "/opt/R/devel/bin/R did not run", result.Message)
+}
+
+func TestSymlinkChecks(t *testing.T) {
+	dir := useConfDir(t)
+	assert.Equal(t, Warn, checkSymlinks("R", "Rscript").Status)
+
+	target := filepath.Join(dir, "R-4.2.3")
+	writeFile(t, target, "")
+	require.NoError(t, os.Symlink(target, filepath.Join(dir, "R")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "Rscript")))
+	result := checkSymlinks("R", "Rscript")
+	assert.Equal(t, Fail, result.Status)
+	assert.Contains(t, result.Message, "points to a file that does not exist")
+
+	assert.Equal(t, Pass, checkSymlinks("R").Status)
+}
+
+func TestJupyterCheck(t *testing.T) {
+	dir := useConfDir(t)
+	stubCommands(t, map[string]string{"/opt/python/jupyter/bin/jupyter --version": "Selected Jupyter core packages...\n"})
+	assert.Equal(t, Warn, checkJupyter().Status)
+
+	writeFile(t, filepath.Join(dir, "jupyter.conf"), "jupyter-exe=/opt/python/jupyter/bin/jupyter\n")
+	assert.Equal(t, Pass, checkJupyter().Status)
+
+	writeFile(t, filepath.Join(dir, "jupyter.conf"), "jupyter-exe=/usr/bin/missing-jupyter\n")
+	assert.Equal(t, Fail, checkJupyter().Status)
+}
+
+func TestRepositoryAndConnectChecks(t *testing.T) {
+	dir := useConfDir(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cran/latest/src/contrib/PACKAGES", "/pypi/latest/simple", "/connect/__ping__":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.Equal(t, Warn, checkCRANRepo().Status)
+	assert.Equal(t, Warn, checkPipRepo().Status)
+	assert.Equal(t, Warn, checkConnectURL().Status)
+
+	writeFile(t, filepath.Join(dir, "repos.conf"), "CRAN="+server.URL+"/cran/latest\n")
+	writeFile(t, filepath.Join(dir, "pip.conf"), "[global]\nindex-url = "+server.URL+"/pypi/latest/simple\n")
+	writeFile(t, filepath.Join(dir, "rsession.conf"), "default-rsconnect-server="+server.URL+"/connect/\n")
+	assert.Equal(t, Pass, checkCRANRepo().Status)
+	assert.Equal(t, Pass, checkPipRepo().Status)
+	assert.Equal(t, Pass, checkConnectURL().Status)
+
+	writeFile(t, filepath.Join(dir, "repos.conf"), "CRAN="+server.URL+"/missing\n")
+	result := checkCRANRepo()
+	assert.Equal(t, Fail, result.Status)
+	assert.Contains(t, result.Message, "404")
+}
+
+// writeCertificate writes a self-signed server certificate and its key valid between two times
+func writeCertificate(t *testing.T, dir string, notBefore time.Time, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "workbench.example.com"},
+		DNSNames:     []string{"workbench.example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "workbench.crt")
+	keyPath := filepath.Join(dir, "workbench.key")
+	writeFile(t, certPath, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	writeFile(t, keyPath, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return certPath, keyPath
+}
+
+func TestCertificateCheck(t *testing.T) {
+	dir := useConfDir(t)
+	assert.Equal(t, Warn, checkCertificate().Status)
+
+	now := time.Now()
+	certPath, keyPath := writeCertificate(t, dir, now.Add(-time.Hour), now.Add(-time.Minute))
+	writeFile(t, filepath.Join(dir, "rserver.conf"), strings.Join([]string{
+		"ssl-enabled=1",
+		"ssl-certificate=" + certPath,
+		"ssl-certificate-key=" + keyPath,
+	}, "\n")+"\n")
+	result := checkCertificate()
+	assert.Equal(t, Fail, result.Status)
+	assert.Contains(t, result.Message, "the certificate expired on")
+
+	// a valid self-signed certificate isn't trusted by the system trust store
+	writeCertificate(t, dir, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	result = checkCertificate()
+	assert.Equal(t, Warn, result.Status)
+	assert.Contains(t, result.Message, "not trusted")
+
+	// a key from another certificate doesn't match
+	otherDir := t.TempDir()
+	_, otherKey := writeCertificate(t, otherDir, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, os.Rename(otherKey, keyPath))
+	result = checkCertificate()
+	assert.Equal(t, Fail, result.Status)
+	assert.Contains(t, result.Message, "do not match")
+}
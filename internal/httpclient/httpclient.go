@@ -0,0 +1,165 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// RequestTimeout limits the total time of a request for metadata, such as version lists and API calls
+	RequestTimeout = 30 * time.Second
+	dialTimeout    = 30 * time.Second
+	tlsTimeout     = 15 * time.Second
+	headerTimeout  = 30 * time.Second
+)
+
+// Options configures the proxy, trusted certificates and User-Agent of every HTTP request wbi makes
+type Options struct {
+	// Proxy is used for HTTP and HTTPS requests instead of the HTTP_PROXY and HTTPS_PROXY environment variables
+	Proxy string
+	// CABundle is a PEM file of certificates trusted in addition to the system trust store
+	CABundle string
+	// Version of wbi reported in the User-Agent
+	Version string
+}
+
+var (
+	userAgent = "wbi"
+	base      = newBaseTransport(http.ProxyFromEnvironment, nil)
+	layers    []func(http.RoundTripper) http.RoundTripper
+)
+
+// Configure sets the proxy, trusted certificates and User-Agent used by every client created afterwards.
+// Commands run by wbi, such as pip, inherit the proxy and certificates through environment variables.
+func Configure(opts Options) error {
+	if opts.Version != "" {
+		userAgent = "wbi/" + opts.Version
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return fmt.Errorf("invalid proxy URL %s", opts.Proxy)
+		}
+		config := httpproxy.FromEnvironment()
+		config.HTTPProxy = opts.Proxy
+		config.HTTPSProxy = opts.Proxy
+		proxyFunc := config.ProxyFunc()
+		proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+		for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+			os.Setenv(name, opts.Proxy)
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if opts.CABundle != "" {
+		pool, err := loadCABundle(opts.CABundle)
+		if err != nil {
+			return err
+		}
+		tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		// pip and requests don't use the system trust store
+		os.Setenv("PIP_CERT", opts.CABundle)
+		os.Setenv("REQUESTS_CA_BUNDLE", opts.CABundle)
+	}
+
+	base = newBaseTransport(proxy, tlsConfig)
+	return nil
+}
+
+// AddLayer wraps every transport created afterwards, for example to serve requests from an offline bundle
+func AddLayer(layer func(http.RoundTripper) http.RoundTripper) {
+	layers = append(layers, layer)
+}
+
+// New returns a client for metadata requests that are limited to RequestTimeout
+func New() *http.Client {
+	return &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: Transport(),
+	}
+}
+
+// NewWithTLS returns a client like New that uses its own TLS settings, for APIs such as Kubernetes that are signed
+// by a private certificate authority. Requests use the configured proxy but are never served from an offline bundle.
+func NewWithTLS(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: &userAgentTransport{next: newBaseTransport(base.Proxy, tlsConfig)},
+	}
+}
+
+// NewDownload returns a client without a total timeout for large downloads, which are expected to
+// enforce their own idle timeout
+func NewDownload() *http.Client {
+	return &http.Client{
+		Transport: Transport(),
+	}
+}
+
+// Transport returns the configured transport with any layers applied
+func Transport() http.RoundTripper {
+	var rt http.RoundTripper = base
+	for _, layer := range layers {
+		rt = layer(rt)
+	}
+	return &userAgentTransport{next: rt}
+}
+
+func newBaseTransport(proxy func(*http.Request) (*url.URL, error), tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   tlsTimeout,
+		ResponseHeaderTimeout: headerTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// loadCABundle adds the certificates in a PEM file to the system trust store
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("the CA bundle " + path + " does not contain any PEM encoded certificates")
+	}
+	return pool, nil
+}
+
+// userAgentTransport sets the wbi User-Agent on requests that don't set their own
+type userAgentTransport struct {
+	next http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", userAgent)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreEnvironment restores the variables Configure sets for child processes after the test
+func restoreEnvironment(t *testing.T) {
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy", "NO_PROXY", "no_proxy", "PIP_CERT", "REQUESTS_CA_BUNDLE"} {
+		t.Setenv(name, os.Getenv(name))
+	}
+	t.Cleanup(func() { require.NoError(t, Configure(Options{})) })
+}
+
+func TestConfigureProxy(t *testing.T) {
+	restoreEnvironment(t)
+	proxied := ""
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		io.WriteString(w, r.Header.Get("User-Agent"))
+	}))
+	defer proxy.Close()
+
+	require.NoError(t, Configure(Options{Proxy: proxy.URL, Version: "1.2.3"}))
+	res, err := New().Get("http://cdn.posit.co/r/versions.json")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://cdn.posit.co/r/versions.json", proxied)
+	assert.Equal(t, "wbi/1.2.3", string(body))
+	assert.Equal(t, proxy.URL, os.Getenv("HTTPS_PROXY"))
+
+	assert.Error(t, Configure(Options{Proxy: "not a url"}))
+}
+
+func TestConfigureCABundle(t *testing.T) {
+	restoreEnvironment(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// the test server's certificate isn't trusted by default
+	require.NoError(t, Configure(Options{}))
+	_, err := New().Get(server.URL)
+	assert.Error(t, err)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundle, certificate, 0644))
+	require.NoError(t, Configure(Options{CABundle: caBundle}))
+	res, err := New().Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, caBundle, os.Getenv("PIP_CERT"))
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0644))
+	assert.ErrorContains(t, Configure(Options{CABundle: empty}), "does not contain any PEM encoded certificates")
+}
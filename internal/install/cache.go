@@ -0,0 +1,301 @@
+package install
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// DefaultCacheDir is where downloaded installers are cached unless another directory is configured
+const DefaultCacheDir = "/var/cache/wbi"
+
+const (
+	cacheIndexName  = "index.json"
+	cachePartialDir = "partial"
+)
+
+// partialDownloadAge is how long a download must go without being written to before prune treats it as interrupted,
+// so downloads still in progress in another wbi are left alone
+const partialDownloadAge = time.Hour
+
+// cacheDir is the download cache directory, downloads are not cached when it's empty
+var cacheDir = DefaultCacheDir
+
+// CacheEntry is a downloaded file stored in the cache at <cache dir>/<sha256>/<filename>
+type CacheEntry struct {
+	URL      string    `json:"url"`
+	SHA256   string    `json:"sha256"`
+	Filename string    `json:"filename"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Path returns the location of the cached file
+func (e CacheEntry) Path() string {
+	return filepath.Join(cacheDir, e.SHA256, e.Filename)
+}
+
+// SetCacheDir sets the download cache directory, an empty string disables the cache
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// CacheDir returns the download cache directory
+func CacheDir() string {
+	return cacheDir
+}
+
+// cacheEnabled returns true when the cache directory is configured and can be written to,
+// for example it can't be when wbi isn't run as root
+func cacheEnabled() bool {
+	if cacheDir == "" {
+		return false
+	}
+	err := os.MkdirAll(filepath.Join(cacheDir, cachePartialDir), 0755)
+	if err != nil {
+		log.Info(fmt.Sprintf("The download cache %s is not available, downloads will not be cached: %s", cacheDir, err))
+		return false
+	}
+	return true
+}
+
+// ListCache returns the entries in the download cache, most recently used first
+func ListCache() ([]CacheEntry, error) {
+	entries, err := loadCacheIndex()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.After(entries[j].LastUsed) })
+	return entries, nil
+}
+
+// PruneCache removes cache entries that haven't been used within the maximum age, along with
+// interrupted downloads and files that are no longer in the index
+func PruneCache(maxAge time.Duration) ([]CacheEntry, error) {
+	entries, err := loadCacheIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, removed []CacheEntry
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if entry.LastUsed.Before(cutoff) {
+			removed = append(removed, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+
+	keptDirs := map[string]bool{cacheIndexName: true}
+	for _, entry := range kept {
+		keptDirs[entry.SHA256] = true
+	}
+	dirEntries, err := os.ReadDir(cacheDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", cacheDir, err)
+	}
+	for _, dirEntry := range dirEntries {
+		if keptDirs[dirEntry.Name()] {
+			continue
+		}
+		if dirEntry.Name() == cachePartialDir && dirEntry.IsDir() {
+			err = prunePartialDownloads()
+		} else {
+			err = removeCachePath(filepath.Join(cacheDir, dirEntry.Name()))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(removed) > 0 && !system.DryRun() {
+		err = saveCacheIndex(kept)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// prunePartialDownloads removes interrupted downloads, keeping those written to recently since they may still be
+// downloading
+func prunePartialDownloads() error {
+	partialDir := filepath.Join(cacheDir, cachePartialDir)
+	dirEntries, err := os.ReadDir(partialDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", partialDir, err)
+	}
+	cutoff := time.Now().Add(-partialDownloadAge)
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			// the download finished and was moved into the cache
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Join(partialDir, dirEntry.Name()), err)
+		}
+		if info.ModTime().After(cutoff) {
+			continue
+		}
+		err = removeCachePath(filepath.Join(partialDir, dirEntry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearCache removes every file in the download cache
+func ClearCache() error {
+	dirEntries, err := os.ReadDir(cacheDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", cacheDir, err)
+	}
+	for _, dirEntry := range dirEntries {
+		err = removeCachePath(filepath.Join(cacheDir, dirEntry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveDownload removes a downloaded installer once it has been installed, files in the cache are kept
+func RemoveDownload(path string) {
+	if system.DryRun() || path == "" {
+		return
+	}
+	if cacheDir != "" && strings.HasPrefix(path, filepath.Clean(cacheDir)+string(os.PathSeparator)) {
+		return
+	}
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Info(fmt.Sprintf("Failed to remove the downloaded file %s: %s", path, err))
+	}
+}
+
+// lookupCache returns the cached file for an expected digest, or for a URL when the digest isn't known.
+// The cached file is checked against its digest before it is used.
+func lookupCache(url string, sha256 string) (string, bool) {
+	if cacheDir == "" {
+		return "", false
+	}
+	entries, err := loadCacheIndex()
+	if err != nil {
+		log.Info(fmt.Sprintf("Failed to read the download cache index: %s", err))
+		return "", false
+	}
+	for i, entry := range entries {
+		if (sha256 != "" && !strings.EqualFold(entry.SHA256, sha256)) || (sha256 == "" && entry.URL != url) {
+			continue
+		}
+		if _, err := VerifySHA256(entry.Path(), entry.SHA256); err != nil {
+			log.Info(fmt.Sprintf("Ignoring the cached file %s: %s", entry.Path(), err))
+			continue
+		}
+		entries[i].LastUsed = time.Now()
+		err = saveCacheIndex(entries)
+		if err != nil {
+			log.Info(fmt.Sprintf("Failed to update the download cache index: %s", err))
+		}
+		return entry.Path(), true
+	}
+	return "", false
+}
+
+// storeInCache moves a completed download into the cache and records it in the index
+func storeInCache(downloadPath string, url string, filename string, sum string) (string, error) {
+	entry := CacheEntry{URL: url, SHA256: sum, Filename: filename, LastUsed: time.Now()}
+	err := os.MkdirAll(filepath.Dir(entry.Path()), 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	err = os.Rename(downloadPath, entry.Path())
+	if err != nil {
+		return "", fmt.Errorf("failed to move %s into the cache: %w", downloadPath, err)
+	}
+	info, err := os.Stat(entry.Path())
+	if err != nil {
+		return "", err
+	}
+	entry.Size = info.Size()
+
+	entries, err := loadCacheIndex()
+	if err != nil {
+		return "", err
+	}
+	// a URL is only listed once, the previous download is pruned if nothing else references it
+	entries = append(filterCacheEntries(entries, func(e CacheEntry) bool { return e.URL != url }), entry)
+	err = saveCacheIndex(entries)
+	if err != nil {
+		return "", err
+	}
+	return entry.Path(), nil
+}
+
+func filterCacheEntries(entries []CacheEntry, keep func(CacheEntry) bool) []CacheEntry {
+	var filtered []CacheEntry
+	for _, entry := range entries {
+		if keep(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func loadCacheIndex() ([]CacheEntry, error) {
+	content, err := os.ReadFile(filepath.Join(cacheDir, cacheIndexName))
+	if errors.Is(err, os.ErrNotExist) {
+		return []CacheEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the download cache index: %w", err)
+	}
+	var entries []CacheEntry
+	err = json.Unmarshal(content, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the download cache index: %w", err)
+	}
+	return entries, nil
+}
+
+// saveCacheIndex writes the index to a temporary file first so an interrupted write doesn't corrupt it
+func saveCacheIndex(entries []CacheEntry) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URL < entries[j].URL })
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to create the download cache index: %w", err)
+	}
+	indexPath := filepath.Join(cacheDir, cacheIndexName)
+	err = os.WriteFile(indexPath+".tmp", content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write the download cache index: %w", err)
+	}
+	return os.Rename(indexPath+".tmp", indexPath)
+}
+
+// removeCachePath removes a file or directory from the cache, in dry-run mode the removal is recorded instead
+func removeCachePath(path string) error {
+	if system.DryRun() {
+		system.RecordCommand("rm -rf " + path)
+		return nil
+	}
+	err := os.RemoveAll(path)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
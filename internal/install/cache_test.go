@@ -0,0 +1,95 @@
+package install
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadFileCache(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	defer SetCacheDir(DefaultCacheDir)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, "installer")
+	}))
+	defer server.Close()
+	// sha256sum of "installer"
+	digest := "9c0d294c05fc1d88d698034609bb81c0c69196327594e4c69d2915c80fd9850c"
+
+	// the first download is stored in the cache under its digest
+	first, err := DownloadFile("R", server.URL+"/R-4.3.2.deb", "R-4.3.2.deb", "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(CacheDir(), digest, "R-4.3.2.deb"), first)
+
+	// later downloads of the same URL or digest are served from the cache
+	second, err := DownloadFile("R", server.URL+"/R-4.3.2.deb", "R-4.3.2.deb", "")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	third, err := DownloadFile("R", server.URL+"/mirror/R-4.3.2.deb", "R-4.3.2.deb", digest)
+	require.NoError(t, err)
+	assert.Equal(t, first, third)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// cached installers are kept after they are installed
+	RemoveDownload(first)
+	assert.FileExists(t, first)
+
+	// a corrupted cache entry is downloaded again
+	require.NoError(t, os.WriteFile(first, []byte("corrupt"), 0644))
+	_, err = DownloadFile("R", server.URL+"/R-4.3.2.deb", "R-4.3.2.deb", "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	entries, err := ListCache()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(len("installer")), entries[0].Size)
+
+	// entries used within the maximum age are kept, interrupted downloads are removed while downloads that may still
+	// be in progress are kept
+	interrupted := filepath.Join(CacheDir(), cachePartialDir, "123_Workbench.deb")
+	require.NoError(t, os.WriteFile(interrupted, []byte("partial"), 0644))
+	stale := time.Now().Add(-2 * partialDownloadAge)
+	require.NoError(t, os.Chtimes(interrupted, stale, stale))
+	inProgress := filepath.Join(CacheDir(), cachePartialDir, "456_R-4.3.2.deb")
+	require.NoError(t, os.WriteFile(inProgress, []byte("partial"), 0644))
+	removed, err := PruneCache(time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+	assert.FileExists(t, first)
+	assert.NoFileExists(t, interrupted)
+	assert.FileExists(t, inProgress)
+
+	removed, err = PruneCache(time.Nanosecond)
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.NoFileExists(t, first)
+
+	_, err = DownloadFile("R", server.URL+"/R-4.3.2.deb", "R-4.3.2.deb", "")
+	require.NoError(t, err)
+	require.NoError(t, ClearCache())
+	entries, err = ListCache()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRemoveDownload(t *testing.T) {
+	SetCacheDir("")
+	defer SetCacheDir(DefaultCacheDir)
+
+	path := filepath.Join(t.TempDir(), "installer.deb")
+	require.NoError(t, os.WriteFile(path, []byte("installer"), 0644))
+	RemoveDownload(path)
+	assert.NoFileExists(t, path)
+}
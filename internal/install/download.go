@@ -3,49 +3,250 @@ package install
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/system"
+	"golang.org/x/term"
 )
 
-// Create a temporary file and download the installer to it.
-func DownloadFile(installerName string, url string, filename string) (string, error) {
+var (
+	// downloadAttempts is the number of times a download is tried before giving up
+	downloadAttempts = 5
+	// downloadIdleTimeout aborts an attempt when no data has been received for this long
+	downloadIdleTimeout = 30 * time.Second
+	// downloadBackoff is the wait before the first retry, doubled for every retry after it
+	downloadBackoff = 2 * time.Second
+)
+
+// permanentError is a download failure that retrying won't fix, such as a 404
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Create a temporary file and download the installer to it. When an expected SHA-256 digest is provided
+// the download is removed and an error returned if it doesn't match.
+//
+// Failed attempts are retried with an exponential backoff, resuming from the partial file when the server
+// supports range requests. A progress bar is displayed when the output is a terminal.
+//
+// Completed downloads are kept in the download cache and reused by later runs, call RemoveDownload
+// once the installer has been installed to clean up downloads that could not be cached.
+func DownloadFile(installerName string, url string, filename string, sha256 string) (string, error) {
+
+	if system.DryRun() {
+		destination := filepath.Join(os.TempDir(), filename)
+		system.RecordDownload(url, destination)
+		return destination, nil
+	}
+
+	if cachedPath, ok := lookupCache(url, sha256); ok {
+		system.PrintAndLogInfo("Using the cached " + installerName + " installer: " + cachedPath)
+		return cachedPath, nil
+	}
 
 	system.PrintAndLogInfo("Downloading " + installerName + " installer from: " + url)
 
-	// Create the file
-	filename = "*_" + filename
-	tmpFile, err := os.CreateTemp("", filename)
+	// Create the file, in the cache directory when possible so it can be moved into the cache
+	useCache := cacheEnabled()
+	tmpDir := ""
+	if useCache {
+		tmpDir = filepath.Join(cacheDir, cachePartialDir)
+	}
+	tmpFile, err := os.CreateTemp(tmpDir, "*_"+filename)
 	if err != nil {
-		return tmpFile.Name(), err
+		return "", err
 	}
 	defer tmpFile.Close()
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	backoff := downloadBackoff
+	for attempt := 1; ; attempt++ {
+		err = downloadAttempt(tmpFile, url)
+		if err == nil {
+			break
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= downloadAttempts {
+			os.Remove(tmpFile.Name())
+			return "", fmt.Errorf("error downloading %s installer: %w", installerName, err)
+		}
+		system.PrintAndLogInfo(fmt.Sprintf("Download attempt %d of %d failed (%s), retrying in %s", attempt, downloadAttempts, err, backoff))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	// Verify the digest
+	sum, err := VerifySHA256(tmpFile.Name(), sha256)
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("aborting the %s install: %w", installerName, err)
+	}
+	if sha256 != "" {
+		system.PrintAndLogInfo("Verified the SHA-256 digest of the " + installerName + " installer: " + sum)
+	}
+
+	if useCache {
+		cachedPath, err := storeInCache(tmpFile.Name(), url, filename, sum)
+		if err == nil {
+			return cachedPath, nil
+		}
+		log.Info(fmt.Sprintf("Failed to add %s to the download cache: %s", url, err))
 	}
-	req, err := http.NewRequestWithContext(context.Background(),
-		http.MethodGet, url, nil)
+	return tmpFile.Name(), nil
+}
+
+// downloadAttempt downloads a URL to a file, resuming from the end of the file if it already contains data
+func downloadAttempt(file *os.File, url string) error {
+	info, err := file.Stat()
 	if err != nil {
-		return "", errors.New("error creating request")
+		return &permanentError{err}
 	}
-	res, err := client.Do(req)
+	offset := info.Size()
+
+	// the request is cancelled when no data is received for the idle timeout, rather than
+	// limiting the total time a large download can take
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(downloadIdleTimeout, cancel)
+	defer idle.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", errors.New("error downloading " + installerName + " installer")
+		return &permanentError{errors.New("error creating request")}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	res, err := httpclient.NewDownload().Do(req)
+	if err != nil {
+		return idleError(ctx, err)
 	}
 	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		return "", errors.New("error retrieving " + installerName + " installer")
+
+	switch {
+	case res.StatusCode == http.StatusPartialContent && offset > 0:
+		log.Info(fmt.Sprintf("Resuming the download of %s from byte %d", url, offset))
+	case res.StatusCode == http.StatusOK:
+		// the server doesn't support resuming, so start over
+		offset = 0
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("HTTP status %s", res.Status)
+	default:
+		return &permanentError{fmt.Errorf("HTTP status %s", res.Status)}
 	}
 
-	// Writer the body to file
-	_, err = io.Copy(tmpFile, res.Body)
+	err = file.Truncate(offset)
 	if err != nil {
-		return "", err
+		return &permanentError{err}
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return &permanentError{err}
 	}
 
-	return tmpFile.Name(), nil
+	var writer io.Writer = file
+	// the progress bar is drawn with the other messages, so it stays out of structured output
+	if messages, ok := system.MessageOutput().(*os.File); ok && term.IsTerminal(int(messages.Fd())) {
+		bar := newProgressBar(messages, offset, res.ContentLength)
+		defer bar.finish()
+		writer = io.MultiWriter(file, bar)
+	}
+
+	_, err = io.Copy(writer, &idleReader{reader: res.Body, timer: idle, timeout: downloadIdleTimeout})
+	if err != nil {
+		return idleError(ctx, err)
+	}
+	return nil
+}
+
+// idleError replaces the context cancellation error with a clearer message when the idle timeout fired
+func idleError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("no data received for %s", downloadIdleTimeout)
+	}
+	return err
+}
+
+// idleReader restarts the idle timer every time data is read
+type idleReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+// progressBar draws the progress of a download on a single terminal line
+type progressBar struct {
+	out     io.Writer
+	current int64
+	total   int64
+	drawn   time.Time
+}
+
+// newProgressBar creates a progress bar for a download resumed at an offset, the length is -1 when unknown
+func newProgressBar(out io.Writer, offset int64, length int64) *progressBar {
+	total := int64(-1)
+	if length >= 0 {
+		total = offset + length
+	}
+	return &progressBar{out: out, current: offset, total: total}
+}
+
+func (p *progressBar) Write(b []byte) (int, error) {
+	p.current += int64(len(b))
+	// redrawing on every write makes the terminal flicker
+	if time.Since(p.drawn) >= 200*time.Millisecond {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+func (p *progressBar) draw() {
+	p.drawn = time.Now()
+	const width = 40
+	megabytes := float64(p.current) / 1024 / 1024
+	if p.total <= 0 {
+		fmt.Fprintf(p.out, "\r%.1f MB", megabytes)
+		return
+	}
+	filled := int(float64(width) * float64(p.current) / float64(p.total))
+	if filled > width {
+		filled = width
+	}
+	bar := make([]byte, width)
+	for i := range bar {
+		if i < filled {
+			bar[i] = '='
+		} else {
+			bar[i] = ' '
+		}
+	}
+	fmt.Fprintf(p.out, "\r[%s] %3d%% %.1f/%.1f MB", bar, p.current*100/p.total, megabytes, float64(p.total)/1024/1024)
+}
+
+func (p *progressBar) finish() {
+	p.draw()
+	fmt.Fprintln(p.out)
 }
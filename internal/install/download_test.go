@@ -0,0 +1,125 @@
+package install
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadFile(t *testing.T) {
+	SetCacheDir("")
+	downloadBackoff = time.Millisecond
+	downloadIdleTimeout = 200 * time.Millisecond
+	content := strings.Repeat("workbench", 1000)
+
+	tests := map[string]struct {
+		handler      func(attempt int32, w http.ResponseWriter, r *http.Request)
+		expectError  string
+		expectCalls  int32
+		expectResume bool
+	}{
+		"succeeds on the first attempt": {
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, content)
+			},
+			expectCalls: 1,
+		},
+		"retries a server error": {
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				fmt.Fprint(w, content)
+			},
+			expectCalls: 2,
+		},
+		"does not retry a missing file": {
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectError: "404",
+			expectCalls: 1,
+		},
+		"resumes a dropped connection with a range request": {
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt == 1 {
+					w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+					fmt.Fprint(w, content[:len(content)/2])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", len(content)/2, len(content)-1, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				fmt.Fprint(w, content[len(content)/2:])
+			},
+			expectCalls:  2,
+			expectResume: true,
+		},
+		"restarts when the server ignores the range request": {
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt == 1 {
+					w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+					fmt.Fprint(w, content[:len(content)/2])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				fmt.Fprint(w, content)
+			},
+			expectCalls: 2,
+		},
+		"retries a stalled download": {
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt == 1 {
+					w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+					fmt.Fprint(w, content[:10])
+					w.(http.Flusher).Flush()
+					select {
+					case <-r.Context().Done():
+					case <-time.After(2 * time.Second):
+					}
+					return
+				}
+				fmt.Fprint(w, content)
+			},
+			expectCalls: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			var resumed bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") != "" {
+					resumed = true
+				}
+				tc.handler(atomic.AddInt32(&calls, 1), w, r)
+			}))
+			defer server.Close()
+
+			path, err := DownloadFile("Workbench", server.URL+"/workbench.deb", "workbench.deb", "")
+			assert.Equal(t, tc.expectCalls, atomic.LoadInt32(&calls))
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			defer os.Remove(path)
+			downloaded, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(downloaded))
+			if tc.expectResume {
+				assert.True(t, resumed, "expected the download to be resumed with a range request")
+			}
+		})
+	}
+}
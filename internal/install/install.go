@@ -31,7 +31,7 @@ func InstallLanguage(language string, filepath string, osType config.OperatingSy
 // Creates the proper command to install R/Python based on the operating system
 func RetrieveInstallCommand(filepath string, osType config.OperatingSystem) (string, error) {
 	switch osType {
-	case config.Ubuntu22, config.Ubuntu20:
+	case config.Ubuntu24, config.Ubuntu22, config.Ubuntu20:
 		return "DEBIAN_FRONTEND=noninteractive gdebi -n " + filepath, nil
 	case config.Redhat7, config.Redhat8, config.Redhat9:
 		return "yum install -y " + filepath, nil
@@ -39,3 +39,15 @@ func RetrieveInstallCommand(filepath string, osType config.OperatingSystem) (str
 		return "", errors.New("operating system not supported")
 	}
 }
+
+// Creates the proper command to remove an R/Python package based on the operating system
+func RetrieveRemoveCommand(packageName string, osType config.OperatingSystem) (string, error) {
+	switch osType {
+	case config.Ubuntu24, config.Ubuntu22, config.Ubuntu20:
+		return "DEBIAN_FRONTEND=noninteractive apt-get remove -y " + packageName, nil
+	case config.Redhat7, config.Redhat8, config.Redhat9:
+		return "yum remove -y " + packageName, nil
+	default:
+		return "", errors.New("operating system not supported")
+	}
+}
@@ -0,0 +1,269 @@
+package install
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/mirrors"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// PositSigningKeyID is the ID of the key Posit signs the Workbench and Pro Drivers packages with, used to look the
+// key up and in messages
+const PositSigningKeyID = "51C0B5BB19F92D60"
+
+// PositSigningKeyFingerprint is the full fingerprint of the Posit signing key, the downloaded key must have exactly this
+// fingerprint. It must be copied from the fingerprint Posit publishes for the key ID, packages can't be verified while
+// it's empty.
+const PositSigningKeyFingerprint = ""
+
+// signingKeyFingerprint is the fingerprint the key is checked against, tests replace it with the fingerprint of a
+// generated key
+var signingKeyFingerprint = PositSigningKeyFingerprint
+
+// PositSigningKeyURL returns where the Posit signing key is retrieved from. The key server or its mirror isn't trusted,
+// the key it returns is only used when its fingerprint is the pinned fingerprint.
+func PositSigningKeyURL() string {
+	return mirrors.URL(mirrors.KeyServer, "/vks/v1/by-keyid/"+PositSigningKeyID)
+}
+
+// VerifySHA256 returns the SHA-256 digest of a file and, when an expected digest is provided, an error if they don't match
+func VerifySHA256(filePath string, expected string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	if expected != "" && !strings.EqualFold(sum, expected) {
+		return sum, fmt.Errorf("the SHA-256 digest of %s is %s but %s was expected, the download may be corrupt or tampered with", filePath, sum, expected)
+	}
+	return sum, nil
+}
+
+// SHA256CheckCommand creates the command that verifies a downloaded file's digest, saved to the command log after the download
+func SHA256CheckCommand(sum string, filename string) string {
+	return fmt.Sprintf(`echo "%s  %s" | sha256sum --check`, sum, filename)
+}
+
+// VerifyPackageSignature verifies a Workbench or Pro Drivers package is signed with the pinned Posit key
+func VerifyPackageSignature(packagePath string, osType config.OperatingSystem) error {
+	if system.DryRun() {
+		system.RecordCommand("verify the signature of " + packagePath + " against the Posit signing key " + PositSigningKeyID)
+		return nil
+	}
+
+	if len(signingKeyFingerprint) != 40 {
+		return errors.New("the fingerprint of the Posit signing key is not pinned in this build of wbi, so the package signature can't be verified")
+	}
+
+	system.PrintAndLogInfo("Verifying the signature of " + packagePath + " against the Posit signing key " + PositSigningKeyID)
+	keyPath, err := downloadSigningKey()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(keyPath)
+	fingerprint, err := checkSigningKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	switch osType {
+	case config.Ubuntu24, config.Ubuntu22, config.Ubuntu20:
+		err = verifyDebSignature(packagePath, keyPath, fingerprint)
+	case config.Redhat7, config.Redhat8, config.Redhat9:
+		err = verifyRPMSignature(packagePath, keyPath)
+	default:
+		return errors.New("operating system not supported")
+	}
+	if err != nil {
+		return fmt.Errorf("the signature of %s could not be verified against the Posit signing key %s: %w", packagePath, PositSigningKeyID, err)
+	}
+
+	system.PrintAndLogInfo("The signature of " + packagePath + " has been verified")
+	return nil
+}
+
+// downloadSigningKey downloads the pinned Posit signing key to a temporary file
+func downloadSigningKey() (string, error) {
+	client := httpclient.New()
+	req, err := http.NewRequestWithContext(context.Background(),
+		http.MethodGet, PositSigningKeyURL(), nil)
+	if err != nil {
+		return "", errors.New("error creating request")
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", errors.New("error retrieving the Posit signing key")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", errors.New("error retrieving the Posit signing key")
+	}
+
+	keyFile, err := os.CreateTemp("", "*_posit-signing-key.asc")
+	if err != nil {
+		return "", err
+	}
+	defer keyFile.Close()
+	_, err = io.Copy(keyFile, res.Body)
+	if err != nil {
+		os.Remove(keyFile.Name())
+		return "", err
+	}
+	return keyFile.Name(), nil
+}
+
+// checkSigningKey checks the downloaded key file holds exactly one key with the pinned fingerprint, and returns the
+// fingerprint. The key is shown without being imported, and gpg uses an empty home directory.
+func checkSigningKey(keyPath string) (string, error) {
+	gnupgHome, err := os.MkdirTemp("", "wbi-gnupg-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a keyring directory: %w", err)
+	}
+	defer os.RemoveAll(gnupgHome)
+
+	showCommand := "gpg --batch --homedir " + gnupgHome + " --with-colons --show-keys " + keyPath
+	output, err := system.RunCommandAndCaptureOutput(showCommand, false, 0, false)
+	if err != nil {
+		return "", fmt.Errorf("issue reading the Posit signing key: %w", err)
+	}
+	return matchKeyFingerprint(output, signingKeyFingerprint)
+}
+
+// matchKeyFingerprint parses gpg --with-colons output for a single primary key with the expected fingerprint
+func matchKeyFingerprint(output string, expected string) (string, error) {
+	var fingerprints []string
+	primary := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		switch {
+		case fields[0] == "pub":
+			primary = true
+		case fields[0] == "sub":
+			primary = false
+		case fields[0] == "fpr" && primary && len(fields) > 9:
+			fingerprints = append(fingerprints, strings.ToUpper(fields[9]))
+			primary = false
+		}
+	}
+	if len(fingerprints) != 1 {
+		return "", fmt.Errorf("the Posit signing key file holds %d keys, only the key %s was expected", len(fingerprints), expected)
+	}
+	if fingerprints[0] != strings.ToUpper(expected) {
+		return "", fmt.Errorf("the Posit signing key file holds the key %s, not the key %s", fingerprints[0], expected)
+	}
+	return fingerprints[0], nil
+}
+
+// verifyDebSignature checks the dpkg-sig signature of a deb package with a keyring only containing the Posit key
+func verifyDebSignature(packagePath string, keyPath string, fingerprint string) error {
+	if _, err := exec.LookPath("dpkg-sig"); err != nil {
+		installCommand := "DEBIAN_FRONTEND=noninteractive apt-get install -y dpkg-sig"
+		err = system.RunCommand(installCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue installing dpkg-sig with the command '%s': %w", installCommand, err)
+		}
+	}
+
+	gnupgHome, err := os.MkdirTemp("", "wbi-gnupg-")
+	if err != nil {
+		return fmt.Errorf("failed to create a keyring directory: %w", err)
+	}
+	defer os.RemoveAll(gnupgHome)
+
+	importCommand := "GNUPGHOME=" + gnupgHome + " gpg --batch --import " + keyPath
+	_, err = system.RunCommandAndCaptureOutput(importCommand, false, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue importing the Posit signing key: %w", err)
+	}
+
+	verifyCommand := "GNUPGHOME=" + gnupgHome + " dpkg-sig --verify " + packagePath
+	output, err := system.RunCommandAndCaptureOutput(verifyCommand, true, 0, false)
+	if err != nil {
+		return err
+	}
+	return debSignatureMatches(output, fingerprint)
+}
+
+// debSignatureMatches checks dpkg-sig reported a good signature, "GOODSIG <role> <fingerprint> <timestamp>", made
+// with the key of the fingerprint
+func debSignatureMatches(output string, fingerprint string) error {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "GOODSIG" && strings.EqualFold(fields[2], fingerprint) {
+			return nil
+		}
+	}
+	return errors.New("no good signature from the Posit signing key was found")
+}
+
+// verifyRPMSignature checks the package signature with a temporary rpm database only containing the Posit key, so
+// the key is never imported into the system's rpm keyring
+func verifyRPMSignature(packagePath string, keyPath string) error {
+	dbPath, err := os.MkdirTemp("", "wbi-rpmdb-")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary rpm database: %w", err)
+	}
+	defer os.RemoveAll(dbPath)
+
+	importCommand := "rpm --dbpath " + dbPath + " --initdb && rpm --dbpath " + dbPath + " --import " + keyPath
+	_, err = system.RunCommandAndCaptureOutput(importCommand, false, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue importing the Posit signing key into a temporary rpm database: %w", err)
+	}
+
+	verifyCommand := "rpm --dbpath " + dbPath + " -Kv " + packagePath
+	output, err := system.RunCommandAndCaptureOutput(verifyCommand, true, 0, false)
+	if err != nil {
+		return err
+	}
+	// rpm -K only reports the short key ID, so the full key ID is read from the signature itself
+	signatureCommand := "rpm --dbpath " + dbPath + ` -qp --qf '%{RSAHEADER:pgpsig}\n%{SIGPGP:pgpsig}\n' ` + packagePath
+	signature, err := system.RunCommandAndCaptureOutput(signatureCommand, false, 0, false)
+	if err != nil {
+		return err
+	}
+	// the long key ID is the end of the fingerprint
+	return rpmSignatureMatches(output, signature, signingKeyFingerprint[len(signingKeyFingerprint)-16:])
+}
+
+// rpmSignatureMatches checks rpm -Kv reported every signature and digest OK, and the package signature, such as
+// "RSA/SHA256, Mon 01 Jan 2024, Key ID 51c0b5bb19f92d60", was made with the full key ID
+func rpmSignatureMatches(verifyOutput string, signature string, keyID string) error {
+	signed := false
+	// the first line names the package, each following line reports a signature or digest
+	for _, line := range strings.Split(verifyOutput, "\n")[1:] {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, ": ok") {
+			return errors.New("no good signature from the Posit signing key was found")
+		}
+		signed = signed || strings.Contains(line, "signature")
+	}
+	if !signed {
+		return errors.New("no good signature from the Posit signing key was found")
+	}
+	if !strings.Contains(strings.ToLower(signature), "key id "+strings.ToLower(keyID)) {
+		return fmt.Errorf("the package is not signed with the Posit signing key %s", keyID)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package install
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySHA256(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "installer.deb")
+	require.NoError(t, os.WriteFile(filePath, []byte("installer"), 0644))
+	// sha256sum of "installer"
+	digest := "9c0d294c05fc1d88d698034609bb81c0c69196327594e4c69d2915c80fd9850c"
+
+	tests := map[string]struct {
+		expected    string
+		expectError string
+	}{
+		"no expected digest succeeds":        {expected: "", expectError: ""},
+		"matching digest succeeds":           {expected: digest, expectError: ""},
+		"matching uppercase digest succeeds": {expected: "9C0D294C05FC1D88D698034609BB81C0C69196327594E4C69D2915C80FD9850C", expectError: ""},
+		"mismatched digest fails":            {expected: "0000000000000000000000000000000000000000000000000000000000000000", expectError: "the download may be corrupt or tampered with"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sum, err := VerifySHA256(filePath, tc.expected)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, digest, sum)
+		})
+	}
+}
+
+// generateSigningKey creates a key in a temporary keyring and exports it, returning the key file and fingerprint
+func generateSigningKey(t *testing.T, name string) (string, string) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	gnupgHome := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("gpg", append([]string{"--batch", "--homedir", gnupgHome}, args...)...)
+		output, err := cmd.Output()
+		require.NoError(t, err, "gpg %v", args)
+		return string(output)
+	}
+	run("--passphrase", "", "--quick-gen-key", name+" <"+name+"@example.com>", "ed25519", "sign", "never")
+	keyPath := filepath.Join(t.TempDir(), name+".asc")
+	require.NoError(t, os.WriteFile(keyPath, []byte(run("--armor", "--export")), 0644))
+	for _, line := range strings.Split(run("--with-colons", "--list-keys"), "\n") {
+		if strings.HasPrefix(line, "fpr:") {
+			return keyPath, strings.Split(line, ":")[9]
+		}
+	}
+	t.Fatal("the generated key has no fingerprint")
+	return "", ""
+}
+
+func TestCheckSigningKey(t *testing.T) {
+	keyPath, fingerprint := generateSigningKey(t, "posit")
+	wrongKeyPath, wrongFingerprint := generateSigningKey(t, "attacker")
+	original := signingKeyFingerprint
+	signingKeyFingerprint = fingerprint
+	t.Cleanup(func() { signingKeyFingerprint = original })
+
+	found, err := checkSigningKey(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, found)
+
+	_, err = checkSigningKey(wrongKeyPath)
+	assert.ErrorContains(t, err, "holds the key "+wrongFingerprint+", not the key "+fingerprint)
+
+	// a file holding the pinned key alongside another key is rejected
+	keys, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	wrongKey, err := os.ReadFile(wrongKeyPath)
+	require.NoError(t, err)
+	bothPath := filepath.Join(t.TempDir(), "both.asc")
+	require.NoError(t, os.WriteFile(bothPath, append(keys, wrongKey...), 0644))
+	_, err = checkSigningKey(bothPath)
+	assert.ErrorContains(t, err, "holds 2 keys")
+}
+
+func TestMatchKeyFingerprint(t *testing.T) {
+	fingerprint := "0123456789ABCDEF0123456751C0B5BB19F92D60"
+	tests := map[string]struct {
+		output      string
+		expectError string
+	}{
+		"pinned key": {
+			output: "pub:-:4096:1:51C0B5BB19F92D60:1600000000:::-:::scESC::::::23::0:\nfpr:::::::::" + fingerprint + ":\nsub:-:4096:1:1111111111111111:1600000000::::::e::::::23:\nfpr:::::::::0000000000000000000000001111111111111111:\n",
+		},
+		"pinned key in lowercase": {
+			output: "pub:-:4096:1:51C0B5BB19F92D60:1600000000:::-:::scESC::::::23::0:\nfpr:::::::::" + strings.ToLower(fingerprint) + ":\n",
+		},
+		"key with a colliding key ID": {
+			output:      "pub:-:4096:1:51C0B5BB19F92D60:1600000000:::-:::scESC::::::23::0:\nfpr:::::::::FFFFFFFFFFFFFFFFFFFFFFFF51C0B5BB19F92D60:\n",
+			expectError: "not the key " + fingerprint,
+		},
+		"no key": {
+			output:      "",
+			expectError: "holds 0 keys",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			found, err := matchKeyFingerprint(tc.output, fingerprint)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, fingerprint, found)
+		})
+	}
+}
+
+func TestVerifyPackageSignatureWithoutFingerprint(t *testing.T) {
+	original := signingKeyFingerprint
+	signingKeyFingerprint = ""
+	t.Cleanup(func() { signingKeyFingerprint = original })
+
+	err := VerifyPackageSignature(filepath.Join(t.TempDir(), "rstudio-workbench.deb"), config.Ubuntu22)
+	assert.ErrorContains(t, err, "the fingerprint of the Posit signing key is not pinned")
+}
+
+func TestDebSignatureMatches(t *testing.T) {
+	fingerprint := "0123456789ABCDEF0123456751C0B5BB19F92D60"
+	tests := map[string]struct {
+		output      string
+		expectError string
+	}{
+		"good signature":      {output: "Processing rstudio-server.deb...\nGOODSIG _gpgbuilder " + fingerprint + " 1700000000\n"},
+		"bad signature":       {output: "Processing rstudio-server.deb...\nBADSIG _gpgbuilder\n", expectError: "no good signature"},
+		"wrong key signature": {output: "GOODSIG _gpgbuilder FFFFFFFFFFFFFFFFFFFFFFFF51C0B5BB19F92D60 1700000000\n", expectError: "no good signature"},
+		"unsigned":            {output: "Processing rstudio-server.deb...\nNOSIG\n", expectError: "no good signature"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := debSignatureMatches(tc.output, fingerprint)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRPMSignatureMatches(t *testing.T) {
+	goodVerify := "rstudio-server.rpm:\n    Header V4 RSA/SHA256 Signature, key ID 19f92d60: OK\n    Header SHA256 digest: OK\n    Payload SHA256 digest: OK\n"
+	goodSignature := "RSA/SHA256, Mon Jan  1 00:00:00 2024, Key ID 51c0b5bb19f92d60\n(none)\n"
+	tests := map[string]struct {
+		verify      string
+		signature   string
+		expectError string
+	}{
+		"good signature": {verify: goodVerify, signature: goodSignature},
+		"bad signature": {
+			verify:      "rstudio-server.rpm:\n    Header V4 RSA/SHA256 Signature, key ID 19f92d60: BAD\n    Header SHA256 digest: OK\n",
+			signature:   goodSignature,
+			expectError: "no good signature",
+		},
+		"key not in the temporary database": {
+			verify:      "rstudio-server.rpm:\n    Header V4 RSA/SHA256 Signature, key ID 19f92d60: NOKEY\n    Header SHA256 digest: OK\n",
+			signature:   goodSignature,
+			expectError: "no good signature",
+		},
+		"unsigned": {
+			verify:      "rstudio-server.rpm:\n    Header SHA256 digest: OK\n    Payload SHA256 digest: OK\n",
+			signature:   "(none)\n(none)\n",
+			expectError: "no good signature",
+		},
+		"wrong key with a colliding short ID": {
+			verify:      goodVerify,
+			signature:   "RSA/SHA256, Mon Jan  1 00:00:00 2024, Key ID ffffffff19f92d60\n(none)\n",
+			expectError: "not signed with the Posit signing key 51C0B5BB19F92D60",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := rpmSignatureMatches(tc.verify, tc.signature, "51C0B5BB19F92D60")
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sol-eng/wbi/internal/bundle"
 	"github.com/sol-eng/wbi/internal/languages"
 	"github.com/sol-eng/wbi/internal/system"
 	"github.com/sol-eng/wbi/internal/workbench"
 )
 
+// JupyterPackages are the packages installed from PyPI to run Jupyter in Workbench
+var JupyterPackages = []string{"jupyter", "jupyterlab==3.6.5", "rsp_jupyter", "rsconnect_jupyter", "workbench_jupyterlab==1.1.315"}
+
 // InstallJupyter installs jupypter pip stuff
 func InstallJupyter(pythonPath string) error {
 	JupyterComponentsErr := InstallJupyterAndComponents(pythonPath)
@@ -24,7 +28,7 @@ func InstallJupyter(pythonPath string) error {
 
 // Install various Jupyter related packages from PyPI
 func InstallJupyterAndComponents(pythonPath string) error {
-	licenseCommand := "PIP_ROOT_USER_ACTION=ignore " + pythonPath + " -m pip install --no-warn-script-location --disable-pip-version-check jupyter jupyterlab==3.6.5 rsp_jupyter rsconnect_jupyter workbench_jupyterlab==1.1.315"
+	licenseCommand := "PIP_ROOT_USER_ACTION=ignore " + pythonPath + " -m pip install --no-warn-script-location --disable-pip-version-check" + bundle.PipIndexArgs() + " " + strings.Join(JupyterPackages, " ")
 	err := system.RunCommand(licenseCommand, true, 2, true)
 	if err != nil {
 		return fmt.Errorf("issue installing Jupyter with the command '%s': %w", licenseCommand, err)
@@ -111,7 +115,7 @@ func installIpykernel(pythonPath string) error {
 		return fmt.Errorf("issue removing python from the path: %w", err)
 	}
 
-	installCommand := "PIP_ROOT_USER_ACTION=ignore " + basePath + "/pip install --no-warn-script-location --disable-pip-version-check ipykernel"
+	installCommand := "PIP_ROOT_USER_ACTION=ignore " + basePath + "/pip install --no-warn-script-location --disable-pip-version-check" + bundle.PipIndexArgs() + " ipykernel"
 	err = system.RunCommand(installCommand, true, 1, true)
 	if err != nil {
 		return fmt.Errorf("issue installing ipykernel with the command '%s': %w", installCommand, err)
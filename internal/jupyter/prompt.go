@@ -7,12 +7,16 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/languages"
 	"github.com/sol-eng/wbi/internal/workbench"
 )
 
 // Prompt asking users if they wish to install Jupyter
 func InstallPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Jupyter.Install, "jupyter.install")
+	}
 	name := true
 	messageText := "Would you like to install Jupyter?"
 	prompt := &survey.Confirm{
@@ -29,6 +33,9 @@ func InstallPrompt() (bool, error) {
 
 // Prompt asking users which Python location should Jupyter be installed into
 func KernelPrompt(pythonPaths []string) (string, error) {
+	if answers.Loaded() {
+		return answers.RequireOneOf(answers.Get().Jupyter.PythonPath, "jupyter.python_path", pythonPaths)
+	}
 	// Allow the user to select a version of Python to target
 	target := ""
 	messageText := "Select a Python kernel to install Jupyter into:"
@@ -50,6 +57,13 @@ func KernelPrompt(pythonPaths []string) (string, error) {
 
 // Prompt asking users which additional Python location should be registered as Jupyter kernels
 func AdditionalKernelPrompt(pythonPaths []string, defaultPythonPaths []string) ([]string, error) {
+	if answers.Loaded() {
+		// additional kernels are optional so a missing answer registers none
+		if answers.Get().Jupyter.AdditionalKernels == nil {
+			return []string{}, nil
+		}
+		return answers.RequireSubsetOf(answers.Get().Jupyter.AdditionalKernels, "jupyter.additional_kernels", pythonPaths)
+	}
 	// Allow the user to select multiple versions
 	var qs = []*survey.Question{
 		{
@@ -0,0 +1,151 @@
+package jupyter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/sol-eng/wbi/internal/workbench"
+)
+
+// JupyterInstallation describes an installation of Jupyter found by a scan and the kernels it can run
+type JupyterInstallation struct {
+	Path    string `json:"path" yaml:"path"`
+	Version string `json:"version" yaml:"version"`
+	// Configured is true when jupyter.conf sets this installation as jupyter-exe
+	Configured bool `json:"configured" yaml:"configured"`
+	// Broken is true when the installation could not be run, Error describes why
+	Broken  bool            `json:"broken" yaml:"broken"`
+	Error   string          `json:"error,omitempty" yaml:"error,omitempty"`
+	Kernels []JupyterKernel `json:"kernels" yaml:"kernels"`
+}
+
+// JupyterKernel is a kernelspec registered with a Jupyter installation
+type JupyterKernel struct {
+	Name        string `json:"name" yaml:"name"`
+	DisplayName string `json:"display_name" yaml:"display_name"`
+	Language    string `json:"language" yaml:"language"`
+	// Executable is the interpreter the kernel runs, the first entry of its argv
+	Executable  string `json:"executable" yaml:"executable"`
+	ResourceDir string `json:"resource_dir" yaml:"resource_dir"`
+}
+
+var (
+	// jupyterGlob matches Jupyter installed into each version of Python in /opt/python
+	jupyterGlob = "/opt/python/*/bin/jupyter"
+	// readJupyterConfig returns the jupyter-exe set in jupyter.conf
+	readJupyterConfig = workbench.ReadJupyterConfig
+	// runCommand runs a read-only command and returns its stdout, so warnings on stderr don't break parsing its JSON
+	runCommand = func(command string) (string, error) {
+		return system.RunCommandAndCaptureStdout(command, false)
+	}
+)
+
+// ScanJupyterInstallations scans for Jupyter installed in /opt/python, the Jupyter configured in jupyter.conf
+// and Jupyter on the PATH, running each one to find its version and kernels
+func ScanJupyterInstallations() ([]JupyterInstallation, error) {
+	jupyterPaths, err := filepath.Glob(jupyterGlob)
+	if err != nil {
+		return nil, fmt.Errorf("issue scanning for Jupyter in /opt/python: %w", err)
+	}
+	sort.Strings(jupyterPaths)
+
+	configuredPath, err := readJupyterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("issue reading the Jupyter configuration: %w", err)
+	}
+	if configuredPath != "" && !system.ContainsPath(jupyterPaths, configuredPath) {
+		jupyterPaths = append(jupyterPaths, configuredPath)
+	}
+	if pathJupyter, err := exec.LookPath("jupyter"); err == nil && !system.ContainsPath(jupyterPaths, pathJupyter) {
+		jupyterPaths = append(jupyterPaths, pathJupyter)
+	}
+
+	installations := []JupyterInstallation{}
+	for _, jupyterPath := range jupyterPaths {
+		installation := JupyterInstallation{
+			Path:       jupyterPath,
+			Configured: configuredPath != "" && system.SamePath(jupyterPath, configuredPath),
+			Kernels:    []JupyterKernel{},
+		}
+		installedVersion, err := probeJupyter(jupyterPath)
+		if err != nil {
+			installation.Broken = true
+			installation.Error = err.Error()
+			installations = append(installations, installation)
+			continue
+		}
+		installation.Version = installedVersion
+		kernels, err := listKernels(jupyterPath)
+		if err != nil {
+			installation.Error = err.Error()
+		} else {
+			installation.Kernels = kernels
+		}
+		installations = append(installations, installation)
+	}
+	return installations, nil
+}
+
+// probeJupyter returns the version of jupyter_core reported by jupyter --version. Older versions of
+// Jupyter print only the version, newer ones list every Jupyter package.
+func probeJupyter(jupyterPath string) (string, error) {
+	out, err := runCommand(jupyterPath + " --version")
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) == 1 && lines[0] != "" {
+		return strings.TrimSpace(lines[0]), nil
+	}
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.TrimSpace(name) == "jupyter_core" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", errors.New("jupyter did not report its version")
+}
+
+// listKernels returns the kernelspecs registered with a Jupyter installation, sorted by name
+func listKernels(jupyterPath string) ([]JupyterKernel, error) {
+	out, err := runCommand(jupyterPath + " kernelspec list --json")
+	if err != nil {
+		return nil, fmt.Errorf("issue listing Jupyter kernels: %w", err)
+	}
+	var kernelspecs struct {
+		Kernelspecs map[string]struct {
+			ResourceDir string `json:"resource_dir"`
+			Spec        struct {
+				Argv        []string `json:"argv"`
+				DisplayName string   `json:"display_name"`
+				Language    string   `json:"language"`
+			} `json:"spec"`
+		} `json:"kernelspecs"`
+	}
+	err = json.Unmarshal([]byte(out), &kernelspecs)
+	if err != nil {
+		return nil, fmt.Errorf("issue parsing the Jupyter kernel list: %w", err)
+	}
+
+	kernels := []JupyterKernel{}
+	for name, kernelspec := range kernelspecs.Kernelspecs {
+		kernel := JupyterKernel{
+			Name:        name,
+			DisplayName: kernelspec.Spec.DisplayName,
+			Language:    kernelspec.Spec.Language,
+			ResourceDir: kernelspec.ResourceDir,
+		}
+		if len(kernelspec.Spec.Argv) > 0 {
+			kernel.Executable = kernelspec.Spec.Argv[0]
+		}
+		kernels = append(kernels, kernel)
+	}
+	sort.Slice(kernels, func(i, j int) bool { return kernels[i].Name < kernels[j].Name })
+	return kernels, nil
+}
@@ -0,0 +1,85 @@
+package jupyter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kernelspecJSON = `{
+  "kernelspecs": {
+    "python3": {
+      "resource_dir": "/opt/python/3.11.2/share/jupyter/kernels/python3",
+      "spec": {
+        "argv": ["/opt/python/3.11.2/bin/python", "-m", "ipykernel_launcher", "-f", "{connection_file}"],
+        "display_name": "Python 3 (ipykernel)",
+        "language": "python"
+      }
+    },
+    "ir": {
+      "resource_dir": "/usr/local/share/jupyter/kernels/ir",
+      "spec": {
+        "argv": ["/opt/R/4.3.2/lib/R/bin/R", "--slave", "-e", "IRkernel::main()", "--args", "{connection_file}"],
+        "display_name": "R",
+        "language": "R"
+      }
+    }
+  }
+}`
+
+func TestScanJupyterInstallations(t *testing.T) {
+	dir := t.TempDir()
+	working := filepath.Join(dir, "3.11.2", "bin", "jupyter")
+	broken := filepath.Join(dir, "3.10.0", "bin", "jupyter")
+	for _, path := range []string{working, broken} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte{}, 0755))
+	}
+
+	oldGlob, oldReadConfig, oldRunCommand := jupyterGlob, readJupyterConfig, runCommand
+	t.Cleanup(func() { jupyterGlob, readJupyterConfig, runCommand = oldGlob, oldReadConfig, oldRunCommand })
+	jupyterGlob = filepath.Join(dir, "*", "bin", "jupyter")
+	readJupyterConfig = func() (string, error) { return working, nil }
+	outputs := map[string]string{
+		working + " --version":              "Selected Jupyter core packages...\nIPython          : 8.14.0\njupyter_core     : 5.3.1\n",
+		working + " kernelspec list --json": kernelspecJSON,
+	}
+	runCommand = func(command string) (string, error) {
+		if out, ok := outputs[command]; ok {
+			return out, nil
+		}
+		return "", errors.New("exit status 1")
+	}
+
+	installations, err := ScanJupyterInstallations()
+	require.NoError(t, err)
+	require.Len(t, installations, 2)
+
+	assert.Equal(t, broken, installations[0].Path)
+	assert.True(t, installations[0].Broken)
+	assert.Empty(t, installations[0].Kernels)
+
+	assert.Equal(t, JupyterInstallation{
+		Path:       working,
+		Version:    "5.3.1",
+		Configured: true,
+		Kernels: []JupyterKernel{
+			{Name: "ir", DisplayName: "R", Language: "R", Executable: "/opt/R/4.3.2/lib/R/bin/R", ResourceDir: "/usr/local/share/jupyter/kernels/ir"},
+			{Name: "python3", DisplayName: "Python 3 (ipykernel)", Language: "python", Executable: "/opt/python/3.11.2/bin/python", ResourceDir: "/opt/python/3.11.2/share/jupyter/kernels/python3"},
+		},
+	}, installations[1])
+}
+
+func TestProbeJupyterSingleLineVersion(t *testing.T) {
+	oldRunCommand := runCommand
+	t.Cleanup(func() { runCommand = oldRunCommand })
+	runCommand = func(command string) (string, error) { return "4.11.2\n", nil }
+
+	installedVersion, err := probeJupyter("/usr/bin/jupyter")
+	require.NoError(t, err)
+	assert.Equal(t, "4.11.2", installedVersion)
+}
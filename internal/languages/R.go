@@ -11,18 +11,25 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/samber/lo"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/install"
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
+// RVersionsURL returns the URL that lists the versions of R available from Posit
+func RVersionsURL() string {
+	return mirrors.URL(mirrors.PositCDN, "/r/versions.json")
+}
+
 var nonNumericRVersions = []string{
 	"next", "devel",
 }
@@ -167,9 +174,8 @@ func AppendIfMissing(slice []string, s string) []string {
 func ScanForRVersions() ([]string, error) {
 	foundVersions := []string{}
 	foundOptVersions := []string{}
-	// This is somewhat naive with respect to actually checking whether
-	// this is _really_ a working version of R by launching it,
-	// vs just matches the path to R
+	// Candidates are only matched by path here, ScanRInstallations runs
+	// each one to check it works and find its version
 	for _, rPath := range GetRPaths() {
 		if _, err := os.Stat(rPath); err == nil {
 			foundVersions = append(foundVersions, rPath)
@@ -202,37 +208,17 @@ func ScanForRVersions() ([]string, error) {
 	}
 
 	// sort /opt/R versions
-	foundOptVersionsSortedPaths, err := sortOptRVersionPaths(foundOptVersions)
-	if err != nil {
-		return []string{}, fmt.Errorf("issue sorting /opt/R versions: %w", err)
-	}
+	foundOptVersionsSortedPaths := sortOptPaths(foundOptVersions, "/opt/R/", ProbeR)
 
 	finalVersionPathSorted := append(foundOptVersionsSortedPaths, foundVersions...)
 	return finalVersionPathSorted, nil
 }
 
-func sortOptRVersionPaths(versionPaths []string) ([]string, error) {
-	foundOptVersionsOnly := []string{}
-	for _, optVersion := range versionPaths {
-		i := strings.Index(optVersion, "R")
-		j := strings.Index(optVersion, "bin")
-		foundOptVersionsOnly = append(foundOptVersionsOnly, optVersion[i+2:j-1])
-	}
-	versions, err := ConvertStringSliceToVersionSlice(foundOptVersionsOnly)
-	if err != nil {
-		return []string{}, fmt.Errorf("issue converting string slice to version slice: %w", err)
-	}
-	sortedVersions := SortVersionsDesc(versions)
-	foundOptVersionsSorted := ConvertVersionSliceToStringSlice(sortedVersions)
-	foundOptVersionsSortedPaths := []string{}
-	for _, optVersion := range foundOptVersionsSorted {
-		foundOptVersionsSortedPaths = append(foundOptVersionsSortedPaths, "/opt/R/"+optVersion+"/bin/R")
-	}
-	return foundOptVersionsSortedPaths, nil
-}
-
 // RInstallPrompt Prompt users if they would like to install R versions
 func RInstallPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().R.Install, "r.install")
+	}
 	name := true
 	messageText := "Would you like to install version(s) of R?"
 	prompt := &survey.Confirm{
@@ -248,11 +234,9 @@ func RInstallPrompt() (bool, error) {
 }
 
 func RetrieveValidRVersions() ([]string, error) {
-	rVersionURL := "https://cdn.posit.co/r/versions.json"
+	rVersionURL := RVersionsURL()
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
 		http.MethodGet, rVersionURL, nil)
 	if err != nil {
@@ -294,6 +278,16 @@ func RetrieveValidRVersions() ([]string, error) {
 
 // RSelectVersionsPrompt Prompt asking users which R version(s) they would like to install
 func RSelectVersionsPrompt(availableRVersions []string) ([]string, error) {
+	if answers.Loaded() {
+		rVersions, err := answers.RequireSubsetOf(answers.Get().R.Versions, "r.versions", availableRVersions)
+		if err != nil {
+			return []string{}, err
+		}
+		if len(rVersions) == 0 {
+			return []string{}, answers.InvalidError("r.versions", "at least one version must be listed when r.install is true")
+		}
+		return rVersions, nil
+	}
 	messageText := "Which version(s) of R would you like to install?"
 	var qs = []*survey.Question{
 		{
@@ -320,12 +314,12 @@ func RSelectVersionsPrompt(availableRVersions []string) ([]string, error) {
 // DownloadAndInstallR Downloads the R installer, and installs R
 func DownloadAndInstallR(rVersion string, osType config.OperatingSystem) error {
 	// Create InstallerInfo with the proper information
-	installerInfo, err := PopulateInstallerInfo("r", rVersion, osType)
+	installerInfo, err := PopulateInstallerInfo("r", rVersion, osType, config.HostArchitecture())
 	if err != nil {
 		return fmt.Errorf("PopulateInstallerInfo: %w", err)
 	}
 	// Download installer
-	installerPath, err := install.DownloadFile("R", installerInfo.URL, installerInfo.Name)
+	installerPath, err := install.DownloadFile("R", installerInfo.URL, installerInfo.Name, "")
 	if err != nil {
 		return fmt.Errorf("DownloadR: %w", err)
 	}
@@ -334,6 +328,7 @@ func DownloadAndInstallR(rVersion string, osType config.OperatingSystem) error {
 	if err != nil {
 		return fmt.Errorf("InstallLanguage: %w", err)
 	}
+	install.RemoveDownload(installerPath)
 	// save to command log
 	installCommand, err := install.RetrieveInstallCommand(installerInfo.Name, osType)
 	if err != nil {
@@ -368,6 +363,9 @@ func PromptAndSetRSymlinks(rPaths []string) error {
 
 // RSymlinkPrompt asks users if they would like to set R symlinks
 func RSymlinkPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().R.Symlink, "r.symlink")
+	}
 	name := true
 	messageText := `Would you like to symlink a R version to make it available on PATH? This is recommended so Workbench can default to this version of R and users can type "R" in the terminal.`
 	prompt := &survey.Confirm{
@@ -384,6 +382,9 @@ func RSymlinkPrompt() (bool, error) {
 
 // RLocationSymlinksPrompt asks users which R binary they want to symlink
 func RLocationSymlinksPrompt(rPaths []string) (string, error) {
+	if answers.Loaded() {
+		return answers.RequireOneOf(answers.Get().R.SymlinkPath, "r.symlink_path", rPaths)
+	}
 	// Allow the user to select a version of R to target
 	target := ""
 	messageText := "Select a R binary to symlink:"
@@ -2,9 +2,11 @@ package languages
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/mirrors"
 )
 
 // InstallerInfo contains the information needed to download and install R and Python
@@ -14,66 +16,53 @@ type InstallerInfo struct {
 	Version string
 }
 
-func PopulateInstallerInfo(language string, version string, osType config.OperatingSystem) (InstallerInfo, error) {
+func PopulateInstallerInfo(language string, version string, osType config.OperatingSystem, arch config.Architecture) (InstallerInfo, error) {
+	// arm64 builds of R and Python are only published for Ubuntu 22.04 and later and RHEL 8 and later
+	if arch == config.ARM64 && (osType == config.Ubuntu20 || osType == config.Redhat7) {
+		languageName := "Python"
+		if language == "r" {
+			languageName = "R"
+		}
+		return InstallerInfo{}, fmt.Errorf("%s has no arm64 build for %s", languageName, osType.ToString())
+	}
+
 	switch osType {
 	case config.Ubuntu20:
-		return InstallerInfo{
-			Name:    language + "-" + version + "_1_amd64.deb",
-			URL:     "https://cdn.rstudio.com/" + language + "/ubuntu-2004/pkgs/" + language + "-" + version + "_1_amd64.deb",
-			Version: version,
-		}, nil
+		return debInstallerInfo(language, version, "ubuntu-2004", arch), nil
 	case config.Ubuntu22:
-		return InstallerInfo{
-			Name:    language + "-" + version + "_1_amd64.deb",
-			URL:     "https://cdn.rstudio.com/" + language + "/ubuntu-2204/pkgs/" + language + "-" + version + "_1_amd64.deb",
-			Version: version,
-		}, nil
+		return debInstallerInfo(language, version, "ubuntu-2204", arch), nil
+	case config.Ubuntu24:
+		return debInstallerInfo(language, version, "ubuntu-2404", arch), nil
 	case config.Redhat7:
-		// Redhat 7 R URL uses lowercase "r" in the beginning but then "R" in the 2nd occurance
-		if language == "r" {
-			return InstallerInfo{
-				Name:    strings.ToUpper(language) + "-" + version + "-1-1.x86_64.rpm",
-				URL:     "https://cdn.rstudio.com/" + language + "/centos-7/pkgs/" + strings.ToUpper(language) + "-" + version + "-1-1.x86_64.rpm",
-				Version: version,
-			}, nil
-		} else {
-			return InstallerInfo{
-				Name:    language + "-" + version + "-1-1.x86_64.rpm",
-				URL:     "https://cdn.rstudio.com/" + language + "/centos-7/pkgs/" + language + "-" + version + "-1-1.x86_64.rpm",
-				Version: version,
-			}, nil
-		}
+		return rpmInstallerInfo(language, version, "centos-7", arch), nil
 	case config.Redhat8:
-		// Redhat 8 R URL uses lowercase "r" in the beginning but then "R" in the 2nd occurance
-		if language == "r" {
-			return InstallerInfo{
-				Name:    strings.ToUpper(language) + "-" + version + "-1-1.x86_64.rpm",
-				URL:     "https://cdn.rstudio.com/" + language + "/centos-8/pkgs/" + strings.ToUpper(language) + "-" + version + "-1-1.x86_64.rpm",
-				Version: version,
-			}, nil
-		} else {
-			return InstallerInfo{
-				Name:    language + "-" + version + "-1-1.x86_64.rpm",
-				URL:     "https://cdn.rstudio.com/" + language + "/centos-8/pkgs/" + language + "-" + version + "-1-1.x86_64.rpm",
-				Version: version,
-			}, nil
-		}
+		return rpmInstallerInfo(language, version, "centos-8", arch), nil
 	case config.Redhat9:
-		// Redhat 9 R URL uses lowercase "r" in the beginning but then "R" in the 2nd occurance
-		if language == "r" {
-			return InstallerInfo{
-				Name:    strings.ToUpper(language) + "-" + version + "-1-1.x86_64.rpm",
-				URL:     "https://cdn.rstudio.com/" + language + "/rhel-9/pkgs/" + strings.ToUpper(language) + "-" + version + "-1-1.x86_64.rpm",
-				Version: version,
-			}, nil
-		} else {
-			return InstallerInfo{
-				Name:    language + "-" + version + "-1-1.x86_64.rpm",
-				URL:     "https://cdn.rstudio.com/" + language + "/rhel-9/pkgs/" + language + "-" + version + "-1-1.x86_64.rpm",
-				Version: version,
-			}, nil
-		}
+		return rpmInstallerInfo(language, version, "rhel-9", arch), nil
 	default:
 		return InstallerInfo{}, errors.New("operating system not supported")
 	}
 }
+
+func debInstallerInfo(language string, version string, platform string, arch config.Architecture) InstallerInfo {
+	name := language + "-" + version + "_1_" + arch.Deb() + ".deb"
+	return InstallerInfo{
+		Name:    name,
+		URL:     mirrors.URL(mirrors.RStudioCDN, "/"+language) + "/" + platform + "/pkgs/" + name,
+		Version: version,
+	}
+}
+
+func rpmInstallerInfo(language string, version string, platform string, arch config.Architecture) InstallerInfo {
+	// Redhat R URLs use lowercase "r" in the beginning but then "R" in the 2nd occurance
+	packageName := language
+	if language == "r" {
+		packageName = strings.ToUpper(language)
+	}
+	name := packageName + "-" + version + "-1-1." + arch.RPM() + ".rpm"
+	return InstallerInfo{
+		Name:    name,
+		URL:     mirrors.URL(mirrors.RStudioCDN, "/"+language) + "/" + platform + "/pkgs/" + name,
+		Version: version,
+	}
+}
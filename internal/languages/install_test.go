@@ -0,0 +1,68 @@
+package languages
+
+import (
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopulateInstallerInfo(t *testing.T) {
+	tests := map[string]struct {
+		language    string
+		osType      config.OperatingSystem
+		arch        config.Architecture
+		expectedURL string
+		expectError string
+	}{
+		"r on ubuntu 24 amd64": {
+			language:    "r",
+			osType:      config.Ubuntu24,
+			arch:        config.AMD64,
+			expectedURL: "https://cdn.rstudio.com/r/ubuntu-2404/pkgs/r-4.3.2_1_amd64.deb",
+		},
+		"r on ubuntu 22 arm64": {
+			language:    "r",
+			osType:      config.Ubuntu22,
+			arch:        config.ARM64,
+			expectedURL: "https://cdn.rstudio.com/r/ubuntu-2204/pkgs/r-4.3.2_1_arm64.deb",
+		},
+		"r on rhel 9 arm64": {
+			language:    "r",
+			osType:      config.Redhat9,
+			arch:        config.ARM64,
+			expectedURL: "https://cdn.rstudio.com/r/rhel-9/pkgs/R-4.3.2-1-1.aarch64.rpm",
+		},
+		"python on rhel 8 amd64": {
+			language:    "python",
+			osType:      config.Redhat8,
+			arch:        config.AMD64,
+			expectedURL: "https://cdn.rstudio.com/python/centos-8/pkgs/python-4.3.2-1-1.x86_64.rpm",
+		},
+		"python on rhel 7 arm64 fails": {
+			language:    "python",
+			osType:      config.Redhat7,
+			arch:        config.ARM64,
+			expectError: "Python has no arm64 build for RHEL 7",
+		},
+		"r on ubuntu 20 arm64 fails": {
+			language:    "r",
+			osType:      config.Ubuntu20,
+			arch:        config.ARM64,
+			expectError: "R has no arm64 build for Ubuntu 20",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			info, err := PopulateInstallerInfo(tc.language, "4.3.2", tc.osType, tc.arch)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedURL, info.URL)
+		})
+	}
+}
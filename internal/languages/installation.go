@@ -0,0 +1,170 @@
+package languages
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// Installation describes an installed version of R, Python or Quarto found by a scan
+type Installation struct {
+	Path    string `json:"path" yaml:"path"`
+	Version string `json:"version" yaml:"version"`
+	// Architecture is the machine the installation was built for, such as x86_64 or aarch64
+	Architecture string `json:"architecture,omitempty" yaml:"architecture,omitempty"`
+	// Source is opt for versions installed in /opt, bundled for the Quarto bundled with Workbench
+	// and system otherwise
+	Source string `json:"source" yaml:"source"`
+	// Symlinked is true when a symlink in /usr/local/bin points at this installation
+	Symlinked bool `json:"symlinked" yaml:"symlinked"`
+	// Broken is true when the installation could not be run, Error describes why
+	Broken bool   `json:"broken" yaml:"broken"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Prober runs an installation and returns the version and architecture it reports
+type Prober func(path string) (string, string, error)
+
+// symlinkDir is where the default versions of R, Python and Quarto are symlinked
+var symlinkDir = "/usr/local/bin"
+
+var (
+	// development builds report "R Under development (unstable)" instead of a version
+	rVersionPattern  = regexp.MustCompile(`R (?:version (\S+)|(Under development))`)
+	rPlatformPattern = regexp.MustCompile(`Platform: ([^-\s]+)`)
+)
+
+// ScanRInstallations scans for R versions and runs each one to find its version and architecture
+func ScanRInstallations() ([]Installation, error) {
+	rPaths, err := ScanForRVersions()
+	if err != nil {
+		return nil, err
+	}
+	return DescribeInstallations(rPaths, []string{"R"}, optSource("/opt/R/"), ProbeR), nil
+}
+
+// ScanPythonInstallations scans for Python versions and runs each one to find its version and architecture
+func ScanPythonInstallations() ([]Installation, error) {
+	pythonPaths, err := ScanForPythonVersions()
+	if err != nil {
+		return nil, err
+	}
+	return DescribeInstallations(pythonPaths, []string{"python", "python3"}, optSource("/opt/python/"), ProbePython), nil
+}
+
+// ProbeR runs R to find its version and architecture. R homes such as /usr/lib/R are run through bin/R.
+func ProbeR(path string) (string, string, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "bin", "R")
+	}
+	out, err := system.RunCommandAndCaptureOutput(path+" --version", false, 0, false)
+	if err != nil {
+		return "", "", err
+	}
+	versionMatch := rVersionPattern.FindStringSubmatch(out)
+	if versionMatch == nil {
+		return "", "", errors.New("R did not report its version")
+	}
+	installedVersion := versionMatch[1]
+	if installedVersion == "" {
+		installedVersion = "devel"
+	}
+	arch := ""
+	if platformMatch := rPlatformPattern.FindStringSubmatch(out); platformMatch != nil {
+		arch = platformMatch[1]
+	}
+	return installedVersion, arch, nil
+}
+
+// ProbePython runs Python to find its version and architecture
+func ProbePython(path string) (string, string, error) {
+	out, err := system.RunCommandAndCaptureOutput(path+` -c "import platform; print(platform.python_version()); print(platform.machine())"`, false, 0, false)
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Fields(out)
+	if len(lines) != 2 {
+		return "", "", errors.New("python did not report its version")
+	}
+	return lines[0], lines[1], nil
+}
+
+// DescribeInstallations probes each path for its version and checks whether any of the named symlinks in
+// /usr/local/bin point at it. Installations that can't be run are flagged as broken.
+func DescribeInstallations(paths []string, symlinks []string, sourceOf func(string) string, probe Prober) []Installation {
+	linkTargets := map[string]bool{}
+	for _, name := range symlinks {
+		target, err := filepath.EvalSymlinks(filepath.Join(symlinkDir, name))
+		if err == nil {
+			linkTargets[target] = true
+		}
+	}
+
+	installations := []Installation{}
+	for _, path := range paths {
+		installation := Installation{Path: path, Source: sourceOf(path)}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && linkTargets[resolved] {
+			installation.Symlinked = true
+		}
+		installedVersion, arch, err := probe(path)
+		if err != nil {
+			installation.Broken = true
+			installation.Error = err.Error()
+		} else {
+			installation.Version = installedVersion
+			installation.Architecture = arch
+		}
+		installations = append(installations, installation)
+	}
+	return installations
+}
+
+// optSource returns a function that reports installations under an /opt directory as opt and the rest as system
+func optSource(optDir string) func(string) string {
+	return func(path string) string {
+		if strings.HasPrefix(path, optDir) {
+			return "opt"
+		}
+		return "system"
+	}
+}
+
+// sortOptPaths sorts installations in an /opt directory newest first by their directory name. Directories
+// that aren't version numbers, such as /opt/R/devel, are run to find their version, and any that still
+// can't be ordered are listed last.
+func sortOptPaths(paths []string, optDir string, probe Prober) []string {
+	type optPath struct {
+		path    string
+		version *version.Version
+	}
+	var versioned []optPath
+	var unversioned []string
+	for _, path := range paths {
+		dirName, _, _ := strings.Cut(strings.TrimPrefix(path, optDir), "/")
+		parsed, err := version.NewVersion(dirName)
+		if err != nil {
+			if probedVersion, _, probeErr := probe(path); probeErr == nil {
+				parsed, err = version.NewVersion(probedVersion)
+			}
+		}
+		if err != nil {
+			unversioned = append(unversioned, path)
+			continue
+		}
+		versioned = append(versioned, optPath{path: path, version: parsed})
+	}
+
+	sort.SliceStable(versioned, func(i, j int) bool { return versioned[i].version.GreaterThan(versioned[j].version) })
+
+	sorted := []string{}
+	for _, v := range versioned {
+		sorted = append(sorted, v.path)
+	}
+	return append(sorted, unversioned...)
+}
@@ -0,0 +1,75 @@
+package languages
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProbe reports versions for known paths and fails for the rest
+func fakeProbe(versions map[string]string) Prober {
+	return func(path string) (string, string, error) {
+		if installedVersion, ok := versions[path]; ok {
+			return installedVersion, "x86_64", nil
+		}
+		return "", "", errors.New("exit status 127")
+	}
+}
+
+func TestDescribeInstallations(t *testing.T) {
+	dir := t.TempDir()
+	oldSymlinkDir := symlinkDir
+	symlinkDir = dir
+	t.Cleanup(func() { symlinkDir = oldSymlinkDir })
+
+	rPath := filepath.Join(dir, "R-4.2.3")
+	require.NoError(t, os.WriteFile(rPath, []byte{}, 0755))
+	require.NoError(t, os.Symlink(rPath, filepath.Join(dir, "R")))
+
+	probe := fakeProbe(map[string]string{rPath: "4.2.3"})
+	installations := DescribeInstallations([]string{"/opt/R/4.3.2/bin/R", rPath}, []string{"R"}, optSource("/opt/R/"), probe)
+	assert.Equal(t, []Installation{
+		{Path: "/opt/R/4.3.2/bin/R", Source: "opt", Broken: true, Error: "exit status 127"},
+		{Path: rPath, Version: "4.2.3", Architecture: "x86_64", Source: "system", Symlinked: true},
+	}, installations)
+}
+
+func TestSortOptPaths(t *testing.T) {
+	paths := []string{
+		"/opt/R/3.6.3/bin/R",
+		"/opt/R/devel/bin/R",
+		"/opt/R/custom/bin/R",
+		"/opt/R/4.3.2/bin/R",
+		"/opt/R/4.10.0/bin/R",
+	}
+	probe := fakeProbe(map[string]string{"/opt/R/custom/bin/R": "4.2.0", "/opt/R/devel/bin/R": "devel"})
+	assert.Equal(t, []string{
+		"/opt/R/4.10.0/bin/R",
+		"/opt/R/4.3.2/bin/R",
+		"/opt/R/custom/bin/R",
+		"/opt/R/3.6.3/bin/R",
+		"/opt/R/devel/bin/R",
+	}, sortOptPaths(paths, "/opt/R/", probe))
+}
+
+func TestProbeR(t *testing.T) {
+	dir := t.TempDir()
+	rPath := filepath.Join(dir, "R")
+	require.NoError(t, os.WriteFile(rPath, []byte("#!/bin/sh\necho 'R version 4.3.2 (2023-10-31) -- \"Eye Holes\"'\necho 'Platform: aarch64-unknown-linux-gnu (64-bit)'\n"), 0755))
+	installedVersion, arch, err := ProbeR(rPath)
+	require.NoError(t, err)
+	assert.Equal(t, "4.3.2", installedVersion)
+	assert.Equal(t, "aarch64", arch)
+
+	require.NoError(t, os.WriteFile(rPath, []byte("#!/bin/sh\necho 'R Under development (unstable) (2024-01-10 r85790)'\n"), 0755))
+	installedVersion, _, err = ProbeR(rPath)
+	require.NoError(t, err)
+	assert.Equal(t, "devel", installedVersion)
+
+	_, _, err = ProbeR(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -7,10 +7,33 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 )
 
 // Prompt asking users which languages they will use
 func PromptAndRespond() ([]string, error) {
+	if answers.Loaded() {
+		selected, err := answers.RequireList(answers.Get().Languages, "languages")
+		if err != nil {
+			return []string{}, err
+		}
+		// normalize to the same values the interactive prompt returns
+		var languageAnswers []string
+		for _, language := range selected {
+			switch strings.ToLower(language) {
+			case "r":
+				languageAnswers = append(languageAnswers, "R")
+			case "python":
+				languageAnswers = append(languageAnswers, "python")
+			default:
+				return []string{}, answers.InvalidError("languages", "only R and python are supported, found "+language)
+			}
+		}
+		if !lo.Contains(languageAnswers, "R") {
+			return []string{}, errors.New("R must be a select language to install Workbench")
+		}
+		return languageAnswers, nil
+	}
 	messageText := "What languages will you use"
 	var qs = []*survey.Question{
 		{
@@ -11,17 +11,25 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
+	"github.com/sol-eng/wbi/internal/bundle"
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/install"
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
+// PythonVersionsURL returns the URL that lists the versions of Python available from Posit
+func PythonVersionsURL() string {
+	return mirrors.URL(mirrors.PositCDN, "/python/versions.json")
+}
+
 type availablePythonVersions struct {
 	PythonVersions []string `json:"python_versions"`
 }
@@ -99,6 +107,9 @@ func CheckPromptAndSetPythonPATH(pythonPaths []string) error {
 
 // PythonLocationPATHPrompt asks users which Python binary they want to add to PATH
 func PythonLocationPATHPrompt(pythonPaths []string) (string, error) {
+	if answers.Loaded() {
+		return answers.RequireOneOf(answers.Get().Python.PATHDir, "python.path_dir", pythonPaths)
+	}
 	// Allow the user to select a version of Python to target
 	target := ""
 	messageText := `Please select a Python binary to add to PATH.`
@@ -211,9 +222,8 @@ func ScanAndHandlePythonVersions(osType config.OperatingSystem) error {
 func ScanForPythonVersions() ([]string, error) {
 	foundVersions := []string{}
 	foundOptVersions := []string{}
-	// This is somewhat naive with respect to actually checking whether
-	// this is _really_ a working version of Python by launching it,
-	// vs just matches the path to Python
+	// Candidates are only matched by path here, ScanPythonInstallations runs
+	// each one to check it works and find its version
 	for _, pyPath := range GetPythonPaths() {
 		if _, err := os.Stat(pyPath); err == nil {
 			foundVersions = append(foundVersions, pyPath)
@@ -244,40 +254,19 @@ func ScanForPythonVersions() ([]string, error) {
 		foundVersions = AppendIfMissing(foundVersions, maybePython)
 	}
 
-	// sort /opt/R versions
-	foundOptVersionsSortedPaths, err := sortOptPythonVersionPaths(foundOptVersions)
-	if err != nil {
-		return []string{}, fmt.Errorf("issue sorting /opt/python versions: %w", err)
-	}
+	// sort /opt/python versions
+	foundOptVersionsSortedPaths := sortOptPaths(foundOptVersions, "/opt/python/", ProbePython)
 
 	finalVersionPathSorted := append(foundOptVersionsSortedPaths, foundVersions...)
 
 	return finalVersionPathSorted, nil
 }
 
-func sortOptPythonVersionPaths(versionPaths []string) ([]string, error) {
-	foundOptVersionsOnly := []string{}
-	for _, optVersion := range versionPaths {
-		i := strings.Index(optVersion, "python")
-		j := strings.Index(optVersion, "bin")
-		foundOptVersionsOnly = append(foundOptVersionsOnly, optVersion[i+7:j-1])
-	}
-	versions, err := ConvertStringSliceToVersionSlice(foundOptVersionsOnly)
-	if err != nil {
-		return []string{}, fmt.Errorf("issue converting string slice to version slice: %w", err)
-	}
-
-	sortedVersions := SortVersionsDesc(versions)
-	foundOptVersionsSorted := ConvertVersionSliceToStringSlice(sortedVersions)
-	foundOptVersionsSortedPaths := []string{}
-	for _, optVersion := range foundOptVersionsSorted {
-		foundOptVersionsSortedPaths = append(foundOptVersionsSortedPaths, "/opt/python/"+optVersion+"/bin/python")
-	}
-	return foundOptVersionsSortedPaths, nil
-}
-
 // PythonInstallPrompt Prompt users if they would like to install Python versions
 func PythonInstallPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Python.Install, "python.install")
+	}
 	name := true
 	messageText := "Would you like to install version(s) of Python?"
 	prompt := &survey.Confirm{
@@ -294,6 +283,9 @@ func PythonInstallPrompt() (bool, error) {
 
 // PythonPATHPrompt asks users if they would like to set Python PATH
 func PythonPATHPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Python.AddToPATH, "python.add_to_path")
+	}
 	name := true
 	messageText := `Would you like to add a Python version to PATH? This is recommended so users can type "python" and "pip" in the terminal to access this specified version of python and associated tools.`
 	prompt := &survey.Confirm{
@@ -309,11 +301,9 @@ func PythonPATHPrompt() (bool, error) {
 }
 
 func RetrieveValidPythonVersions(osType config.OperatingSystem) ([]string, error) {
-	pythonVersionURL := "https://cdn.posit.co/python/versions.json"
+	pythonVersionURL := PythonVersionsURL()
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
 		http.MethodGet, pythonVersionURL, nil)
 	if err != nil {
@@ -377,6 +367,16 @@ func RetrieveValidPythonVersions(osType config.OperatingSystem) ([]string, error
 
 // PythonSelectVersionsPrompt Prompt asking users which Python version(s) they would like to install
 func PythonSelectVersionsPrompt(availablePythonVersions []string) ([]string, error) {
+	if answers.Loaded() {
+		pythonVersions, err := answers.RequireSubsetOf(answers.Get().Python.Versions, "python.versions", availablePythonVersions)
+		if err != nil {
+			return []string{}, err
+		}
+		if len(pythonVersions) == 0 {
+			return []string{}, answers.InvalidError("python.versions", "at least one version must be listed when python.install is true")
+		}
+		return pythonVersions, nil
+	}
 	messageText := "Which version(s) of Python would you like to install?"
 	var qs = []*survey.Question{
 		{
@@ -403,12 +403,12 @@ func PythonSelectVersionsPrompt(availablePythonVersions []string) ([]string, err
 // DownloadAndInstallPython Downloads the Python installer, and installs Python
 func DownloadAndInstallPython(pythonVersion string, osType config.OperatingSystem) error {
 	// Create InstallerInfoPython with the proper information
-	installerInfo, err := PopulateInstallerInfo("python", pythonVersion, osType)
+	installerInfo, err := PopulateInstallerInfo("python", pythonVersion, osType, config.HostArchitecture())
 	if err != nil {
 		return fmt.Errorf("PopulateInstallerInfoPython: %w", err)
 	}
 	// Download installer
-	installerPath, err := install.DownloadFile("Python", installerInfo.URL, installerInfo.Name)
+	installerPath, err := install.DownloadFile("Python", installerInfo.URL, installerInfo.Name, "")
 	if err != nil {
 		return fmt.Errorf("DownloadPython: %w", err)
 	}
@@ -417,6 +417,7 @@ func DownloadAndInstallPython(pythonVersion string, osType config.OperatingSyste
 	if err != nil {
 		return fmt.Errorf("InstallLanguage: %w", err)
 	}
+	install.RemoveDownload(installerPath)
 	// save to command log
 	installCommand, err := install.RetrieveInstallCommand(installerInfo.Name, osType)
 	if err != nil {
@@ -434,7 +435,7 @@ func DownloadAndInstallPython(pythonVersion string, osType config.OperatingSyste
 }
 
 func UpgradePythonTools(pythonVersion string) error {
-	upgradeCommand := "PIP_ROOT_USER_ACTION=ignore /opt/python/" + pythonVersion + "/bin/pip install --upgrade --no-warn-script-location --disable-pip-version-check pip setuptools wheel"
+	upgradeCommand := "PIP_ROOT_USER_ACTION=ignore /opt/python/" + pythonVersion + "/bin/pip install --upgrade --no-warn-script-location --disable-pip-version-check" + bundle.PipIndexArgs() + " pip setuptools wheel"
 	err := system.RunCommand(upgradeCommand, true, 2, true)
 	if err != nil {
 		return fmt.Errorf("issue upgrading pip, setuptools and wheel for Python with the command '%s': %w", upgradeCommand, err)
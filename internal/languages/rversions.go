@@ -0,0 +1,267 @@
+package languages
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+var (
+	// rVersionsPath lists the versions of R Workbench offers to users
+	rVersionsPath   = "/etc/rstudio/r-versions"
+	rserverConfPath = "/etc/rstudio/rserver.conf"
+)
+
+// RVersionsOptions controls the entries added to /etc/rstudio/r-versions. Label, Module and Script
+// may contain {version}, which is replaced with the version of R of each entry.
+type RVersionsOptions struct {
+	Label  string
+	Module string
+	Script string
+	// DisableScan sets r-versions-scan=0 so Workbench only offers the versions in r-versions
+	DisableScan bool
+	// SetDefault sets rsession-which-r to the version of R symlinked to /usr/local/bin/R
+	SetDefault bool
+}
+
+// rVersionsEntry is a block of lines in r-versions describing one version of R. Entries are separated
+// by blank lines, and the lines of entries read from an existing file are written back exactly as they were.
+type rVersionsEntry struct {
+	path  string
+	lines []string
+	// simple entries are a single path in the simple format of one path per line
+	simple bool
+}
+
+// ConfigureRVersions scans for versions of R in /opt/R and adds any that are missing to /etc/rstudio/r-versions
+func ConfigureRVersions(opts RVersionsOptions) error {
+	installations, err := ScanRInstallations()
+	if err != nil {
+		return fmt.Errorf("issue scanning for R versions: %w", err)
+	}
+	return WriteRVersionsConfig(installations, opts)
+}
+
+// WriteRVersionsConfig merges the working /opt/R installations into /etc/rstudio/r-versions. Existing entries,
+// including any edited by hand, are kept as they are and only versions that aren't listed yet are added.
+func WriteRVersionsConfig(installations []Installation, opts RVersionsOptions) error {
+	original, exists, err := system.ReadPlannedFile(rVersionsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rVersionsPath, err)
+	}
+	entries := parseRVersions(original)
+
+	// a file in the simple format can't contain labels, modules or scripts, so new entries use it too
+	simpleFormat := len(entries) > 0
+	listed := map[string]bool{}
+	for _, entry := range entries {
+		simpleFormat = simpleFormat && (entry.simple || entry.path == "")
+		if entry.path == "" {
+			continue
+		}
+		listed[entry.path] = true
+		if _, err := os.Stat(entry.path); err != nil {
+			system.PrintAndLogInfo("The R version " + entry.path + " listed in " + rVersionsPath + " does not exist, it has been kept as it may have been added by hand")
+		}
+	}
+
+	added := 0
+	for _, installation := range installations {
+		if installation.Source != "opt" || installation.Broken {
+			continue
+		}
+		rHome := rHomePath(installation.Path)
+		if listed[rHome] {
+			continue
+		}
+		listed[rHome] = true
+		if simpleFormat {
+			entries = append(entries, rVersionsEntry{path: rHome, lines: []string{rHome}, simple: true})
+		} else {
+			entries = append(entries, newRVersionsEntry(rHome, installation.Version, opts))
+		}
+		added++
+	}
+	if simpleFormat && added > 0 && (opts.Label != "" || opts.Module != "" || opts.Script != "") {
+		system.PrintAndLogInfo(rVersionsPath + " lists one path per line, so the new entries have been added without a label, module or script")
+	}
+	if added == 0 && len(listed) == 0 {
+		return errors.New("no working versions of R were found in /opt/R to add to " + rVersionsPath)
+	}
+
+	err = saveRVersions(formatRVersions(entries), original, exists)
+	if err != nil {
+		return err
+	}
+	system.PrintAndLogInfo(fmt.Sprintf("%d version(s) of R added to %s", added, rVersionsPath))
+
+	if !opts.DisableScan && !opts.SetDefault {
+		return nil
+	}
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if opts.DisableScan {
+		rserverConf.Set("r-versions-scan", "0")
+	}
+	if opts.SetDefault {
+		defaultR, err := filepath.EvalSymlinks(filepath.Join(symlinkDir, "R"))
+		if err != nil {
+			return fmt.Errorf("no version of R is symlinked to %s, use wbi install r --symlink to set one: %w", filepath.Join(symlinkDir, "R"), err)
+		}
+		rserverConf.Set("rsession-which-r", defaultR)
+	}
+	err = rserverConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// rHomePath returns the installation directory of an R binary, /opt/R/4.3.2/bin/R becomes /opt/R/4.3.2
+func rHomePath(path string) string {
+	path = strings.TrimSuffix(filepath.Clean(path), "/bin/R")
+	return strings.TrimSuffix(path, "/")
+}
+
+func newRVersionsEntry(rHome string, rVersion string, opts RVersionsOptions) rVersionsEntry {
+	entry := rVersionsEntry{path: rHome, lines: []string{"Path: " + rHome}}
+	fields := []struct {
+		name     string
+		template string
+	}{
+		{"Label", opts.Label},
+		{"Module", opts.Module},
+		{"Script", opts.Script},
+	}
+	for _, field := range fields {
+		if field.template != "" {
+			entry.lines = append(entry.lines, field.name+": "+strings.ReplaceAll(field.template, "{version}", rVersion))
+		}
+	}
+	return entry
+}
+
+// parseRVersions splits r-versions into entries. Both the extended format, where each entry is a block of
+// "Field: value" lines, and the simple format of one path per line are understood.
+func parseRVersions(content string) []rVersionsEntry {
+	var entries []rVersionsEntry
+	var current *rVersionsEntry
+	for _, raw := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			entries = append(entries, rVersionsEntry{})
+			current = &entries[len(entries)-1]
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			current.lines = append(current.lines, raw)
+			continue
+		}
+		field, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			// the simple format lists one path per line without blank lines between them
+			if current.path != "" {
+				entries = append(entries, rVersionsEntry{})
+				current = &entries[len(entries)-1]
+			}
+			current.path = rHomePath(trimmed)
+			current.simple = true
+			current.lines = append(current.lines, raw)
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(field), "Path") {
+			current.path = rHomePath(strings.TrimSpace(value))
+		}
+		current.lines = append(current.lines, raw)
+	}
+	return entries
+}
+
+// formatRVersions separates entries with blank lines, except consecutive entries in the simple format
+func formatRVersions(entries []rVersionsEntry) string {
+	var b strings.Builder
+	for i, entry := range entries {
+		if i > 0 && (!entry.simple || !entries[i-1].simple) {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.Join(entry.lines, "\n") + "\n")
+	}
+	return b.String()
+}
+
+// saveRVersions writes r-versions if it changed, an existing file is first backed up alongside the original
+func saveRVersions(content string, original string, exists bool) error {
+	return system.WriteFileWithBackup(rVersionsPath, content, original, exists, 0644)
+}
+
+// PromptAndConfigureRVersions asks users if they would like to list the versions of R in /opt/R in
+// /etc/rstudio/r-versions, and whether Workbench should only offer those versions
+func PromptAndConfigureRVersions() error {
+	configure, err := rVersionsConfirmPrompt(
+		func(a *answers.Answers) *bool { return a.RVersions.Configure },
+		"r_versions.configure",
+		"Would you like to list the versions of R installed in /opt/R in /etc/rstudio/r-versions? Existing entries are kept.",
+	)
+	if err != nil || !configure {
+		return err
+	}
+
+	var opts RVersionsOptions
+	if answers.Loaded() && answers.Get().RVersions.Label != nil {
+		opts.Label = *answers.Get().RVersions.Label
+	}
+	opts.DisableScan, err = rVersionsConfirmPrompt(
+		func(a *answers.Answers) *bool { return a.RVersions.DisableScan },
+		"r_versions.disable_scan",
+		"Would you like Workbench to only offer the versions of R listed in /etc/rstudio/r-versions (r-versions-scan=0)?",
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := filepath.EvalSymlinks(filepath.Join(symlinkDir, "R")); err == nil {
+		opts.SetDefault, err = rVersionsConfirmPrompt(
+			func(a *answers.Answers) *bool { return a.RVersions.SetDefault },
+			"r_versions.set_default",
+			"Would you like the version of R symlinked to /usr/local/bin/R to be the default for new sessions (rsession-which-r)?",
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ConfigureRVersions(opts)
+	if err != nil {
+		return fmt.Errorf("issue configuring R versions: %w", err)
+	}
+	return nil
+}
+
+func rVersionsConfirmPrompt(answer func(*answers.Answers) *bool, key string, messageText string) (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answer(answers.Get()), key)
+	}
+	name := true
+	prompt := &survey.Confirm{
+		Message: messageText,
+	}
+	err := survey.AskOne(prompt, &name)
+	if err != nil {
+		return false, errors.New("there was an issue with the R versions prompt")
+	}
+	log.Info(messageText)
+	log.Info(fmt.Sprintf("%v", name))
+	return name, nil
+}
@@ -0,0 +1,88 @@
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useRVersionsDir points r-versions, rserver.conf and the R symlink at a temporary directory
+func useRVersionsDir(t *testing.T) string {
+	dir := t.TempDir()
+	oldRVersions, oldRServerConf, oldSymlinkDir := rVersionsPath, rserverConfPath, symlinkDir
+	rVersionsPath, rserverConfPath, symlinkDir = filepath.Join(dir, "r-versions"), filepath.Join(dir, "rserver.conf"), dir
+	t.Cleanup(func() { rVersionsPath, rserverConfPath, symlinkDir = oldRVersions, oldRServerConf, oldSymlinkDir })
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestWriteRVersionsConfig(t *testing.T) {
+	dir := useRVersionsDir(t)
+	installations := []Installation{
+		{Path: "/opt/R/4.3.2/bin/R", Version: "4.3.2", Source: "opt"},
+		{Path: "/opt/R/4.2.3/bin/R", Version: "4.2.3", Source: "opt"},
+		{Path: "/opt/R/devel/bin/R", Source: "opt", Broken: true},
+		{Path: "/usr/lib/R", Version: "4.1.2", Source: "system"},
+	}
+
+	err := WriteRVersionsConfig(installations, RVersionsOptions{Label: "R {version}", Module: "R/{version}"})
+	require.NoError(t, err)
+	assert.Equal(t, "Path: /opt/R/4.3.2\nLabel: R 4.3.2\nModule: R/4.3.2\n\nPath: /opt/R/4.2.3\nLabel: R 4.2.3\nModule: R/4.2.3\n", readFile(t, rVersionsPath))
+	assert.NoFileExists(t, rserverConfPath)
+
+	// hand edits are kept and only missing versions are added
+	handEdited := "# managed by the data science team\nPath: /opt/R/4.3.2\nLabel: Production R\nRepo: https://cran.example.com\n\n/opt/R/3.6.3\n"
+	require.NoError(t, os.WriteFile(rVersionsPath, []byte(handEdited), 0644))
+	err = WriteRVersionsConfig(installations, RVersionsOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, handEdited+"\nPath: /opt/R/4.2.3\n", readFile(t, rVersionsPath))
+	backups, _ := filepath.Glob(rVersionsPath + ".bak-*")
+	assert.Len(t, backups, 1)
+
+	// set-default needs the R symlink
+	err = WriteRVersionsConfig(installations, RVersionsOptions{DisableScan: true, SetDefault: true})
+	assert.ErrorContains(t, err, "no version of R is symlinked")
+
+	rPath := filepath.Join(dir, "R-4.3.2")
+	require.NoError(t, os.WriteFile(rPath, []byte{}, 0755))
+	require.NoError(t, os.Symlink(rPath, filepath.Join(dir, "R")))
+	err = WriteRVersionsConfig(installations, RVersionsOptions{DisableScan: true, SetDefault: true})
+	require.NoError(t, err)
+	assert.Equal(t, "r-versions-scan=0\nrsession-which-r="+rPath+"\n", readFile(t, rserverConfPath))
+}
+
+func TestWriteRVersionsConfigWithoutR(t *testing.T) {
+	useRVersionsDir(t)
+	err := WriteRVersionsConfig([]Installation{{Path: "/usr/lib/R", Version: "4.1.2", Source: "system"}}, RVersionsOptions{})
+	assert.ErrorContains(t, err, "no working versions of R were found in /opt/R")
+	assert.NoFileExists(t, rVersionsPath)
+}
+
+func TestWriteRVersionsConfigSimpleFormat(t *testing.T) {
+	useRVersionsDir(t)
+	require.NoError(t, os.WriteFile(rVersionsPath, []byte("/opt/R/4.3.2\n/opt/R/3.6.3\n"), 0644))
+	installations := []Installation{
+		{Path: "/opt/R/4.3.2/bin/R", Version: "4.3.2", Source: "opt"},
+		{Path: "/opt/R/4.2.3/bin/R", Version: "4.2.3", Source: "opt"},
+	}
+	err := WriteRVersionsConfig(installations, RVersionsOptions{Label: "R {version}"})
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/R/4.3.2\n/opt/R/3.6.3\n/opt/R/4.2.3\n", readFile(t, rVersionsPath))
+}
+
+func TestParseRVersions(t *testing.T) {
+	entries := parseRVersions("/opt/R/4.3.2\n/opt/R/4.2.3/bin/R\n\nPath: /opt/R/4.1.3/\nLabel: Old R\n")
+	require.Len(t, entries, 3)
+	assert.Equal(t, "/opt/R/4.3.2", entries[0].path)
+	assert.Equal(t, "/opt/R/4.2.3", entries[1].path)
+	assert.Equal(t, "/opt/R/4.1.3", entries[2].path)
+	assert.Equal(t, []string{"Path: /opt/R/4.1.3/", "Label: Old R"}, entries[2].lines)
+}
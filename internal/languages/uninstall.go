@@ -0,0 +1,171 @@
+package languages
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/install"
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/sol-eng/wbi/internal/workbench"
+)
+
+const pythonProfileDPath = "/etc/profile.d/wbi_python.sh"
+
+// rInstallDir and pythonInstallDir are where each version of R and Python is installed
+var (
+	rInstallDir      = "/opt/R"
+	pythonInstallDir = "/opt/python"
+)
+
+// UninstallR removes a version of R installed in /opt/R and repairs any R and Rscript symlinks pointing at it
+func UninstallR(rVersion string, osType config.OperatingSystem) error {
+	rDir, err := system.VersionDir(rInstallDir, rVersion, filepath.Join("bin", "R"))
+	if err != nil {
+		return fmt.Errorf("issue finding R version %s: %w", rVersion, err)
+	}
+
+	err = removeLanguagePackage("r", rVersion, osType)
+	if err != nil {
+		return fmt.Errorf("issue removing R version %s: %w", rVersion, err)
+	}
+
+	// point the symlinks at the newest remaining version of R in /opt/R if there is one
+	rPaths, err := ScanForRVersions()
+	if err != nil {
+		return fmt.Errorf("issue scanning for R versions: %w", err)
+	}
+	rReplacement := newestRemainingOptPath(rPaths, "/opt/R/", rDir)
+	rScriptReplacement := ""
+	if rReplacement != "" {
+		rScriptReplacement = rReplacement + "script"
+	}
+
+	err = system.RepairSymlink("/usr/local/bin/R", rDir, rReplacement)
+	if err != nil {
+		return fmt.Errorf("issue repairing the R symlink: %w", err)
+	}
+	err = system.RepairSymlink("/usr/local/bin/Rscript", rDir, rScriptReplacement)
+	if err != nil {
+		return fmt.Errorf("issue repairing the Rscript symlink: %w", err)
+	}
+
+	system.PrintAndLogInfo("\nR version " + rVersion + " successfully uninstalled!\n")
+	return nil
+}
+
+// UninstallPython removes a version of Python installed in /opt/python and repairs /etc/profile.d/wbi_python.sh.
+// The version Jupyter is configured with in jupyter.conf is only removed when force is true.
+func UninstallPython(pythonVersion string, osType config.OperatingSystem, force bool) error {
+	pythonDir, err := system.VersionDir(pythonInstallDir, pythonVersion, filepath.Join("bin", "python3"))
+	if err != nil {
+		return fmt.Errorf("issue finding Python version %s: %w", pythonVersion, err)
+	}
+
+	jupyterPath, err := workbench.ReadJupyterConfig()
+	if err != nil {
+		return fmt.Errorf("issue reading the Jupyter configuration: %w", err)
+	}
+	if strings.HasPrefix(jupyterPath, pythonDir+"/") {
+		if !force {
+			return fmt.Errorf("Python version %s is used by Jupyter (jupyter-exe=%s in /etc/rstudio/jupyter.conf). Configure Jupyter to use another version of Python, or use the force flag to remove it anyway", pythonVersion, jupyterPath)
+		}
+		system.PrintAndLogInfo("\nPython version " + pythonVersion + " is used by Jupyter (jupyter-exe=" + jupyterPath + " in /etc/rstudio/jupyter.conf), Jupyter sessions will not start until it is configured with another version of Python")
+	}
+
+	err = removeLanguagePackage("python", pythonVersion, osType)
+	if err != nil {
+		return fmt.Errorf("issue removing Python version %s: %w", pythonVersion, err)
+	}
+
+	// add the newest remaining version of Python in /opt/python to PATH if there is one
+	pythonPaths, err := ScanForPythonVersions()
+	if err != nil {
+		return fmt.Errorf("issue scanning for Python versions: %w", err)
+	}
+	pythonReplacement := newestRemainingOptPath(pythonPaths, "/opt/python/", pythonDir)
+	if pythonReplacement != "" {
+		pythonReplacement = filepath.Dir(pythonReplacement)
+	}
+
+	err = repairPythonProfileD(pythonDir, pythonReplacement)
+	if err != nil {
+		return fmt.Errorf("issue repairing %s: %w", pythonProfileDPath, err)
+	}
+
+	system.PrintAndLogInfo("\nPython version " + pythonVersion + " successfully uninstalled!\n")
+	return nil
+}
+
+// removeLanguagePackage removes the deb or rpm package that installed R/Python
+func removeLanguagePackage(language string, version string, osType config.OperatingSystem) error {
+	packageName := language + "-" + version
+	// the R rpm packages are named with an uppercase "R"
+	if language == "r" && (osType == config.Redhat7 || osType == config.Redhat8 || osType == config.Redhat9) {
+		packageName = "R-" + version
+	}
+
+	removeCommand, err := install.RetrieveRemoveCommand(packageName, osType)
+	if err != nil {
+		return fmt.Errorf("RetrieveRemoveCommand: %w", err)
+	}
+	err = system.RunCommand(removeCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("the command '%s' failed to run: %w", removeCommand, err)
+	}
+	return nil
+}
+
+// newestRemainingOptPath returns the first path under the root directory that isn't in the removed directory,
+// the scanned paths are sorted newest first
+func newestRemainingOptPath(paths []string, rootDir string, removedDir string) string {
+	for _, path := range paths {
+		if strings.HasPrefix(path, rootDir) && !strings.HasPrefix(path, removedDir+"/") {
+			return path
+		}
+	}
+	return ""
+}
+
+// repairPythonProfileD removes PATH entries for a removed version of Python from /etc/profile.d/wbi_python.sh
+// and adds the replacement bin directory if no entries remain
+func repairPythonProfileD(removedDir string, replacementDir string) error {
+	content, exists, err := system.ReadPlannedFile(pythonProfileDPath)
+	if err != nil {
+		return err
+	}
+	if !exists || !strings.Contains(content, removedDir+"/") {
+		return nil
+	}
+
+	system.PrintAndLogInfo("\n" + pythonProfileDPath + " adds " + removedDir + " to PATH which has been removed")
+	err = system.DeleteStrings([]string{removedDir + "/"}, pythonProfileDPath, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from PATH: %w", removedDir, err)
+	}
+
+	content, _, err = system.ReadPlannedFile(pythonProfileDPath)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(content, "PATH=") {
+		return nil
+	}
+
+	if replacementDir != "" {
+		err = system.AddToPATH(replacementDir, "python")
+		if err != nil {
+			return fmt.Errorf("issue adding Python binary to PATH: %w", err)
+		}
+		system.PrintAndLogInfo(replacementDir + " has been added to PATH in " + pythonProfileDPath)
+		return nil
+	}
+
+	removeCommand := "rm -f " + pythonProfileDPath
+	err = system.RunCommand(removeCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("error removing %s with the command '%s': %w", pythonProfileDPath, removeCommand, err)
+	}
+	return nil
+}
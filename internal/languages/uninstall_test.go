@@ -0,0 +1,39 @@
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUninstallRejectsInvalidVersions(t *testing.T) {
+	root := t.TempDir()
+	originalR, originalPython := rInstallDir, pythonInstallDir
+	rInstallDir, pythonInstallDir = filepath.Join(root, "R"), filepath.Join(root, "python")
+	t.Cleanup(func() { rInstallDir, pythonInstallDir = originalR, originalPython })
+	// installs without their binaries aren't removed
+	require.NoError(t, os.MkdirAll(filepath.Join(rInstallDir, "4.2.3"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(pythonInstallDir, "3.11.4"), 0755))
+
+	tests := map[string]struct {
+		version     string
+		expectError string
+	}{
+		"parent":         {version: "..", expectError: "versions can't be empty or contain / or .."},
+		"escape":         {version: "../../etc", expectError: "versions can't be empty or contain / or .."},
+		"root":           {version: ".", expectError: "it is not a directory in"},
+		"missing binary": {version: "4.2.3", expectError: "version 4.2.3 is not installed"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, UninstallR(tc.version, config.Ubuntu22), tc.expectError)
+		})
+	}
+
+	assert.ErrorContains(t, UninstallPython("3.11.4", config.Ubuntu22, false), "version 3.11.4 is not installed")
+	assert.ErrorContains(t, UninstallPython("../R", config.Ubuntu22, false), "versions can't be empty or contain / or ..")
+}
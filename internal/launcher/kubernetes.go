@@ -0,0 +1,392 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/system"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultKubernetesNamespace is where sessions run when no namespace is provided
+const DefaultKubernetesNamespace = "rstudio"
+
+var (
+	launcherKubernetesConfPath     = "/etc/rstudio/launcher.kubernetes.conf"
+	launcherKubernetesProfilesPath = "/etc/rstudio/launcher.kubernetes.profiles.conf"
+)
+
+// KubernetesOptions describes the Kubernetes cluster the Launcher starts sessions and jobs in
+type KubernetesOptions struct {
+	APIURL    string
+	Namespace string
+	// Token authenticates the Launcher's service account
+	Token string
+	// Kubeconfig provides the API URL, token, namespace and certificate authority not set directly, from its current context
+	Kubeconfig string
+	// CACert is the PEM encoded certificate authority of the API server, the system trust store is used when empty
+	CACert []byte
+	// InsecureSkipVerify turns off verification of the API server's certificate
+	InsecureSkipVerify bool
+	// CallbackAddress is the URL sessions reach Workbench at
+	CallbackAddress string
+	Profiles        []Profile
+}
+
+// kubeconfig is the part of a kubeconfig file needed to reach the API with a token
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token     string `yaml:"token"`
+			TokenFile string `yaml:"tokenFile"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// kubernetesStatus is the error body returned by the Kubernetes API
+type kubernetesStatus struct {
+	Message string `json:"message"`
+}
+
+// ConfigureKubernetes checks the Launcher can start pods through the Kubernetes API, then writes
+// launcher.kubernetes.conf, the Kubernetes cluster in launcher.conf, the session profiles and the Launcher settings
+// in rserver.conf
+func ConfigureKubernetes(opts KubernetesOptions) error {
+	opts, err := resolveKubernetesOptions(opts)
+	if err != nil {
+		return err
+	}
+	err = ValidateKubernetesAPI(opts)
+	if err != nil {
+		return err
+	}
+
+	kubernetesConf, err := conffile.Load(launcherKubernetesConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	kubernetesConf.Set("api-url", opts.APIURL)
+	kubernetesConf.Set("auth-token", opts.Token)
+	kubernetesConf.Set("kubernetes-namespace", opts.Namespace)
+	if opts.InsecureSkipVerify {
+		kubernetesConf.Set("verify-ssl-certs", "0")
+	} else {
+		kubernetesConf.Set("verify-ssl-certs", "1")
+	}
+	if len(opts.CACert) > 0 {
+		kubernetesConf.Set("certificate-authority", base64.StdEncoding.EncodeToString(opts.CACert))
+	} else {
+		kubernetesConf.Delete("certificate-authority")
+	}
+	err = kubernetesConf.SaveSecret(0600, "auth-token")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	err = writeClusterConfig("Kubernetes", "Kubernetes")
+	if err != nil {
+		return err
+	}
+	if len(opts.Profiles) > 0 {
+		err = writeProfiles(launcherKubernetesProfilesPath, opts.Profiles)
+		if err != nil {
+			return err
+		}
+	}
+	err = enableLauncherSessions("Kubernetes", opts.CallbackAddress)
+	if err != nil {
+		return err
+	}
+
+	printNextSteps("Kubernetes")
+	return nil
+}
+
+// resolveKubernetesOptions fills in anything not set directly from the kubeconfig and applies the defaults
+func resolveKubernetesOptions(opts KubernetesOptions) (KubernetesOptions, error) {
+	if opts.Kubeconfig != "" {
+		var err error
+		opts, err = readKubeconfig(opts)
+		if err != nil {
+			return opts, err
+		}
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultKubernetesNamespace
+	}
+	if opts.APIURL == "" || opts.Token == "" {
+		return opts, errors.New("the Kubernetes API URL and a service account token are required, provide them directly or through a kubeconfig")
+	}
+	parsed, err := url.Parse(opts.APIURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return opts, fmt.Errorf("invalid Kubernetes API URL %s, it must start with https://", opts.APIURL)
+	}
+	opts.APIURL = strings.TrimSuffix(opts.APIURL, "/")
+	return opts, nil
+}
+
+// readKubeconfig reads the cluster, user and namespace of the kubeconfig's current context
+func readKubeconfig(opts KubernetesOptions) (KubernetesOptions, error) {
+	content, err := os.ReadFile(opts.Kubeconfig)
+	if err != nil {
+		return opts, fmt.Errorf("failed to read the kubeconfig %s: %w", opts.Kubeconfig, err)
+	}
+	var config kubeconfig
+	err = yaml.Unmarshal(content, &config)
+	if err != nil {
+		return opts, fmt.Errorf("the kubeconfig %s is not valid YAML: %w", opts.Kubeconfig, err)
+	}
+
+	contextName := config.CurrentContext
+	if contextName == "" && len(config.Contexts) == 1 {
+		contextName = config.Contexts[0].Name
+	}
+	found := false
+	var clusterName, userName string
+	for _, c := range config.Contexts {
+		if c.Name == contextName {
+			found = true
+			clusterName, userName = c.Context.Cluster, c.Context.User
+			if opts.Namespace == "" {
+				opts.Namespace = c.Context.Namespace
+			}
+		}
+	}
+	if !found {
+		return opts, fmt.Errorf("the kubeconfig %s has no current context", opts.Kubeconfig)
+	}
+
+	// relative paths in a kubeconfig are relative to the kubeconfig
+	kubeconfigPath := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(filepath.Dir(opts.Kubeconfig), path)
+	}
+	for _, c := range config.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		if opts.APIURL == "" {
+			opts.APIURL = c.Cluster.Server
+		}
+		opts.InsecureSkipVerify = opts.InsecureSkipVerify || c.Cluster.InsecureSkipTLSVerify
+		if len(opts.CACert) == 0 && c.Cluster.CertificateAuthorityData != "" {
+			opts.CACert, err = base64.StdEncoding.DecodeString(c.Cluster.CertificateAuthorityData)
+			if err != nil {
+				return opts, fmt.Errorf("the certificate-authority-data of the cluster %s in %s is not valid base64: %w", clusterName, opts.Kubeconfig, err)
+			}
+		} else if len(opts.CACert) == 0 && c.Cluster.CertificateAuthority != "" {
+			opts.CACert, err = os.ReadFile(kubeconfigPath(c.Cluster.CertificateAuthority))
+			if err != nil {
+				return opts, fmt.Errorf("failed to read the certificate authority of the cluster %s: %w", clusterName, err)
+			}
+		}
+	}
+	for _, u := range config.Users {
+		if u.Name != userName || opts.Token != "" {
+			continue
+		}
+		opts.Token = u.User.Token
+		if opts.Token == "" && u.User.TokenFile != "" {
+			token, err := os.ReadFile(kubeconfigPath(u.User.TokenFile))
+			if err != nil {
+				return opts, fmt.Errorf("failed to read the token of the user %s: %w", userName, err)
+			}
+			opts.Token = strings.TrimSpace(string(token))
+		}
+		if opts.Token == "" {
+			return opts, fmt.Errorf("the user %s in %s does not use a token, the Launcher authenticates to Kubernetes with a service account token", userName, opts.Kubeconfig)
+		}
+	}
+	return opts, nil
+}
+
+// ValidateKubernetesAPI asks the API whether the token may create pods in the namespace with a SelfSubjectAccessReview,
+// then creates a pod with a server side dry run that creates nothing. Only the namespaced permissions sessions and jobs
+// need are checked. Admission policies rejecting the dry run pod are reported without failing the check, since sessions
+// run other images with other settings.
+func ValidateKubernetesAPI(opts KubernetesOptions) error {
+	opts, err := resolveKubernetesOptions(opts)
+	if err != nil {
+		return err
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: opts.InsecureSkipVerify} //nolint:all
+	if len(opts.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(opts.CACert) {
+			return errors.New("the Kubernetes certificate authority does not contain any PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := httpclient.NewWithTLS(tlsConfig)
+	system.PrintAndLogInfo("\nChecking the Kubernetes API at " + opts.APIURL + " can run sessions in the namespace " + opts.Namespace)
+
+	err = checkCreatePodsAccess(client, opts)
+	if err != nil {
+		return err
+	}
+
+	pod := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]string{"generateName": "wbi-launcher-check-"},
+		"spec": map[string]interface{}{
+			"containers": []map[string]string{{"name": "check", "image": "busybox"}},
+		},
+	}
+	body, err := json.Marshal(pod)
+	if err != nil {
+		return fmt.Errorf("failed to create the dry run pod: %w", err)
+	}
+	podsURL := opts.APIURL + "/api/v1/namespaces/" + url.PathEscape(opts.Namespace) + "/pods?dryRun=All"
+	_, err = kubernetesRequest(client, http.MethodPost, podsURL, opts.Token, body)
+	var apiErr *kubernetesAPIError
+	if errors.As(err, &apiErr) && apiErr.admissionRejected() {
+		system.PrintAndLogInfo("The service account may create pods in the namespace " + opts.Namespace + ", but an admission policy rejected " +
+			"the dry run pod, which runs the busybox image as its default user. Check the session images and profiles meet the cluster's policies: " + apiErr.Error())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("issue creating a pod in the namespace %s with a dry run, the Launcher needs to create pods to start sessions: %w", opts.Namespace, err)
+	}
+	system.PrintAndLogInfo("The Launcher can run sessions in the Kubernetes namespace " + opts.Namespace)
+	return nil
+}
+
+// checkCreatePodsAccess asks the Kubernetes API whether the token may create pods in the namespace. Clusters that
+// don't allow access reviews are left to the dry run.
+func checkCreatePodsAccess(client *http.Client, opts KubernetesOptions) error {
+	review := map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SelfSubjectAccessReview",
+		"spec": map[string]interface{}{
+			"resourceAttributes": map[string]string{"namespace": opts.Namespace, "verb": "create", "resource": "pods"},
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return fmt.Errorf("failed to create the access review: %w", err)
+	}
+	content, err := kubernetesRequest(client, http.MethodPost, opts.APIURL+"/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", opts.Token, body)
+	var apiErr *kubernetesAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode != http.StatusUnauthorized {
+		log.Info("The Kubernetes API did not review the access of the service account, relying on the dry run: " + err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Status struct {
+			Allowed bool   `json:"allowed"`
+			Reason  string `json:"reason"`
+		} `json:"status"`
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return fmt.Errorf("failed to parse the access review: %w", err)
+	}
+	if !result.Status.Allowed {
+		reason := ""
+		if result.Status.Reason != "" {
+			reason = ": " + result.Status.Reason
+		}
+		return fmt.Errorf("the service account is not allowed to create pods in the namespace %s, the Launcher needs a Role binding that allows it to start sessions%s", opts.Namespace, reason)
+	}
+	return nil
+}
+
+// kubernetesAPIError is an error response of the Kubernetes API
+type kubernetesAPIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *kubernetesAPIError) Error() string {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return "the Kubernetes API rejected the token (401 Unauthorized)"
+	case http.StatusForbidden:
+		return "the service account is not allowed to do this (403 Forbidden): " + e.Message
+	case http.StatusNotFound:
+		return "not found (404), create the namespace with 'kubectl create namespace': " + e.Message
+	}
+	return "the Kubernetes API returned " + e.Status + ": " + e.Message
+}
+
+// admissionRejected returns true when an admission controller, such as Pod Security admission, a webhook or a
+// validating admission policy, rejected a request RBAC allowed. RBAC denials always say which user cannot do what.
+func (e *kubernetesAPIError) admissionRejected() bool {
+	switch e.StatusCode {
+	case http.StatusForbidden:
+		return !strings.Contains(e.Message, "cannot create resource")
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+func kubernetesRequest(client *http.Client, method string, requestURL string, token string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", requestURL, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the Kubernetes API: %w", err)
+	}
+	defer res.Body.Close()
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of the Kubernetes API: %w", err)
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return content, nil
+	}
+
+	var status kubernetesStatus
+	json.Unmarshal(content, &status)
+	return nil, &kubernetesAPIError{StatusCode: res.StatusCode, Status: res.Status, Message: status.Message}
+}
@@ -0,0 +1,263 @@
+package launcher
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "service-account-token"
+
+// newFakeKubernetesAPI serves the parts of the Kubernetes API the Launcher check uses. The token may create pods in the
+// rstudio namespace with a dry run, may not create pods in readonly, and may create pods in restricted where an
+// admission policy rejects them. Access reviews are forbidden for the legacy namespace.
+func newFakeKubernetesAPI(t *testing.T) (*httptest.Server, []byte) {
+	mux := http.NewServeMux()
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"kind":"Status","message":"Unauthorized"}`))
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		var review struct {
+			Spec struct {
+				ResourceAttributes struct {
+					Namespace string `json:"namespace"`
+				} `json:"resourceAttributes"`
+			} `json:"spec"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		switch review.Spec.ResourceAttributes.Namespace {
+		case "legacy":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","message":"selfsubjectaccessreviews.authorization.k8s.io is forbidden"}`))
+		case "readonly":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"kind":"SelfSubjectAccessReview","status":{"allowed":false}}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"kind":"SelfSubjectAccessReview","status":{"allowed":true,"reason":"RBAC: allowed by RoleBinding \"launcher\""}}`))
+		}
+	})
+	dryRunPods := func(w http.ResponseWriter, r *http.Request) bool {
+		if !authorized(w, r) {
+			return false
+		}
+		if r.Method != http.MethodPost || r.URL.Query().Get("dryRun") != "All" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","message":"only dry runs are allowed"}`))
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("/api/v1/namespaces/rstudio/pods", func(w http.ResponseWriter, r *http.Request) {
+		if dryRunPods(w, r) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"kind":"Pod"}`))
+		}
+	})
+	mux.HandleFunc("/api/v1/namespaces/restricted/pods", func(w http.ResponseWriter, r *http.Request) {
+		if dryRunPods(w, r) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","message":"pods \"wbi-launcher-check-x\" is forbidden: violates PodSecurity \"restricted:latest\": runAsNonRoot != true"}`))
+		}
+	})
+	forbiddenPods := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"kind":"Status","message":"pods is forbidden: User \"system:serviceaccount:readonly:launcher\" cannot create resource \"pods\""}`))
+	}
+	mux.HandleFunc("/api/v1/namespaces/readonly/pods", forbiddenPods)
+	mux.HandleFunc("/api/v1/namespaces/legacy/pods", forbiddenPods)
+	mux.HandleFunc("/api/v1/namespaces/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"kind":"Status","message":"namespaces \"missing\" not found"}`))
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, caCert
+}
+
+// useTestLauncherConf points the Launcher and Workbench config files at a temporary directory
+func useTestLauncherConf(t *testing.T) string {
+	dir := t.TempDir()
+	originalLauncher, originalRserver := launcherConfPath, rserverConfPath
+	originalKubernetes, originalProfiles := launcherKubernetesConfPath, launcherKubernetesProfilesPath
+	originalSlurm, originalSlurmProfiles := launcherSlurmConfPath, launcherSlurmProfilesPath
+	launcherConfPath = filepath.Join(dir, "launcher.conf")
+	rserverConfPath = filepath.Join(dir, "rserver.conf")
+	launcherKubernetesConfPath = filepath.Join(dir, "launcher.kubernetes.conf")
+	launcherKubernetesProfilesPath = filepath.Join(dir, "launcher.kubernetes.profiles.conf")
+	launcherSlurmConfPath = filepath.Join(dir, "launcher.slurm.conf")
+	launcherSlurmProfilesPath = filepath.Join(dir, "launcher.slurm.profiles.conf")
+	t.Cleanup(func() {
+		launcherConfPath, rserverConfPath = originalLauncher, originalRserver
+		launcherKubernetesConfPath, launcherKubernetesProfilesPath = originalKubernetes, originalProfiles
+		launcherSlurmConfPath, launcherSlurmProfilesPath = originalSlurm, originalSlurmProfiles
+	})
+	return dir
+}
+
+func TestValidateKubernetesAPI(t *testing.T) {
+	server, caCert := newFakeKubernetesAPI(t)
+
+	tests := map[string]struct {
+		opts        KubernetesOptions
+		expectError string
+	}{
+		"access review and dry run pod": {
+			opts: KubernetesOptions{APIURL: server.URL, Token: testToken, CACert: caCert},
+		},
+		"insecure without the certificate authority": {
+			opts: KubernetesOptions{APIURL: server.URL, Token: testToken, InsecureSkipVerify: true},
+		},
+		"untrusted certificate": {
+			opts:        KubernetesOptions{APIURL: server.URL, Token: testToken},
+			expectError: "failed to reach the Kubernetes API",
+		},
+		"invalid token": {
+			opts:        KubernetesOptions{APIURL: server.URL, Token: "wrong", CACert: caCert},
+			expectError: "the Kubernetes API rejected the token (401 Unauthorized)",
+		},
+		"missing namespace": {
+			opts:        KubernetesOptions{APIURL: server.URL, Token: testToken, CACert: caCert, Namespace: "missing"},
+			expectError: "not found (404), create the namespace",
+		},
+		"pods forbidden by RBAC": {
+			opts:        KubernetesOptions{APIURL: server.URL, Token: testToken, CACert: caCert, Namespace: "readonly"},
+			expectError: "the service account is not allowed to create pods in the namespace readonly",
+		},
+		"pods forbidden by RBAC without access reviews": {
+			opts:        KubernetesOptions{APIURL: server.URL, Token: testToken, CACert: caCert, Namespace: "legacy"},
+			expectError: "cannot create resource \"pods\"",
+		},
+		"dry run pod rejected by an admission policy": {
+			opts: KubernetesOptions{APIURL: server.URL, Token: testToken, CACert: caCert, Namespace: "restricted"},
+		},
+		"http API URL": {
+			opts:        KubernetesOptions{APIURL: "http://kubernetes.example.com", Token: testToken},
+			expectError: "it must start with https://",
+		},
+		"no token": {
+			opts:        KubernetesOptions{APIURL: server.URL},
+			expectError: "a service account token are required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateKubernetesAPI(tc.opts)
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestReadKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte(testToken+"\n"), 0600))
+	kubeconfigPath := filepath.Join(dir, "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+current-context: launcher
+contexts:
+- name: admin
+  context: {cluster: prod, user: admin}
+- name: launcher
+  context: {cluster: prod, user: launcher, namespace: sessions}
+clusters:
+- name: prod
+  cluster:
+    server: https://kubernetes.example.com:6443
+    certificate-authority-data: `+base64.StdEncoding.EncodeToString([]byte("CA"))+`
+users:
+- name: admin
+  user: {client-certificate-data: abc, client-key-data: def}
+- name: launcher
+  user: {tokenFile: token}
+`), 0600))
+
+	opts, err := readKubeconfig(KubernetesOptions{Kubeconfig: kubeconfigPath})
+	require.NoError(t, err)
+	assert.Equal(t, "https://kubernetes.example.com:6443", opts.APIURL)
+	assert.Equal(t, testToken, opts.Token)
+	assert.Equal(t, "sessions", opts.Namespace)
+	assert.Equal(t, []byte("CA"), opts.CACert)
+
+	// flags take precedence over the kubeconfig
+	opts, err = readKubeconfig(KubernetesOptions{Kubeconfig: kubeconfigPath, Namespace: "rstudio", Token: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, "rstudio", opts.Namespace)
+	assert.Equal(t, "other", opts.Token)
+
+	// client certificates can't be used by the Launcher
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(
+		`current-context: admin
+contexts:
+- name: admin
+  context: {cluster: prod, user: admin}
+clusters:
+- name: prod
+  cluster: {server: "https://kubernetes.example.com:6443"}
+users:
+- name: admin
+  user: {client-certificate-data: abc, client-key-data: def}
+`), 0600))
+	_, err = readKubeconfig(KubernetesOptions{Kubeconfig: kubeconfigPath})
+	assert.ErrorContains(t, err, "does not use a token")
+}
+
+func TestConfigureKubernetes(t *testing.T) {
+	server, caCert := newFakeKubernetesAPI(t)
+	dir := useTestLauncherConf(t)
+	require.NoError(t, os.WriteFile(launcherConfPath, []byte("[server]\naddress=127.0.0.1\nport=5559\n\n[cluster]\nname=Local\ntype=Local\n"), 0644))
+
+	profile, err := ParseProfile("@data-science:max-cpus=4,max-mem-mb=8192,image=rstudio/r-session-complete:jammy,image=rstudio/r-session-complete:noble")
+	require.NoError(t, err)
+	opts := KubernetesOptions{APIURL: server.URL + "/", Token: testToken, CACert: caCert, CallbackAddress: "https://workbench.example.com/", Profiles: []Profile{profile}}
+	require.NoError(t, ConfigureKubernetes(opts))
+
+	kubernetesConf, err := os.ReadFile(launcherKubernetesConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "api-url="+server.URL+"\nauth-token="+testToken+"\nkubernetes-namespace=rstudio\nverify-ssl-certs=1\ncertificate-authority="+base64.StdEncoding.EncodeToString(caCert)+"\n", string(kubernetesConf))
+	info, err := os.Stat(launcherKubernetesConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	launcherConf, err := os.ReadFile(launcherConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[server]\naddress=127.0.0.1\nport=5559\n\n[cluster]\nname=Local\ntype=Local\n\n[cluster]\nname=Kubernetes\ntype=Kubernetes\n", string(launcherConf))
+
+	profiles, err := os.ReadFile(filepath.Join(dir, "launcher.kubernetes.profiles.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "[@data-science]\nmax-cpus=4\nmax-mem-mb=8192\ncontainer-images=rstudio/r-session-complete:jammy,rstudio/r-session-complete:noble\n"+
+		"default-container-image=rstudio/r-session-complete:jammy\nallow-unknown-images=0\n", string(profiles))
+
+	rserverConf, err := os.ReadFile(rserverConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "launcher-sessions-callback-address=https://workbench.example.com\nlauncher-sessions-enabled=1\nlauncher-address=127.0.0.1\nlauncher-port=5559\nlauncher-default-cluster=Kubernetes\n", string(rserverConf))
+
+	// a failed check leaves the configuration unchanged
+	opts.Token = "wrong"
+	assert.Error(t, ConfigureKubernetes(opts))
+	unchanged, err := os.ReadFile(launcherKubernetesConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, kubernetesConf, unchanged)
+}
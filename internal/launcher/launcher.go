@@ -0,0 +1,104 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// Launcher plugins configured by wbi config launcher
+const (
+	PluginKubernetes = "kubernetes"
+	PluginSlurm      = "slurm"
+)
+
+// Plugins lists the valid Launcher plugins
+var Plugins = []string{PluginKubernetes, PluginSlurm}
+
+var (
+	launcherConfPath = "/etc/rstudio/launcher.conf"
+	rserverConfPath  = "/etc/rstudio/rserver.conf"
+)
+
+// serverDefaults are the [server] settings of the launcher.conf installed with Workbench, they are only added when
+// launcher.conf has no [server] section
+var serverDefaults = [][2]string{
+	{"address", "127.0.0.1"},
+	{"port", "5559"},
+	{"server-user", "rstudio-server"},
+	{"admin-group", "rstudio-server"},
+	{"authorization-enabled", "1"},
+	{"thread-pool-size", "4"},
+}
+
+// writeClusterConfig adds a [cluster] section for the plugin to launcher.conf, or updates the existing one, keeping
+// any other clusters such as Local
+func writeClusterConfig(name string, clusterType string) error {
+	launcherConf, err := conffile.Load(launcherConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(launcherConf.Sections("server")) == 0 {
+		server := launcherConf.Section("server")
+		for _, setting := range serverDefaults {
+			server.Set(setting[0], setting[1])
+		}
+	}
+
+	var cluster *conffile.Section
+	for _, section := range launcherConf.Sections("cluster") {
+		if value, _ := section.Get("type"); strings.EqualFold(value, clusterType) {
+			cluster = section
+			break
+		}
+	}
+	if cluster == nil {
+		cluster = launcherConf.AddSection("cluster")
+	}
+	cluster.Set("name", name)
+	cluster.Set("type", clusterType)
+
+	err = launcherConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// enableLauncherSessions points Workbench at the Launcher in rserver.conf and starts new sessions on the cluster by
+// default. Sessions connect back to Workbench at the callback address, which is kept when none is provided.
+func enableLauncherSessions(defaultCluster string, callbackAddress string) error {
+	launcherConf, err := conffile.Load(launcherConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	address, _ := launcherConf.Section("server").Get("address")
+	port, _ := launcherConf.Section("server").Get("port")
+
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if callbackAddress != "" {
+		rserverConf.Set("launcher-sessions-callback-address", strings.TrimSuffix(callbackAddress, "/"))
+	} else if existing, _ := rserverConf.Get("launcher-sessions-callback-address"); existing == "" {
+		return errors.New("launcher-sessions-callback-address is not set in " + rserverConfPath + ", provide the URL sessions reach Workbench at with the callback-address flag")
+	}
+	rserverConf.Set("launcher-sessions-enabled", "1")
+	rserverConf.Set("launcher-address", address)
+	rserverConf.Set("launcher-port", port)
+	rserverConf.Set("launcher-default-cluster", defaultCluster)
+
+	err = rserverConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+func printNextSteps(plugin string) {
+	system.PrintAndLogInfo("\nThe " + plugin + " Launcher plugin has been configured. Restart the Launcher and Workbench with 'rstudio-launcher restart' and 'rstudio-server restart' to apply the change.")
+}
@@ -0,0 +1,127 @@
+package launcher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+)
+
+// Profile sets the default and maximum resources, and the images, of sessions for everyone (*), a group (@group) or
+// a user
+type Profile struct {
+	Target       string
+	DefaultCPUs  string
+	DefaultMemMB string
+	MaxCPUs      string
+	MaxMemMB     string
+	// Images are the container images users may choose, the first is the default
+	Images []string
+}
+
+// ParseProfile parses a profile such as "@data-science:max-cpus=4,max-mem-mb=8192,image=rstudio/r-session-complete:jammy"
+func ParseProfile(spec string) (Profile, error) {
+	target, settings, found := strings.Cut(spec, ":")
+	target = strings.TrimSpace(target)
+	if !found || target == "" || strings.TrimSpace(settings) == "" {
+		return Profile{}, fmt.Errorf("invalid profile %q, use [*|@GROUP|USER]:default-cpus=N,default-mem-mb=N,max-cpus=N,max-mem-mb=N,image=IMAGE", spec)
+	}
+
+	profile := Profile{Target: target}
+	for _, setting := range strings.Split(settings, ",") {
+		key, value, _ := strings.Cut(setting, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "" {
+			return Profile{}, fmt.Errorf("invalid profile %q, %s has no value", spec, key)
+		}
+		switch key {
+		case "default-cpus", "max-cpus":
+			if cpus, err := strconv.ParseFloat(value, 64); err != nil || cpus <= 0 {
+				return Profile{}, fmt.Errorf("invalid profile %q, %s must be a positive number", spec, key)
+			}
+		case "default-mem-mb", "max-mem-mb":
+			if mem, err := strconv.Atoi(value); err != nil || mem <= 0 {
+				return Profile{}, fmt.Errorf("invalid profile %q, %s must be a positive whole number", spec, key)
+			}
+		}
+		switch key {
+		case "default-cpus":
+			profile.DefaultCPUs = value
+		case "default-mem-mb":
+			profile.DefaultMemMB = value
+		case "max-cpus":
+			profile.MaxCPUs = value
+		case "max-mem-mb":
+			profile.MaxMemMB = value
+		case "image":
+			profile.Images = append(profile.Images, value)
+		default:
+			return Profile{}, fmt.Errorf("invalid profile %q, unknown setting %s, valid settings are default-cpus, default-mem-mb, max-cpus, max-mem-mb and image", spec, key)
+		}
+	}
+
+	// the defaults can't be more than the maximums
+	if exceeds(profile.DefaultCPUs, profile.MaxCPUs) {
+		return Profile{}, fmt.Errorf("invalid profile %q, default-cpus is more than max-cpus", spec)
+	}
+	if exceeds(profile.DefaultMemMB, profile.MaxMemMB) {
+		return Profile{}, fmt.Errorf("invalid profile %q, default-mem-mb is more than max-mem-mb", spec)
+	}
+	return profile, nil
+}
+
+// exceeds reports whether both values are set and the first is larger, the values have already been validated
+func exceeds(value string, limit string) bool {
+	if value == "" || limit == "" {
+		return false
+	}
+	v, _ := strconv.ParseFloat(value, 64)
+	l, _ := strconv.ParseFloat(limit, 64)
+	return v > l
+}
+
+// ValidateProfiles checks the profiles only use settings the plugin supports, only Kubernetes runs sessions in
+// container images
+func ValidateProfiles(plugin string, profiles []Profile) error {
+	for _, profile := range profiles {
+		if len(profile.Images) > 0 && plugin != PluginKubernetes {
+			return fmt.Errorf("invalid profile for %s, image is only valid for %s", profile.Target, PluginKubernetes)
+		}
+	}
+	return nil
+}
+
+// writeProfiles sets each profile's limits in a Launcher profiles file, keeping any other profiles and settings
+func writeProfiles(path string, profiles []Profile) error {
+	profilesConf, err := conffile.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, profile := range profiles {
+		section := profilesConf.Section(profile.Target)
+		if profile.DefaultCPUs != "" {
+			section.Set("default-cpus", profile.DefaultCPUs)
+		}
+		if profile.DefaultMemMB != "" {
+			section.Set("default-mem-mb", profile.DefaultMemMB)
+		}
+		if profile.MaxCPUs != "" {
+			section.Set("max-cpus", profile.MaxCPUs)
+		}
+		if profile.MaxMemMB != "" {
+			section.Set("max-mem-mb", profile.MaxMemMB)
+		}
+		if len(profile.Images) > 0 {
+			section.Set("container-images", strings.Join(profile.Images, ","))
+			section.Set("default-container-image", profile.Images[0])
+			section.Set("allow-unknown-images", "0")
+		}
+	}
+
+	err = profilesConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
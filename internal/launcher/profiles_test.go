@@ -0,0 +1,64 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfile(t *testing.T) {
+	tests := map[string]struct {
+		spec        string
+		expected    Profile
+		expectError string
+	}{
+		"group with limits and images": {
+			spec:     "@data-science:max-cpus=4, max-mem-mb=8192,image=rstudio/r-session-complete:jammy,image=rstudio/r-session-complete:noble",
+			expected: Profile{Target: "@data-science", MaxCPUs: "4", MaxMemMB: "8192", Images: []string{"rstudio/r-session-complete:jammy", "rstudio/r-session-complete:noble"}},
+		},
+		"defaults and limits": {
+			spec:     "@hpc:default-cpus=2,default-mem-mb=4096,max-cpus=16,max-mem-mb=65536",
+			expected: Profile{Target: "@hpc", DefaultCPUs: "2", DefaultMemMB: "4096", MaxCPUs: "16", MaxMemMB: "65536"},
+		},
+		"default more than the maximum": {
+			spec:        "@hpc:default-mem-mb=8192,max-mem-mb=4096",
+			expectError: "default-mem-mb is more than max-mem-mb",
+		},
+		"everyone with fractional cpus": {
+			spec:     "*:max-cpus=0.5",
+			expected: Profile{Target: "*", MaxCPUs: "0.5"},
+		},
+		"no settings": {
+			spec:        "jdoe",
+			expectError: "use [*|@GROUP|USER]:default-cpus=N,default-mem-mb=N,max-cpus=N,max-mem-mb=N,image=IMAGE",
+		},
+		"no value": {
+			spec:        "jdoe:image",
+			expectError: "image has no value",
+		},
+		"invalid memory": {
+			spec:        "jdoe:max-mem-mb=8G",
+			expectError: "max-mem-mb must be a positive whole number",
+		},
+		"invalid cpus": {
+			spec:        "jdoe:max-cpus=-1",
+			expectError: "max-cpus must be a positive number",
+		},
+		"unknown setting": {
+			spec:        "jdoe:max-gpus=1",
+			expectError: "unknown setting max-gpus",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			profile, err := ParseProfile(tc.spec)
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, profile)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
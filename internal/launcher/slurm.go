@@ -0,0 +1,290 @@
+package launcher
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// slurmCommands are the Slurm commands the Launcher runs from slurm-bin-path
+var slurmCommands = []string{"sbatch", "sinfo", "squeue", "scancel", "scontrol", "sacct"}
+
+var (
+	launcherSlurmConfPath     = "/etc/rstudio/launcher.slurm.conf"
+	launcherSlurmProfilesPath = "/etc/rstudio/launcher.slurm.profiles.conf"
+	// slurmConfPaths are where Slurm packages and source installs put slurm.conf
+	slurmConfPaths  = []string{"/etc/slurm/slurm.conf", "/etc/slurm-llnl/slurm.conf", "/usr/local/etc/slurm.conf"}
+	lookPath        = exec.LookPath
+	runSlurmCommand = runWithOutput
+	getenv          = os.Getenv
+)
+
+// SlurmOptions describes the Slurm cluster the Launcher submits sessions and jobs to
+type SlurmOptions struct {
+	// BinPath is the directory of sbatch and sinfo, found on the PATH when empty
+	BinPath string
+	// ConfPath is slurm.conf, found in SLURM_CONF or the usual locations when empty
+	ConfPath string
+	// ServiceUser queries the state of every user's jobs, the Launcher runs the Slurm commands as each user when empty
+	ServiceUser string
+	// Partition is checked and used for the validation job, Slurm's default partition is used when empty
+	Partition       string
+	CallbackAddress string
+	Profiles        []Profile
+}
+
+// slurmConf is the part of slurm.conf needed to check the cluster statically
+type slurmConf struct {
+	ClusterName      string
+	ControllerHosts  []string
+	Partitions       []string
+	DefaultPartition string
+}
+
+// ConfigureSlurm checks the Slurm commands and slurm.conf, submits a validation job when the controller is reachable,
+// then writes launcher.slurm.conf, the Slurm cluster in launcher.conf, the session profiles and the Launcher settings in
+// rserver.conf
+func ConfigureSlurm(opts SlurmOptions) error {
+	err := ValidateProfiles(PluginSlurm, opts.Profiles)
+	if err != nil {
+		return err
+	}
+	binPath, err := findSlurmBinPath(opts.BinPath)
+	if err != nil {
+		return err
+	}
+	err = ValidateSlurm(binPath, opts)
+	if err != nil {
+		return err
+	}
+
+	slurmLauncherConf, err := conffile.Load(launcherSlurmConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	slurmLauncherConf.Set("slurm-bin-path", binPath)
+	if opts.ServiceUser != "" {
+		slurmLauncherConf.Set("slurm-service-user", opts.ServiceUser)
+	}
+	err = slurmLauncherConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	err = writeClusterConfig("Slurm", "Slurm")
+	if err != nil {
+		return err
+	}
+	if len(opts.Profiles) > 0 {
+		err = writeProfiles(launcherSlurmProfilesPath, opts.Profiles)
+		if err != nil {
+			return err
+		}
+	}
+	err = enableLauncherSessions("Slurm", opts.CallbackAddress)
+	if err != nil {
+		return err
+	}
+
+	printNextSteps("Slurm")
+	return nil
+}
+
+// findSlurmBinPath returns the directory of sinfo and sbatch, checking it has every Slurm command the Launcher runs
+func findSlurmBinPath(binPath string) (string, error) {
+	if binPath == "" {
+		sinfoPath, err := lookPath("sinfo")
+		if err != nil {
+			return "", errors.New("sinfo was not found on the PATH, install the Slurm client commands or provide their directory with the bin-path flag")
+		}
+		binPath = filepath.Dir(sinfoPath)
+	}
+
+	var missing []string
+	for _, command := range slurmCommands {
+		info, err := os.Stat(filepath.Join(binPath, command))
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+			missing = append(missing, command)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("the Slurm commands %s were not found in %s, the Launcher runs every Slurm command from one directory", strings.Join(missing, ", "), binPath)
+	}
+	system.PrintAndLogInfo("Found the Slurm commands in " + binPath)
+	return binPath, nil
+}
+
+// findSlurmConf returns the slurm.conf the Slurm commands read
+func findSlurmConf(confPath string) (string, error) {
+	if confPath != "" {
+		return confPath, nil
+	}
+	if envPath := getenv("SLURM_CONF"); envPath != "" {
+		return envPath, nil
+	}
+	for _, path := range slurmConfPaths {
+		if system.VerifyFileExists(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("slurm.conf was not found in %s, provide it with the slurm-conf flag", strings.Join(slurmConfPaths, ", "))
+}
+
+// readSlurmConf reads the cluster name, controllers and partitions from slurm.conf. Keys are case insensitive and a
+// line may hold several Key=Value pairs, as in "PartitionName=cpu Nodes=node[1-4] Default=YES".
+func readSlurmConf(path string) (slurmConf, error) {
+	var conf slurmConf
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return conf, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		settings := map[string]string{}
+		var first string
+		for _, field := range strings.Fields(line) {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+			key = strings.ToLower(key)
+			if first == "" {
+				first = key
+			}
+			settings[key] = value
+		}
+
+		switch first {
+		case "clustername":
+			conf.ClusterName = settings[first]
+		case "slurmctldhost", "controlmachine":
+			// SlurmctldHost may include the address, as in "ctl1(10.0.0.1)"
+			host, _, _ := strings.Cut(settings[first], "(")
+			conf.ControllerHosts = append(conf.ControllerHosts, host)
+		case "partitionname":
+			name := settings[first]
+			// PartitionName=DEFAULT sets the defaults of the partitions that follow
+			if strings.EqualFold(name, "default") {
+				continue
+			}
+			conf.Partitions = append(conf.Partitions, name)
+			if strings.EqualFold(settings["default"], "yes") {
+				conf.DefaultPartition = name
+			}
+		}
+	}
+	return conf, scanner.Err()
+}
+
+// ValidateSlurm checks slurm.conf names a controller and the partition. When the controller answers, the partition is
+// checked against sinfo and a validation job is submitted with sbatch --test-only, which schedules nothing. Otherwise
+// only slurm.conf is checked.
+func ValidateSlurm(binPath string, opts SlurmOptions) error {
+	confPath, err := findSlurmConf(opts.ConfPath)
+	if err != nil {
+		return err
+	}
+	conf, err := readSlurmConf(confPath)
+	if err != nil {
+		return err
+	}
+	if len(conf.ControllerHosts) == 0 {
+		return fmt.Errorf("%s does not set SlurmctldHost, the Slurm controller is needed to run sessions", confPath)
+	}
+	system.PrintAndLogInfo("\nChecking the Slurm cluster " + conf.ClusterName + " with the controller " + strings.Join(conf.ControllerHosts, ", ") + " from " + confPath)
+
+	pingOutput, pingErr := runSlurmCommand(filepath.Join(binPath, "scontrol"), "ping")
+	if pingErr == nil && !strings.Contains(pingOutput, "is UP") {
+		pingErr = errors.New(strings.TrimSpace(pingOutput))
+	}
+	if pingErr != nil {
+		// slurm.conf may list partitions in included files, so they are only checked when it lists any
+		if opts.Partition != "" && len(conf.Partitions) > 0 && !lo.Contains(conf.Partitions, opts.Partition) {
+			return fmt.Errorf("the partition %s is not in %s, the partitions are %s", opts.Partition, confPath, strings.Join(conf.Partitions, ", "))
+		}
+		printDefaultPartition(opts.Partition, conf.DefaultPartition)
+		system.PrintAndLogInfo("The Slurm controller could not be reached, so only " + confPath + " was checked and no validation job was submitted: " + pingErr.Error())
+		return nil
+	}
+
+	partitions, defaultPartition, err := slurmPartitions(binPath)
+	if err != nil {
+		return err
+	}
+	partition := opts.Partition
+	if partition == "" {
+		partition = defaultPartition
+	}
+	if partition == "" {
+		return errors.New("the Slurm cluster has no default partition, provide one with the partition flag")
+	}
+	if !lo.Contains(partitions, partition) {
+		return fmt.Errorf("the partition %s does not exist, the partitions are %s", partition, strings.Join(partitions, ", "))
+	}
+	printDefaultPartition(partition, defaultPartition)
+
+	args := []string{"--test-only", "--partition=" + partition, "--job-name=wbi-launcher-check"}
+	for _, profile := range opts.Profiles {
+		if profile.Target != "*" {
+			continue
+		}
+		if profile.DefaultCPUs != "" {
+			args = append(args, "--cpus-per-task="+profile.DefaultCPUs)
+		}
+		if profile.DefaultMemMB != "" {
+			args = append(args, "--mem="+profile.DefaultMemMB+"M")
+		}
+	}
+	args = append(args, "--wrap=true")
+	output, err := runSlurmCommand(filepath.Join(binPath, "sbatch"), args...)
+	if err != nil {
+		return fmt.Errorf("issue submitting a validation job to the partition %s with sbatch --test-only: %w", partition, err)
+	}
+	system.PrintAndLogInfo("The Slurm controller accepted a validation job in the partition " + partition + ": " + strings.TrimSpace(output))
+	return nil
+}
+
+// printDefaultPartition explains that sessions start in Slurm's default partition when the partition differs from it
+func printDefaultPartition(partition string, defaultPartition string) {
+	if partition != "" && defaultPartition != "" && partition != defaultPartition {
+		system.PrintAndLogInfo("Sessions start in the default partition " + defaultPartition + " unless users choose " + partition + ", set Default=YES on " + partition + " in slurm.conf to change the default")
+	}
+}
+
+// slurmPartitions lists the partitions known to the controller and its default partition, which sinfo marks with *
+func slurmPartitions(binPath string) ([]string, string, error) {
+	output, err := runSlurmCommand(filepath.Join(binPath, "sinfo"), "--noheader", "--format=%P")
+	if err != nil {
+		return nil, "", fmt.Errorf("issue listing the Slurm partitions with sinfo: %w", err)
+	}
+	var partitions []string
+	var defaultPartition string
+	for _, field := range strings.Fields(output) {
+		name := strings.TrimSuffix(field, "*")
+		if name != field {
+			defaultPartition = name
+		}
+		partitions = append(partitions, name)
+	}
+	return partitions, defaultPartition, nil
+}
+
+// runWithOutput runs a command, returning its stdout and stderr together since sbatch --test-only reports on stderr
+func runWithOutput(name string, args ...string) (string, error) {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
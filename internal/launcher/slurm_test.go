@@ -0,0 +1,166 @@
+package launcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSlurmConf = `# slurm.conf
+ClusterName=hpc
+SlurmctldHost=ctl1(10.0.0.1)
+SlurmctldHost=ctl2
+PartitionName=DEFAULT MaxTime=INFINITE State=UP
+PartitionName=cpu Nodes=node[1-4] Default=YES
+partitionname=gpu Nodes=gpu[1-2] # GPU nodes
+`
+
+// fakeSlurm stands in for the Slurm commands, recording how each was run
+type fakeSlurm struct {
+	controllerUp bool
+	partitions   string
+	sbatchErr    error
+	commands     []string
+}
+
+func (f *fakeSlurm) run(name string, args ...string) (string, error) {
+	f.commands = append(f.commands, filepath.Base(name)+" "+strings.Join(args, " "))
+	switch filepath.Base(name) {
+	case "scontrol":
+		if !f.controllerUp {
+			return "", errors.New("exit status 1: Slurmctld(primary) at ctl1 is DOWN")
+		}
+		return "Slurmctld(primary) at ctl1 is UP\n", nil
+	case "sinfo":
+		return f.partitions, nil
+	case "sbatch":
+		if f.sbatchErr != nil {
+			return "", f.sbatchErr
+		}
+		return "sbatch: Job 1234 to start at 2026-10-18T10:00:00 using 2 processors on nodes node1 in partition cpu\n", nil
+	}
+	return "", nil
+}
+
+// useTestSlurm creates the Slurm commands and slurm.conf in a temporary directory and replaces running them
+func useTestSlurm(t *testing.T) (*fakeSlurm, string) {
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "bin")
+	require.NoError(t, os.Mkdir(binPath, 0755))
+	for _, command := range slurmCommands {
+		require.NoError(t, os.WriteFile(filepath.Join(binPath, command), []byte("#!/bin/sh\n"), 0755))
+	}
+	confPath := filepath.Join(dir, "slurm.conf")
+	require.NoError(t, os.WriteFile(confPath, []byte(testSlurmConf), 0644))
+
+	fake := &fakeSlurm{controllerUp: true, partitions: "cpu*\ngpu\n"}
+	originalLookPath, originalRun, originalConfPaths, originalGetenv := lookPath, runSlurmCommand, slurmConfPaths, getenv
+	lookPath = func(file string) (string, error) { return filepath.Join(binPath, file), nil }
+	runSlurmCommand = fake.run
+	slurmConfPaths = []string{confPath}
+	getenv = func(string) string { return "" }
+	t.Cleanup(func() {
+		lookPath, runSlurmCommand, slurmConfPaths, getenv = originalLookPath, originalRun, originalConfPaths, originalGetenv
+	})
+	return fake, binPath
+}
+
+func TestReadSlurmConf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slurm.conf")
+	require.NoError(t, os.WriteFile(path, []byte(testSlurmConf), 0644))
+
+	conf, err := readSlurmConf(path)
+	require.NoError(t, err)
+	assert.Equal(t, slurmConf{ClusterName: "hpc", ControllerHosts: []string{"ctl1", "ctl2"}, Partitions: []string{"cpu", "gpu"}, DefaultPartition: "cpu"}, conf)
+}
+
+func TestFindSlurmBinPath(t *testing.T) {
+	_, binPath := useTestSlurm(t)
+
+	found, err := findSlurmBinPath("")
+	require.NoError(t, err)
+	assert.Equal(t, binPath, found)
+
+	require.NoError(t, os.Remove(filepath.Join(binPath, "sacct")))
+	require.NoError(t, os.Chmod(filepath.Join(binPath, "scancel"), 0644))
+	_, err = findSlurmBinPath(binPath)
+	assert.ErrorContains(t, err, "the Slurm commands scancel, sacct were not found in "+binPath)
+
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	_, err = findSlurmBinPath("")
+	assert.ErrorContains(t, err, "sinfo was not found on the PATH")
+}
+
+func TestValidateSlurm(t *testing.T) {
+	t.Run("validation job in the default partition", func(t *testing.T) {
+		fake, binPath := useTestSlurm(t)
+		profiles := []Profile{{Target: "@hpc", DefaultCPUs: "8"}, {Target: "*", DefaultCPUs: "2", DefaultMemMB: "4096"}}
+		require.NoError(t, ValidateSlurm(binPath, SlurmOptions{Profiles: profiles}))
+		assert.Equal(t, []string{
+			"scontrol ping",
+			"sinfo --noheader --format=%P",
+			"sbatch --test-only --partition=cpu --job-name=wbi-launcher-check --cpus-per-task=2 --mem=4096M --wrap=true",
+		}, fake.commands)
+	})
+
+	t.Run("partition that does not exist", func(t *testing.T) {
+		_, binPath := useTestSlurm(t)
+		assert.ErrorContains(t, ValidateSlurm(binPath, SlurmOptions{Partition: "bigmem"}), "the partition bigmem does not exist, the partitions are cpu, gpu")
+	})
+
+	t.Run("rejected validation job", func(t *testing.T) {
+		fake, binPath := useTestSlurm(t)
+		fake.sbatchErr = errors.New("exit status 1: sbatch: error: Batch job submission failed: Invalid account or account/partition combination specified")
+		assert.ErrorContains(t, ValidateSlurm(binPath, SlurmOptions{Partition: "gpu"}), "Invalid account or account/partition combination specified")
+	})
+
+	t.Run("unreachable controller only checks slurm.conf", func(t *testing.T) {
+		fake, binPath := useTestSlurm(t)
+		fake.controllerUp = false
+		require.NoError(t, ValidateSlurm(binPath, SlurmOptions{Partition: "gpu"}))
+		assert.Equal(t, []string{"scontrol ping"}, fake.commands)
+
+		assert.ErrorContains(t, ValidateSlurm(binPath, SlurmOptions{Partition: "bigmem"}), "the partition bigmem is not in")
+	})
+
+	t.Run("no controller in slurm.conf", func(t *testing.T) {
+		_, binPath := useTestSlurm(t)
+		confPath := filepath.Join(t.TempDir(), "slurm.conf")
+		require.NoError(t, os.WriteFile(confPath, []byte("ClusterName=hpc\n"), 0644))
+		assert.ErrorContains(t, ValidateSlurm(binPath, SlurmOptions{ConfPath: confPath}), "does not set SlurmctldHost")
+	})
+}
+
+func TestConfigureSlurm(t *testing.T) {
+	_, binPath := useTestSlurm(t)
+	useTestLauncherConf(t)
+	require.NoError(t, os.WriteFile(rserverConfPath, []byte("launcher-sessions-callback-address=https://workbench.example.com\n"), 0644))
+
+	profiles := []Profile{{Target: "*", DefaultCPUs: "1", DefaultMemMB: "2048", MaxCPUs: "8", MaxMemMB: "32768"}}
+	require.NoError(t, ConfigureSlurm(SlurmOptions{ServiceUser: "slurm", Profiles: profiles}))
+
+	slurmLauncherConf, err := os.ReadFile(launcherSlurmConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "slurm-bin-path="+binPath+"\nslurm-service-user=slurm\n", string(slurmLauncherConf))
+
+	slurmProfiles, err := os.ReadFile(launcherSlurmProfilesPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[*]\ndefault-cpus=1\ndefault-mem-mb=2048\nmax-cpus=8\nmax-mem-mb=32768\n", string(slurmProfiles))
+
+	launcherConf, err := os.ReadFile(launcherConfPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(launcherConf), "[cluster]\nname=Slurm\ntype=Slurm\n")
+
+	rserverConf, err := os.ReadFile(rserverConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "launcher-sessions-callback-address=https://workbench.example.com\nlauncher-sessions-enabled=1\nlauncher-address=127.0.0.1\nlauncher-port=5559\nlauncher-default-cluster=Slurm\n", string(rserverConf))
+
+	// container images are only used by Kubernetes
+	err = ConfigureSlurm(SlurmOptions{Profiles: []Profile{{Target: "*", Images: []string{"rstudio/r-session-complete:jammy"}}}})
+	assert.ErrorContains(t, err, "image is only valid for kubernetes")
+}
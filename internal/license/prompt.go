@@ -6,10 +6,14 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 )
 
 // Prompt users if they wish to activate Workbench with a license key
 func PromptLicenseChoice() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().License.Activate, "license.activate")
+	}
 	name := true
 	messageText := "Would you like to activate Workbench with a license key?"
 	prompt := &survey.Confirm{
@@ -26,6 +30,9 @@ func PromptLicenseChoice() (bool, error) {
 
 // Prompt users for a Workbench license key
 func PromptLicense() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().License.Key, "license.key")
+	}
 	target := ""
 	messageText := "Workbench license key:"
 	prompt := &survey.Input{
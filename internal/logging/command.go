@@ -1,12 +1,10 @@
 package logging
 
 import (
-	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"runtime"
-	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -39,15 +37,20 @@ func init() {
 	cmdlog.SetFormatter(new(MyFormatter))
 
 	// Determine OS and install pre-requisites
-	osType, _ := DetectOS()
+	distro, _ := config.DetectDistribution()
 	hostname, _ := os.Hostname()
 
-	commentMessage := fmt.Sprintf("# This file was generated by the Workbench Installer (WBI) command line tool.\n# Host: %s, OS: %s, Timestamp: %s\n# This script may contain out of date or non-functional commands if sufficient time has elapsed or if run on a different operating system/server setup.", hostname, osType.ToString(), timestamp)
+	commentMessage := fmt.Sprintf("# This file was generated by the Workbench Installer (WBI) command line tool.\n# Host: %s, OS: %s, Timestamp: %s\n# This script may contain out of date or non-functional commands if sufficient time has elapsed or if run on a different operating system/server setup.", hostname, distro.OperatingSystem().ToString()+" ("+distro.Name+")", timestamp)
 
 	cmdlog.Info("#!/bin/bash")
 	cmdlog.Info(commentMessage + "\n")
 }
 
+// Disable stops recording commands, used in dry-run mode when no commands are run
+func Disable() {
+	cmdlog.SetOutput(io.Discard)
+}
+
 // Info ...
 func Info(format string, v ...interface{}) {
 	cmdlog.Infof(format, v...)
@@ -62,43 +65,3 @@ func Warn(format string, v ...interface{}) {
 func Error(format string, v ...interface{}) {
 	cmdlog.Errorf(format, v...)
 }
-
-// Detect which operating system WBI is running on
-// this is duplicated in operatingsystem/detect.go but cannot import that package here
-func DetectOS() (config.OperatingSystem, error) {
-	osType := runtime.GOOS
-	if osType == "linux" {
-		// Check RHEL
-		if _, err := os.Stat("/etc/redhat-release"); err == nil {
-			releaseVersionRHEL, err := os.ReadFile("/etc/redhat-release")
-			if err != nil {
-				return config.Unknown, err
-			}
-			if strings.Contains(string(releaseVersionRHEL), "release 7") {
-				return config.Redhat7, nil
-			} else if strings.Contains(string(releaseVersionRHEL), "release 8") {
-				return config.Redhat8, nil
-			} else if strings.Contains(string(releaseVersionRHEL), "release 9") {
-				return config.Redhat9, nil
-			} else {
-				return config.Unknown, errors.New("unsupported operating system")
-			}
-		} else if _, err := os.Stat("/etc/issue"); err == nil {
-			releaseVersionUbuntu, err := os.ReadFile("/etc/issue")
-			if err != nil {
-				return config.Unknown, err
-			}
-			if strings.Contains(string(releaseVersionUbuntu), "Ubuntu 22") {
-				return config.Ubuntu22, nil
-			} else if strings.Contains(string(releaseVersionUbuntu), "Ubuntu 20") {
-				return config.Ubuntu20, nil
-			} else {
-				return config.Unknown, errors.New("unsupported operating system")
-			}
-		} else {
-			return config.Unknown, errors.New("unsupported operating system")
-		}
-	} else {
-		return config.Unknown, errors.New("unsupported operating system")
-	}
-}
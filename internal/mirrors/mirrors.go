@@ -0,0 +1,216 @@
+package mirrors
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the mirror configuration read when no other file is configured
+const DefaultFile = "/etc/wbi/mirrors.yaml"
+
+// Source is an upstream location wbi downloads from that can be remapped to an internal mirror
+type Source struct {
+	Name        string
+	Default     string
+	Description string
+	// Aliases are other base URLs of the same source that are remapped as well
+	Aliases []string
+}
+
+// Names of the sources that can be mirrored
+const (
+	PositCDN        = "posit-cdn"
+	RStudioCDN      = "rstudio-cdn"
+	PositDownloads  = "posit-downloads"
+	PositInstallers = "posit-installers"
+	GitHubAPI       = "github-api"
+	GitHub          = "github"
+	EPEL            = "epel"
+	PackageManager  = "packagemanager"
+	KeyServer       = "keyserver"
+)
+
+// Sources lists every source that can be mirrored
+var Sources = []Source{
+	{Name: PositCDN, Default: "https://cdn.posit.co", Description: "R and Python version lists"},
+	{Name: RStudioCDN, Default: "https://cdn.rstudio.com", Description: "R, Python and Pro Drivers installers"},
+	{Name: PositDownloads, Default: "https://www.rstudio.com", Description: "Workbench and Pro Drivers metadata (downloads.json)"},
+	{Name: PositInstallers, Default: "https://download2.rstudio.org", Description: "Workbench installers"},
+	{Name: GitHubAPI, Default: "https://api.github.com", Description: "Quarto version list"},
+	{Name: GitHub, Default: "https://github.com", Description: "Quarto installers and checksums"},
+	{Name: EPEL, Default: "https://dl.fedoraproject.org/pub/epel", Description: "EPEL release packages on RHEL"},
+	{Name: PackageManager, Default: "https://packagemanager.posit.co", Description: "Posit Public Package Manager", Aliases: []string{"https://packagemanager.rstudio.com"}},
+	{Name: KeyServer, Default: "https://keys.openpgp.org", Description: "Posit package signing key"},
+}
+
+var (
+	loaded  bool
+	mirrors = map[string]string{}
+)
+
+// Configure loads the mirror configuration from a file, WBI_MIRROR_<SOURCE> environment variables
+// and name=url overrides, each taking precedence over the previous. A missing file is ignored.
+func Configure(file string, overrides []string) error {
+	configured := map[string]string{}
+
+	if file != "" {
+		fromFile, err := readFile(file)
+		if err != nil {
+			return err
+		}
+		for name, base := range fromFile {
+			configured[name] = base
+		}
+	}
+
+	for _, source := range Sources {
+		if base := os.Getenv(EnvName(source.Name)); base != "" {
+			configured[source.Name] = base
+		}
+	}
+
+	for _, override := range overrides {
+		name, base, found := strings.Cut(override, "=")
+		if !found {
+			return fmt.Errorf("invalid mirror %s, mirrors are specified as name=url", override)
+		}
+		configured[strings.TrimSpace(name)] = strings.TrimSpace(base)
+	}
+
+	for name, base := range configured {
+		if _, ok := lookupSource(name); !ok {
+			return fmt.Errorf("unknown mirror source %s, valid sources are: %s", name, strings.Join(Names(), ", "))
+		}
+		parsed, err := url.Parse(base)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("invalid URL %s for the mirror source %s", base, name)
+		}
+		configured[name] = strings.TrimSuffix(base, "/")
+	}
+
+	mirrors = configured
+	loaded = true
+	return nil
+}
+
+// EnvName returns the environment variable that sets the mirror of a source, such as WBI_MIRROR_POSIT_CDN
+func EnvName(name string) string {
+	return "WBI_MIRROR_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Names returns the names of the sources that can be mirrored
+func Names() []string {
+	var names []string
+	for _, source := range Sources {
+		names = append(names, source.Name)
+	}
+	return names
+}
+
+// Base returns the base URL of a source, the mirror when one is configured and the upstream URL otherwise
+func Base(name string) string {
+	ensureLoaded()
+	if base, ok := mirrors[name]; ok {
+		return base
+	}
+	source, _ := lookupSource(name)
+	return source.Default
+}
+
+// URL joins a path to the base URL of a source
+func URL(name string, path string) string {
+	return Base(name) + path
+}
+
+// Rewrite remaps an upstream URL, such as an installer URL from downloads.json, to its mirror
+func Rewrite(upstreamURL string) string {
+	ensureLoaded()
+	for _, source := range Sources {
+		base, ok := mirrors[source.Name]
+		if !ok {
+			continue
+		}
+		for _, upstream := range append([]string{source.Default}, source.Aliases...) {
+			if rest, found := cutBase(upstreamURL, upstream); found {
+				return base + rest
+			}
+		}
+	}
+	return upstreamURL
+}
+
+// Canonical maps a mirrored URL back to its upstream URL so URLs can be compared regardless of the mirrors in use
+func Canonical(mirroredURL string) string {
+	ensureLoaded()
+	// the longest mirror is matched first in case one mirror's URL is a prefix of another's
+	names := make([]string, 0, len(mirrors))
+	for name := range mirrors {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(mirrors[names[i]]) > len(mirrors[names[j]]) })
+	for _, name := range names {
+		if rest, found := cutBase(mirroredURL, mirrors[name]); found {
+			source, _ := lookupSource(name)
+			return source.Default + rest
+		}
+	}
+	return mirroredURL
+}
+
+// ensureLoaded reads the default configuration for callers that don't configure mirrors themselves,
+// such as the cgo exports
+func ensureLoaded() {
+	if loaded {
+		return
+	}
+	err := Configure(DefaultFile, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ignoring the mirror configuration: %s\n", err)
+		loaded = true
+	}
+}
+
+func readFile(file string) (map[string]string, error) {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mirror configuration: %w", err)
+	}
+	var fromFile struct {
+		Mirrors map[string]string `yaml:"mirrors"`
+	}
+	err = yaml.Unmarshal(content, &fromFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the mirror configuration %s: %w", file, err)
+	}
+	return fromFile.Mirrors, nil
+}
+
+func lookupSource(name string) (Source, bool) {
+	for _, source := range Sources {
+		if source.Name == name {
+			return source, true
+		}
+	}
+	return Source{}, false
+}
+
+// cutBase returns the remainder of a URL after a base URL, only matching whole path segments
+func cutBase(fullURL string, base string) (string, bool) {
+	if !strings.HasPrefix(fullURL, base) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(fullURL, base)
+	if rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?") {
+		return "", false
+	}
+	return rest, true
+}
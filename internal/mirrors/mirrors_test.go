@@ -0,0 +1,76 @@
+package mirrors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reset clears the mirror environment variables and configuration after the test
+func reset(t *testing.T) {
+	for _, name := range Names() {
+		t.Setenv(EnvName(name), "")
+	}
+	t.Cleanup(func() { require.NoError(t, Configure("", nil)) })
+}
+
+func TestConfigurePrecedence(t *testing.T) {
+	reset(t)
+	file := filepath.Join(t.TempDir(), "mirrors.yaml")
+	content := "mirrors:\n  posit-cdn: https://file.example.com/posit/\n  github: https://file.example.com/github\n  epel: https://file.example.com/epel\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	t.Setenv("WBI_MIRROR_GITHUB", "https://env.example.com/github")
+	t.Setenv("WBI_MIRROR_EPEL", "https://env.example.com/epel")
+
+	require.NoError(t, Configure(file, []string{"epel=https://flag.example.com/epel"}))
+
+	assert.Equal(t, "https://file.example.com/posit/r/versions.json", URL(PositCDN, "/r/versions.json"))
+	assert.Equal(t, "https://env.example.com/github", Base(GitHub))
+	assert.Equal(t, "https://flag.example.com/epel", Base(EPEL))
+	assert.Equal(t, "https://cdn.rstudio.com", Base(RStudioCDN))
+}
+
+func TestConfigureMissingFile(t *testing.T) {
+	reset(t)
+	require.NoError(t, Configure(filepath.Join(t.TempDir(), "missing.yaml"), nil))
+	assert.Equal(t, "https://cdn.posit.co", Base(PositCDN))
+}
+
+func TestConfigureInvalid(t *testing.T) {
+	reset(t)
+	cases := map[string][]string{
+		"unknown mirror source":             {"pypi=https://example.com"},
+		"invalid URL":                       {"posit-cdn=example.com"},
+		"mirrors are specified as name=url": {"posit-cdn"},
+	}
+	for message, overrides := range cases {
+		err := Configure("", overrides)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
+
+func TestRewriteAndCanonical(t *testing.T) {
+	reset(t)
+	require.NoError(t, Configure("", []string{
+		"posit-installers=https://mirror.example.com/download2",
+		"packagemanager=https://mirror.example.com/ppm",
+	}))
+
+	installer := "https://download2.rstudio.org/server/jammy/amd64/rstudio-workbench-2023.03.0-386.pro1-amd64.deb"
+	mirrored := "https://mirror.example.com/download2/server/jammy/amd64/rstudio-workbench-2023.03.0-386.pro1-amd64.deb"
+	assert.Equal(t, mirrored, Rewrite(installer))
+	assert.Equal(t, installer, Canonical(mirrored))
+
+	// aliases are remapped to the mirror and canonicalized to the default
+	assert.Equal(t, "https://mirror.example.com/ppm/cran/__linux__/jammy/latest", Rewrite("https://packagemanager.rstudio.com/cran/__linux__/jammy/latest"))
+	assert.Equal(t, "https://packagemanager.posit.co/cran/__linux__/jammy/latest", Canonical("https://mirror.example.com/ppm/cran/__linux__/jammy/latest"))
+
+	// sources without a mirror and partial host matches are left alone
+	assert.Equal(t, "https://cdn.rstudio.com/r/ubuntu-2204/pkgs/r-4.2.3_1_amd64.deb", Rewrite("https://cdn.rstudio.com/r/ubuntu-2204/pkgs/r-4.2.3_1_amd64.deb"))
+	assert.Equal(t, "https://download2.rstudio.org.example.com/file", Rewrite("https://download2.rstudio.org.example.com/file"))
+}
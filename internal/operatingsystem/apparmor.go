@@ -0,0 +1,200 @@
+package operatingsystem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+var (
+	appArmorEnabledPath  = "/sys/module/apparmor/parameters/enabled"
+	appArmorProfilesPath = "/sys/kernel/security/apparmor/profiles"
+	appArmorProfileDir   = "/etc/apparmor.d"
+)
+
+// AppArmorProfile is a profile loaded into the kernel
+type AppArmorProfile struct {
+	Name string
+	// Mode is enforce, complain, kill or unconfined
+	Mode string
+	// File is the profile's source in /etc/apparmor.d, it is empty when no file declares the profile
+	File string
+}
+
+// Enforcing returns true when the profile blocks access rather than only logging it
+func (p AppArmorProfile) Enforcing() bool {
+	return p.Mode == "enforce" || p.Mode == "kill"
+}
+
+// CheckAppArmorStatus returns true when AppArmor is enabled in the kernel
+func CheckAppArmorStatus() (bool, error) {
+	enabled, err := os.ReadFile(appArmorEnabledPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", appArmorEnabledPath, err)
+	}
+	return strings.TrimSpace(string(enabled)) == "Y", nil
+}
+
+// WorkbenchAppArmorProfiles returns the loaded profiles that confine rstudio-server, rsession, or the
+// versions of R and Python in /opt/R and /opt/python
+func WorkbenchAppArmorProfiles() ([]AppArmorProfile, error) {
+	content, err := os.ReadFile(appArmorProfilesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the loaded AppArmor profiles from %s: %w", appArmorProfilesPath, err)
+	}
+	var profiles []AppArmorProfile
+	for _, profile := range parseAppArmorProfiles(string(content)) {
+		if confinesWorkbench(profile.Name) {
+			profile.File = findAppArmorProfileFile(profile.Name)
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+// parseAppArmorProfiles parses the "name (mode)" lines of the kernel's list of loaded profiles
+func parseAppArmorProfiles(content string) []AppArmorProfile {
+	var profiles []AppArmorProfile
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		open := strings.LastIndex(line, " (")
+		if open == -1 || !strings.HasSuffix(line, ")") {
+			continue
+		}
+		profiles = append(profiles, AppArmorProfile{
+			Name: line[:open],
+			Mode: line[open+2 : len(line)-1],
+		})
+	}
+	return profiles
+}
+
+func confinesWorkbench(name string) bool {
+	// child profiles are named parent//child, such as /usr/lib/rstudio-server/bin/rserver//rsession
+	for _, part := range strings.Split(strings.ToLower(name), "//") {
+		if strings.Contains(part, "rstudio") || strings.Contains(part, "rsession") ||
+			strings.HasPrefix(part, "/opt/r/") || strings.HasPrefix(part, "/opt/python/") {
+			return true
+		}
+	}
+	return false
+}
+
+// findAppArmorProfileFile returns the file in /etc/apparmor.d that declares a profile, either by its
+// name (profile rstudio {) or by the path it attaches to (/opt/R/4.3.2/bin/R {)
+func findAppArmorProfileFile(name string) string {
+	// aa-complain works on the top level profile, child profiles are switched along with it
+	name, _, _ = strings.Cut(name, "//")
+	entries, err := os.ReadDir(appArmorProfileDir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(appArmorProfileDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) > 1 && fields[0] == "profile" {
+				fields = fields[1:]
+			}
+			if len(fields) > 0 && fields[0] == name {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// SetAppArmorComplain puts profiles into complain mode, so what they would have blocked is only logged.
+// AppArmor stays enabled and every other profile keeps enforcing.
+func SetAppArmorComplain(profiles []AppArmorProfile) error {
+	utilsCommand := "command -v aa-complain || true"
+	aaComplain, err := system.RunCommandAndCaptureOutput(utilsCommand, false, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue checking for aa-complain with the command '%s': %w", utilsCommand, err)
+	}
+	if strings.TrimSpace(aaComplain) == "" {
+		installCommand := "DEBIAN_FRONTEND=noninteractive apt-get install -y apparmor-utils"
+		err = system.RunCommand(installCommand, true, 1, true)
+		if err != nil {
+			return fmt.Errorf("issue installing apparmor-utils with the command '%s': %w", installCommand, err)
+		}
+	}
+
+	switched := map[string]bool{}
+	for _, profile := range profiles {
+		target := profile.File
+		if target == "" {
+			target = profile.Name
+		}
+		// a parent profile and its children share a file, which only needs to be switched once
+		if switched[target] {
+			continue
+		}
+		switched[target] = true
+		complainCommand := "aa-complain " + target
+		err := system.RunCommand(complainCommand, true, 1, true)
+		if err != nil {
+			return fmt.Errorf("issue putting the AppArmor profile %s into complain mode with the command '%s': %w", profile.Name, complainCommand, err)
+		}
+	}
+
+	system.PrintAndLogInfo("\nThe AppArmor profiles have been successfully put into complain mode, AppArmor remains enabled for all other profiles!")
+	return nil
+}
+
+// PromptAndConfigureAppArmor reports the AppArmor profiles that confine Workbench, R or Python on Ubuntu and
+// asks users whether to put the enforcing ones into complain mode
+func PromptAndConfigureAppArmor(osType config.OperatingSystem) error {
+	if osType != config.Ubuntu20 && osType != config.Ubuntu22 && osType != config.Ubuntu24 {
+		return nil
+	}
+	system.PrintAndLogInfo("Checking to see if AppArmor is active on this server")
+	enabled, err := CheckAppArmorStatus()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		system.PrintAndLogInfo("AppArmor Status: disabled")
+		return nil
+	}
+	system.PrintAndLogInfo("AppArmor Status: enabled")
+
+	profiles, err := WorkbenchAppArmorProfiles()
+	if err != nil {
+		return err
+	}
+	var enforcing []AppArmorProfile
+	for _, profile := range profiles {
+		system.PrintAndLogInfo("AppArmor profile " + profile.Name + " (" + profile.Mode + ") confines Workbench, R or Python")
+		if profile.Enforcing() {
+			enforcing = append(enforcing, profile)
+		}
+	}
+	if len(enforcing) == 0 {
+		system.PrintAndLogInfo("No enforcing AppArmor profiles confine Workbench, R or Python")
+		return nil
+	}
+
+	complain, err := AppArmorPrompt(enforcing)
+	if err != nil {
+		return err
+	}
+	if complain {
+		return SetAppArmorComplain(enforcing)
+	}
+	return nil
+}
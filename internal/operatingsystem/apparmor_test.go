@@ -0,0 +1,59 @@
+package operatingsystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkbenchAppArmorProfiles(t *testing.T) {
+	dir := t.TempDir()
+	originalProfiles, originalDir := appArmorProfilesPath, appArmorProfileDir
+	appArmorProfilesPath = filepath.Join(dir, "profiles")
+	appArmorProfileDir = filepath.Join(dir, "apparmor.d")
+	t.Cleanup(func() {
+		appArmorProfilesPath, appArmorProfileDir = originalProfiles, originalDir
+	})
+
+	loaded := "/usr/sbin/tcpdump (enforce)\n" +
+		"rstudio-server (enforce)\n" +
+		"rstudio-server//rsession (enforce)\n" +
+		"/opt/R/4.3.2/lib/R/bin/exec/R (complain)\n" +
+		"/opt/python/3.11.7/bin/python3.11 (kill)\n" +
+		"unprivileged_userns (unconfined)\n"
+	require.NoError(t, os.WriteFile(appArmorProfilesPath, []byte(loaded), 0644))
+	require.NoError(t, os.Mkdir(appArmorProfileDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(appArmorProfileDir, "usr.sbin.tcpdump"), []byte("/usr/sbin/tcpdump {\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(appArmorProfileDir, "rstudio"), []byte("abi <abi/4.0>,\nprofile rstudio-server /usr/lib/rstudio-server/bin/rserver flags=(attach_disconnected) {\n  profile rsession {\n  }\n}\n"), 0644))
+
+	profiles, err := WorkbenchAppArmorProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []AppArmorProfile{
+		{Name: "rstudio-server", Mode: "enforce", File: filepath.Join(appArmorProfileDir, "rstudio")},
+		{Name: "rstudio-server//rsession", Mode: "enforce", File: filepath.Join(appArmorProfileDir, "rstudio")},
+		{Name: "/opt/R/4.3.2/lib/R/bin/exec/R", Mode: "complain"},
+		{Name: "/opt/python/3.11.7/bin/python3.11", Mode: "kill"},
+	}, profiles)
+
+	assert.True(t, profiles[0].Enforcing())
+	assert.False(t, profiles[2].Enforcing())
+	assert.True(t, profiles[3].Enforcing())
+}
+
+func TestCheckAppArmorStatus(t *testing.T) {
+	original := appArmorEnabledPath
+	appArmorEnabledPath = filepath.Join(t.TempDir(), "enabled")
+	t.Cleanup(func() { appArmorEnabledPath = original })
+
+	enabled, err := CheckAppArmorStatus()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	require.NoError(t, os.WriteFile(appArmorEnabledPath, []byte("Y\n"), 0644))
+	enabled, err = CheckAppArmorStatus()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
@@ -1,52 +1,24 @@
 package operatingsystem
 
 import (
-	"errors"
-	"os"
+	"fmt"
 	"os/user"
-	"runtime"
-	"strings"
 
 	"github.com/sol-eng/wbi/internal/config"
 )
 
-// Detect which operating system WBI is running on
+// Detect which operating system WBI is running on from /etc/os-release. RHEL rebuilds such as Rocky Linux,
+// AlmaLinux and Oracle Linux are detected as the version of RHEL they are built from.
 func DetectOS() (config.OperatingSystem, error) {
-	osType := runtime.GOOS
-	if osType == "linux" {
-		// Check RHEL
-		if _, err := os.Stat("/etc/redhat-release"); err == nil {
-			releaseVersionRHEL, err := os.ReadFile("/etc/redhat-release")
-			if err != nil {
-				return config.Unknown, err
-			}
-			if strings.Contains(string(releaseVersionRHEL), "release 7") {
-				return config.Redhat7, nil
-			} else if strings.Contains(string(releaseVersionRHEL), "release 8") {
-				return config.Redhat8, nil
-			} else if strings.Contains(string(releaseVersionRHEL), "release 9") {
-				return config.Redhat9, nil
-			} else {
-				return config.Unknown, errors.New("unsupported operating system")
-			}
-		} else if _, err := os.Stat("/etc/issue"); err == nil {
-			releaseVersionUbuntu, err := os.ReadFile("/etc/issue")
-			if err != nil {
-				return config.Unknown, err
-			}
-			if strings.Contains(string(releaseVersionUbuntu), "Ubuntu 22") {
-				return config.Ubuntu22, nil
-			} else if strings.Contains(string(releaseVersionUbuntu), "Ubuntu 20") {
-				return config.Ubuntu20, nil
-			} else {
-				return config.Unknown, errors.New("unsupported operating system")
-			}
-		} else {
-			return config.Unknown, errors.New("unsupported operating system")
-		}
-	} else {
-		return config.Unknown, errors.New("unsupported operating system")
+	distro, err := config.DetectDistribution()
+	if err != nil {
+		return config.Unknown, err
+	}
+	osType := distro.OperatingSystem()
+	if osType == config.Unknown {
+		return config.Unknown, fmt.Errorf("unsupported operating system: %s", distro.Name)
 	}
+	return osType, nil
 }
 
 func UserLookup(username string) (*user.User, error) {
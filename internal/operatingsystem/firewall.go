@@ -1,44 +1,285 @@
 package operatingsystem
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/sol-eng/wbi/internal/conffile"
 	"github.com/sol-eng/wbi/internal/config"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
-func CheckFirewallStatus(osType config.OperatingSystem) (bool, error) {
-	if osType == config.Redhat7 || osType == config.Redhat8 || osType == config.Redhat9 {
+// Firewall is a host firewall that wbi can open ports in
+type Firewall string
+
+const (
+	NoFirewall Firewall = ""
+	Firewalld  Firewall = "firewalld"
+	UFW        Firewall = "ufw"
+)
+
+var (
+	rserverConfPath  = "/etc/rstudio/rserver.conf"
+	launcherConfPath = "/etc/rstudio/launcher.conf"
+)
+
+// Default ports of Workbench and the Job Launcher when they aren't set in rserver.conf and launcher.conf
+const (
+	defaultWWWPort      = 8787
+	defaultSSLPort      = 443
+	defaultHTTPPort     = 80
+	defaultLauncherPort = 5559
+)
+
+// DetectFirewall returns the host firewall: firewalld on RHEL when it is active or enabled at boot,
+// and ufw on Ubuntu when it is active
+func DetectFirewall(osType config.OperatingSystem) (Firewall, error) {
+	switch osType {
+	case config.Redhat7, config.Redhat8, config.Redhat9:
 		firewallCheckCommand := "rpm -q firewalld || true"
 		rpmOutput, err := system.RunCommandAndCaptureOutput(firewallCheckCommand, false, 0, false)
 		if err != nil {
-			return false, fmt.Errorf("issue in rpmOutput check with command '%s': %w", firewallCheckCommand, err)
+			return NoFirewall, fmt.Errorf("issue in rpmOutput check with command '%s': %w", firewallCheckCommand, err)
 		}
 
 		if strings.Contains(rpmOutput, "not installed") {
-			return false, nil
+			return NoFirewall, nil
 		}
 
-		firewallIsActiveCommand := "systemctl is-active firewalld || true"
-		firewallActive, err := system.RunCommandAndCaptureOutput(firewallIsActiveCommand, false, 0, false)
+		running, err := firewalldRunning()
 		if err != nil {
-			return false, fmt.Errorf("issue in firewallActive with the command '%s': %w", firewallIsActiveCommand, err)
+			return NoFirewall, err
 		}
-
-		if !strings.Contains(firewallActive, "inactive") {
-			return true, nil
+		if running {
+			return Firewalld, nil
 		}
 
 		firewallEnabledCommand := "systemctl is-enabled firewalld || true"
 		firewallEnabled, err := system.RunCommandAndCaptureOutput(firewallEnabledCommand, false, 0, false)
 		if err != nil {
-			return false, fmt.Errorf("issue in firewallEnabled check with the command '%s': %w", firewallEnabledCommand, err)
+			return NoFirewall, fmt.Errorf("issue in firewallEnabled check with the command '%s': %w", firewallEnabledCommand, err)
 		}
 
 		if strings.Contains(firewallEnabled, "enabled") {
-			return true, nil
+			return Firewalld, nil
+		}
+	case config.Ubuntu20, config.Ubuntu22, config.Ubuntu24:
+		ufwStatusCommand := "ufw status 2>/dev/null || true"
+		ufwStatus, err := system.RunCommandAndCaptureOutput(ufwStatusCommand, false, 0, false)
+		if err != nil {
+			return NoFirewall, fmt.Errorf("issue in ufw status check with the command '%s': %w", ufwStatusCommand, err)
+		}
+
+		if strings.Contains(ufwStatus, "Status: active") {
+			return UFW, nil
+		}
+	}
+	return NoFirewall, nil
+}
+
+func firewalldRunning() (bool, error) {
+	firewallIsActiveCommand := "systemctl is-active firewalld || true"
+	firewallActive, err := system.RunCommandAndCaptureOutput(firewallIsActiveCommand, false, 0, false)
+	if err != nil {
+		return false, fmt.Errorf("issue in firewallActive with the command '%s': %w", firewallIsActiveCommand, err)
+	}
+	return strings.TrimSpace(firewallActive) == "active", nil
+}
+
+// PromptAndConfigureFirewall detects the host firewall and asks users whether to open the Workbench ports in
+// it or disable it. Nothing is asked when no firewall is active.
+func PromptAndConfigureFirewall(osType config.OperatingSystem) error {
+	firewall, err := DetectFirewall(osType)
+	if err != nil {
+		return err
+	}
+	if firewall == NoFirewall {
+		return nil
+	}
+	ports, err := WorkbenchPorts()
+	if err != nil {
+		return err
+	}
+
+	choice, err := FirewallPrompt(firewall, ports)
+	if err != nil {
+		return err
+	}
+	switch choice {
+	case FirewallOpenPorts:
+		err = OpenFirewallPorts(firewall, ports)
+		if err != nil {
+			return err
+		}
+		err = ShowFirewallRules(firewall)
+		if err != nil {
+			return err
+		}
+		verify, err := VerifyPortsPrompt()
+		if err != nil {
+			return err
+		}
+		if verify {
+			VerifyPortsReachable(ports)
 		}
+	case FirewallDisable:
+		return DisableFirewall(osType)
+	}
+	return nil
+}
+
+// WorkbenchPorts returns the ports users and sessions connect to: the www-port from rserver.conf, which defaults
+// to 8787, or to 443 with SSL along with port 80 that redirects to HTTPS, and the port of the Job Launcher
+func WorkbenchPorts() ([]int, error) {
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	launcherConf, err := conffile.Load(launcherConfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	sslEnabled, _ := rserverConf.Get("ssl-enabled")
+	wwwPort, err := workbenchWWWPort(rserverConf)
+	if err != nil {
+		return nil, err
+	}
+	ports := []int{wwwPort}
+	// Workbench redirects HTTP on port 80 to HTTPS unless ssl-redirect-http=0
+	if redirect, _ := rserverConf.Get("ssl-redirect-http"); sslEnabled == "1" && wwwPort == defaultSSLPort && redirect != "0" {
+		ports = append(ports, defaultHTTPPort)
+	}
+
+	launcherPort := defaultLauncherPort
+	if value, ok := launcherConf.Section("server").Get("port"); ok && value != "" {
+		launcherPort, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %s in %s", value, launcherConfPath)
+		}
+	}
+	ports = append(ports, launcherPort)
+
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// workbenchWWWPort returns the www-port from rserver.conf, which defaults to 8787, or to 443 with SSL
+func workbenchWWWPort(rserverConf *conffile.File) (int, error) {
+	value, ok := rserverConf.Get("www-port")
+	if !ok || value == "" {
+		if sslEnabled, _ := rserverConf.Get("ssl-enabled"); sslEnabled == "1" {
+			return defaultSSLPort, nil
+		}
+		return defaultWWWPort, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid www-port %s in %s", value, rserverConfPath)
+	}
+	return port, nil
+}
+
+// OpenFirewallPorts permanently allows TCP connections to the ports through the host firewall
+func OpenFirewallPorts(firewall Firewall, ports []int) error {
+	running := true
+	if firewall == Firewalld {
+		var err error
+		running, err = firewalldRunning()
+		if err != nil {
+			return err
+		}
+	}
+	commands, err := openPortCommands(firewall, running, ports)
+	if err != nil {
+		return err
+	}
+	for _, command := range commands {
+		err := system.RunCommand(command, true, 1, true)
+		if err != nil {
+			return fmt.Errorf("issue opening firewall ports with the command '%s': %w", command, err)
+		}
+	}
+
+	system.PrintAndLogInfo("\nThe firewall now allows connections to port(s) " + joinPorts(ports) + "!")
+	return nil
+}
+
+// openPortCommands returns the commands that open the ports. firewall-cmd needs firewalld to be running,
+// so firewall-offline-cmd is used when it is enabled but stopped.
+func openPortCommands(firewall Firewall, running bool, ports []int) ([]string, error) {
+	var commands []string
+	switch firewall {
+	case Firewalld:
+		firewallCmd := "firewall-cmd --permanent"
+		if !running {
+			firewallCmd = "firewall-offline-cmd"
+		}
+		for _, port := range ports {
+			commands = append(commands, fmt.Sprintf("%s --add-port=%d/tcp", firewallCmd, port))
+		}
+		if running {
+			commands = append(commands, "firewall-cmd --reload")
+		}
+	case UFW:
+		for _, port := range ports {
+			commands = append(commands, fmt.Sprintf("ufw allow %d/tcp", port))
+		}
+	default:
+		return nil, errors.New("no supported firewall is active, only firewalld and ufw are supported")
+	}
+	return commands, nil
+}
+
+// ShowFirewallRules prints the ports and services the host firewall allows
+func ShowFirewallRules(firewall Firewall) error {
+	var rulesCommand string
+	switch firewall {
+	case Firewalld:
+		running, err := firewalldRunning()
+		if err != nil {
+			return err
+		}
+		rulesCommand = "firewall-cmd --list-all"
+		if !running {
+			rulesCommand = "firewall-offline-cmd --list-all"
+		}
+	case UFW:
+		rulesCommand = "ufw status verbose"
+	default:
+		return errors.New("no supported firewall is active, only firewalld and ufw are supported")
+	}
+	rules, err := system.RunCommandAndCaptureOutput(rulesCommand, false, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue listing the firewall rules with the command '%s': %w", rulesCommand, err)
+	}
+	system.PrintAndLogInfo("\n=== Firewall rules (" + rulesCommand + ") ===\n" + strings.TrimSpace(rules))
+	return nil
+}
+
+// VerifyPortsReachable connects to each port on localhost and reports whether anything answered
+func VerifyPortsReachable(ports []int) {
+	for _, port := range ports {
+		address := net.JoinHostPort("localhost", strconv.Itoa(port))
+		conn, err := net.DialTimeout("tcp", address, 3*time.Second)
+		if err != nil {
+			system.PrintAndLogInfo(fmt.Sprintf("Port %d is not reachable from localhost: %v. If Workbench isn't installed and running yet, this is expected.", port, err))
+			continue
+		}
+		conn.Close()
+		system.PrintAndLogInfo(fmt.Sprintf("Port %d is reachable from localhost", port))
+	}
+}
+
+func joinPorts(ports []int) string {
+	names := make([]string, 0, len(ports))
+	for _, port := range ports {
+		names = append(names, strconv.Itoa(port))
 	}
-	return false, nil
+	return strings.Join(names, ", ")
 }
@@ -0,0 +1,90 @@
+package operatingsystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkbenchPorts(t *testing.T) {
+	tests := map[string]struct {
+		rserverConf  string
+		launcherConf string
+		expected     []int
+	}{
+		"defaults": {
+			expected: []int{5559, 8787},
+		},
+		"ssl redirects port 80": {
+			rserverConf: "ssl-enabled=1\n",
+			expected:    []int{80, 443, 5559},
+		},
+		"ssl without the http redirect": {
+			rserverConf: "ssl-enabled=1\nssl-redirect-http=0\n",
+			expected:    []int{443, 5559},
+		},
+		"custom ports": {
+			rserverConf:  "www-port=8443\nssl-enabled=1\n",
+			launcherConf: "[server]\naddress=127.0.0.1\nport=5560\n\n[cluster]\nname=Local\ntype=Local\n",
+			expected:     []int{5560, 8443},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			setConfPaths(t, dir)
+			if tc.rserverConf != "" {
+				require.NoError(t, os.WriteFile(rserverConfPath, []byte(tc.rserverConf), 0644))
+			}
+			if tc.launcherConf != "" {
+				require.NoError(t, os.WriteFile(launcherConfPath, []byte(tc.launcherConf), 0644))
+			}
+
+			ports, err := WorkbenchPorts()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ports)
+		})
+	}
+}
+
+func TestWorkbenchPortsInvalid(t *testing.T) {
+	setConfPaths(t, t.TempDir())
+	require.NoError(t, os.WriteFile(rserverConfPath, []byte("www-port=http\n"), 0644))
+
+	_, err := WorkbenchPorts()
+	assert.ErrorContains(t, err, "invalid www-port http")
+}
+
+func TestOpenPortCommands(t *testing.T) {
+	commands, err := openPortCommands(Firewalld, true, []int{443, 5559})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"firewall-cmd --permanent --add-port=443/tcp",
+		"firewall-cmd --permanent --add-port=5559/tcp",
+		"firewall-cmd --reload",
+	}, commands)
+
+	commands, err = openPortCommands(Firewalld, false, []int{8787})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"firewall-offline-cmd --add-port=8787/tcp"}, commands)
+
+	commands, err = openPortCommands(UFW, true, []int{8787, 5559})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ufw allow 8787/tcp", "ufw allow 5559/tcp"}, commands)
+
+	_, err = openPortCommands(NoFirewall, true, []int{8787})
+	assert.Error(t, err)
+}
+
+func setConfPaths(t *testing.T, dir string) {
+	originalRserver, originalLauncher := rserverConfPath, launcherConfPath
+	rserverConfPath = filepath.Join(dir, "rserver.conf")
+	launcherConfPath = filepath.Join(dir, "launcher.conf")
+	t.Cleanup(func() {
+		rserverConfPath, launcherConfPath = originalRserver, originalLauncher
+	})
+}
@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sol-eng/wbi/internal/bundle"
 	"github.com/sol-eng/wbi/internal/config"
 	"github.com/sol-eng/wbi/internal/install"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
 func InstallPrereqs(osType config.OperatingSystem) error {
 	system.PrintAndLogInfo("Installing prerequisites...")
 	// Update apt and install gdebi-core if an Ubuntu system
-	if osType == config.Ubuntu22 || osType == config.Ubuntu20 {
+	if osType == config.Ubuntu24 || osType == config.Ubuntu22 || osType == config.Ubuntu20 {
 		AptErr := UpgradeApt()
 		if AptErr != nil {
 			return fmt.Errorf("UpgradeApt: %w", AptErr)
@@ -29,12 +31,19 @@ func InstallPrereqs(osType config.OperatingSystem) error {
 		if EnableEPELErr != nil {
 			return fmt.Errorf("EnableEPELRepo: %w", EnableEPELErr)
 		}
-		// Enable the CodeReady Linux Builder repository
-		OnCloud, err := PromptCloud()
+		distro, err := config.DetectDistribution()
 		if err != nil {
-			return fmt.Errorf("PrompOnPremCloud: %w", err)
+			return fmt.Errorf("DetectDistribution: %w", err)
+		}
+		// Enable the CodeReady Linux Builder repository, rebuilds enable it the same way on premises and in the cloud
+		OnCloud := false
+		if !distro.Rebuild() {
+			OnCloud, err = PromptCloud()
+			if err != nil {
+				return fmt.Errorf("PrompOnPremCloud: %w", err)
+			}
 		}
-		EnableCodeReadyErr := EnableCodeReadyRepo(osType, OnCloud)
+		EnableCodeReadyErr := EnableCodeReadyRepo(distro, OnCloud)
 		if EnableCodeReadyErr != nil {
 			return fmt.Errorf("EnableCodeReadyRepo: %w", EnableCodeReadyErr)
 		}
@@ -49,12 +58,16 @@ func InstallPrereqs(osType config.OperatingSystem) error {
 		if err != nil {
 			return fmt.Errorf("EnableExtraRepo: %w", err)
 		}
+		distro, err := config.DetectDistribution()
+		if err != nil {
+			return fmt.Errorf("DetectDistribution: %w", err)
+		}
 		// Enable the CodeReady Linux Builder repository
 		OnCloud, err := PromptCloud()
 		if err != nil {
 			return fmt.Errorf("PrompOnPremCloud: %w", err)
 		}
-		EnableCodeReadyErr := EnableCodeReadyRepo(osType, OnCloud)
+		EnableCodeReadyErr := EnableCodeReadyRepo(distro, OnCloud)
 		if EnableCodeReadyErr != nil {
 			return fmt.Errorf("EnableCodeReadyRepo: %w", EnableCodeReadyErr)
 		}
@@ -90,8 +103,17 @@ func UpgradeApt() error {
 }
 
 // Enable the CodeReady Linux Builder repository:
-func EnableCodeReadyRepo(osType config.OperatingSystem, CloudInstall bool) error {
-	if CloudInstall {
+// RHEL uses subscription-manager on premises and the RHUI repositories in a public cloud. Rebuilds such as
+// Rocky Linux, AlmaLinux and Oracle Linux 8 and 9 enable their equivalent with the crb script from epel-release.
+func EnableCodeReadyRepo(distro config.Distribution, CloudInstall bool) error {
+	osType := distro.OperatingSystem()
+	if distro.Rebuild() && (osType == config.Redhat8 || osType == config.Redhat9) {
+		crbCommand := "dnf install -y dnf-plugins-core && crb enable"
+		err := system.RunCommand(crbCommand, true, 1, true)
+		if err != nil {
+			return fmt.Errorf("issue enabling the CodeReady Linux Builder repo with the command '%s': %w", crbCommand, err)
+		}
+	} else if CloudInstall {
 		switch osType {
 		case config.Redhat9:
 			dnfPluginsCoreCommand := "dnf install -y dnf-plugins-core"
@@ -138,7 +160,7 @@ func EnableCodeReadyRepo(osType config.OperatingSystem, CloudInstall bool) error
 				return fmt.Errorf("issue enabling codeready repo with the command '%s': %w", OnPremCodeReadyEnableCommand, err)
 			}
 		case config.Redhat8:
-			OnPremCodeReadyEnableCommand := "sudo subscription-manager repos --enable codeready-builder-for-rhel-8-x86_64-rpms\n"
+			OnPremCodeReadyEnableCommand := "sudo subscription-manager repos --enable codeready-builder-for-rhel-8-$(arch)-rpms\n"
 			err := system.RunCommand(OnPremCodeReadyEnableCommand, true, 1, true)
 			if err != nil {
 				return fmt.Errorf("issue enabling codeready repo with the command '%s': %w", OnPremCodeReadyEnableCommand, err)
@@ -169,17 +191,30 @@ func EnableExtraRepo() error {
 	return nil
 }
 
+// EPELReleaseURL returns the URL of the rpm that enables the Extra Packages for Enterprise Linux (EPEL) repository
+func EPELReleaseURL(osType config.OperatingSystem) (string, error) {
+	switch osType {
+	case config.Redhat9:
+		return mirrors.URL(mirrors.EPEL, "/epel-release-latest-9.noarch.rpm"), nil
+	case config.Redhat8:
+		return mirrors.URL(mirrors.EPEL, "/epel-release-latest-8.noarch.rpm"), nil
+	case config.Redhat7:
+		return mirrors.URL(mirrors.EPEL, "/epel-release-latest-7.noarch.rpm"), nil
+	default:
+		return "", errors.New("operating system not supported")
+	}
+}
+
 // Enable the Extra Packages for Enterprise Linux (EPEL) repository
 func EnableEPELRepo(osType config.OperatingSystem) error {
-	var EPELURL string
-	if osType == config.Redhat9 {
-		EPELURL = "https://dl.fedoraproject.org/pub/epel/epel-release-latest-9.noarch.rpm"
-	} else if osType == config.Redhat8 {
-		EPELURL = "https://dl.fedoraproject.org/pub/epel/epel-release-latest-8.noarch.rpm"
-	} else if osType == config.Redhat7 {
-		EPELURL = "https://dl.fedoraproject.org/pub/epel/epel-release-latest-7.noarch.rpm"
-	} else {
-		return errors.New("operating system not supported")
+	EPELURL, err := EPELReleaseURL(osType)
+	if err != nil {
+		return err
+	}
+
+	// install the rpm from the offline bundle if one is loaded
+	if bundledPath, ok := bundle.Lookup(EPELURL); ok {
+		EPELURL = bundledPath
 	}
 
 	EPELCommand, err := install.RetrieveInstallCommand(EPELURL, osType)
@@ -207,7 +242,7 @@ func DisableFirewall(osType config.OperatingSystem) error {
 	var FWCommand string
 
 	switch osType {
-	case config.Ubuntu20, config.Ubuntu22:
+	case config.Ubuntu20, config.Ubuntu22, config.Ubuntu24:
 		FWCommand = "ufw disable"
 	case config.Redhat7, config.Redhat8, config.Redhat9:
 		FWCommand = "systemctl stop firewalld && systemctl disable firewalld"
@@ -0,0 +1,84 @@
+package operatingsystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var mountsPath = "/proc/mounts"
+
+// Mount is a mounted file system from /proc/mounts
+type Mount struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    []string
+}
+
+// NFS returns true for NFS version 3 and 4 mounts
+func (m Mount) NFS() bool {
+	return m.FSType == "nfs" || m.FSType == "nfs4"
+}
+
+// Option returns the value of a mount option such as vers=4.1, and whether it is set
+func (m Mount) Option(name string) (string, bool) {
+	for _, option := range m.Options {
+		key, value, _ := strings.Cut(option, "=")
+		if key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// FindMount returns the mount a path is on, the mount with the longest mount point containing the path
+func FindMount(path string) (Mount, error) {
+	content, err := os.ReadFile(mountsPath)
+	if err != nil {
+		return Mount{}, fmt.Errorf("failed to read %s: %w", mountsPath, err)
+	}
+	path = filepath.Clean(path)
+
+	var found Mount
+	for _, mount := range parseMounts(string(content)) {
+		if !pathWithin(path, mount.MountPoint) {
+			continue
+		}
+		// later mounts on the same mount point hide earlier ones
+		if len(mount.MountPoint) >= len(found.MountPoint) {
+			found = mount
+		}
+	}
+	if found.MountPoint == "" {
+		return Mount{}, fmt.Errorf("no file system is mounted at %s", path)
+	}
+	return found, nil
+}
+
+func parseMounts(content string) []Mount {
+	var mounts []Mount
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, Mount{
+			Device:     fields[0],
+			MountPoint: unescapeMountField(fields[1]),
+			FSType:     fields[2],
+			Options:    strings.Split(fields[3], ","),
+		})
+	}
+	return mounts
+}
+
+// unescapeMountField decodes the octal escapes /proc/mounts uses for spaces, tabs, newlines and backslashes
+func unescapeMountField(field string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(field)
+}
+
+func pathWithin(path string, dir string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,50 @@
+package operatingsystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMounts = `/dev/sda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+nfs.example.com:/export/shared /shared nfs4 rw,relatime,vers=4.1,rsize=1048576,hard,proto=tcp 0 0
+nfs.example.com:/export/home /home nfs rw,relatime,vers=3,nolock 0 0
+/dev/sdb1 /mnt/my\040data xfs rw,relatime 0 0
+`
+
+func TestFindMount(t *testing.T) {
+	original := mountsPath
+	mountsPath = filepath.Join(t.TempDir(), "mounts")
+	t.Cleanup(func() { mountsPath = original })
+	require.NoError(t, os.WriteFile(mountsPath, []byte(testMounts), 0644))
+
+	mount, err := FindMount("/shared/rstudio/")
+	require.NoError(t, err)
+	assert.Equal(t, "/shared", mount.MountPoint)
+	assert.True(t, mount.NFS())
+	version, ok := mount.Option("vers")
+	assert.True(t, ok)
+	assert.Equal(t, "4.1", version)
+	_, ok = mount.Option("nolock")
+	assert.False(t, ok)
+
+	mount, err = FindMount("/home/jdoe")
+	require.NoError(t, err)
+	assert.True(t, mount.NFS())
+	_, ok = mount.Option("nolock")
+	assert.True(t, ok)
+
+	mount, err = FindMount("/sharedother")
+	require.NoError(t, err)
+	assert.Equal(t, "/", mount.MountPoint)
+	assert.False(t, mount.NFS())
+
+	mount, err = FindMount("/mnt/my data/rstudio")
+	require.NoError(t, err)
+	assert.Equal(t, "/mnt/my data", mount.MountPoint)
+	assert.Equal(t, "xfs", mount.FSType)
+}
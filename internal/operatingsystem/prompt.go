@@ -3,13 +3,18 @@ package operatingsystem
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
 )
 
 func PromptCloud() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Prereqs.Cloud, "prereqs.cloud")
+	}
 	name := false
 	messageText := "Is your instance of Workbench running in a public cloud(AWS, Azure, GCP, etc)?"
 	prompt := &survey.Confirm{
@@ -24,40 +29,157 @@ func PromptCloud() (bool, error) {
 	return name, nil
 }
 
-func FirewallPrompt() (bool, error) {
+// Choices offered by FirewallPrompt
+const (
+	FirewallOpenPorts = "Open the Workbench ports"
+	FirewallDisable   = "Disable the firewall"
+	FirewallSkip      = "Leave the firewall unchanged"
+)
+
+// FirewallPrompt asks users whether to open the Workbench ports in the host firewall or disable it
+func FirewallPrompt(firewall Firewall, ports []int) (string, error) {
+	if answers.Loaded() {
+		// answers files written before firewall.action was added only set firewall.disable
+		if answers.Get().Firewall.Action == nil && answers.Get().Firewall.Disable != nil {
+			disable, err := answers.Require(answers.Get().Firewall.Disable, "firewall.disable")
+			if err != nil || !disable {
+				return FirewallSkip, err
+			}
+			return FirewallDisable, nil
+		}
+		action, err := answers.RequireOneOf(answers.Get().Firewall.Action, "firewall.action", []string{"open_ports", "disable", "skip"})
+		if err != nil {
+			return "", err
+		}
+		switch action {
+		case "open_ports":
+			return FirewallOpenPorts, nil
+		case "disable":
+			return FirewallDisable, nil
+		default:
+			return FirewallSkip, nil
+		}
+	}
+	choice := ""
+	messageText := "The local firewall (" + string(firewall) + ") is active and will block Posit products unless the ports they use are open.\n" +
+		" Workbench uses port(s) " + joinPorts(ports) + ". Would you like to open these ports, or disable the local firewall?"
+	prompt := &survey.Select{
+		Message: messageText,
+		Options: []string{FirewallOpenPorts, FirewallDisable, FirewallSkip},
+		Default: FirewallOpenPorts,
+	}
+	err := survey.AskOne(prompt, &choice)
+	if err != nil {
+		return "", errors.New("there was an issue with the local firewall prompt")
+	}
+	log.Info(messageText)
+	log.Info(choice)
+	return choice, nil
+}
+
+// VerifyPortsPrompt asks users whether to check that the opened ports are reachable from localhost
+func VerifyPortsPrompt() (bool, error) {
+	if answers.Loaded() {
+		// verifying is optional, so answers files may leave it out
+		if answers.Get().Firewall.Verify == nil {
+			return false, nil
+		}
+		return answers.Require(answers.Get().Firewall.Verify, "firewall.verify")
+	}
 	name := true
-	messageText := "Posit products are often blocked by local server firewalls, most organizations\n " + "do not rely on local firewalls for server security. If your organization controls access\n " + "to this server with an external firewall, we recommend disabling the local firewall.\n" + " Would you like to disable the local firewall?"
+	messageText := "Would you like to verify the ports are reachable from localhost?"
 	prompt := &survey.Confirm{
 		Message: messageText,
 	}
 	err := survey.AskOne(prompt, &name)
 	if err != nil {
-		return false, errors.New("there was an issue with the disable local firewall prompt")
+		return false, errors.New("there was an issue with the verify ports prompt")
 	}
 	log.Info(messageText)
 	log.Info(fmt.Sprintf("%v", name))
 	return name, nil
 }
 
-func LinuxSecurityPrompt(osType config.OperatingSystem) (bool, error) {
-	name := false
-	if osType == config.Redhat7 || osType == config.Redhat8 || osType == config.Redhat9 {
-		name = true
-		messageText := "SELinux is often enabled by default on Redhat Linux distributions. \nWe recommend that SELinux be" + " disabled, unless you and your organization have \nspecific security requirements that require its use.\n" + "Would you like to disable SELinux on this server?"
-		prompt := &survey.Confirm{
-			Message: messageText,
+// Choices offered by LinuxSecurityPrompt
+const (
+	SELinuxEnforcing = "Keep SELinux enforcing and configure it for Workbench"
+	SELinuxDisable   = "Disable SELinux"
+	SELinuxSkip      = "Leave SELinux unchanged"
+)
+
+// LinuxSecurityPrompt asks users whether to configure SELinux for Workbench or disable it
+func LinuxSecurityPrompt(osType config.OperatingSystem) (string, error) {
+	if osType != config.Redhat7 && osType != config.Redhat8 && osType != config.Redhat9 {
+		return SELinuxSkip, nil
+	}
+	if answers.Loaded() {
+		// answers files written before security.selinux was added only set security.disable_selinux
+		if answers.Get().Security.SELinux == nil && answers.Get().Security.DisableSELinux != nil {
+			disable, err := answers.Require(answers.Get().Security.DisableSELinux, "security.disable_selinux")
+			if err != nil || !disable {
+				return SELinuxSkip, err
+			}
+			return SELinuxDisable, nil
 		}
-		err := survey.AskOne(prompt, &name)
+		action, err := answers.RequireOneOf(answers.Get().Security.SELinux, "security.selinux", []string{"enforcing", "disable", "skip"})
 		if err != nil {
-			return false, errors.New("there was an issue with the disable local firewall prompt")
+			return "", err
 		}
-		log.Info(messageText)
-		log.Info(fmt.Sprintf("%v", name))
+		switch action {
+		case "enforcing":
+			return SELinuxEnforcing, nil
+		case "disable":
+			return SELinuxDisable, nil
+		default:
+			return SELinuxSkip, nil
+		}
+	}
+	choice := ""
+	messageText := "SELinux is enforcing on this server. Workbench can run with SELinux enforcing once its install\n" +
+		" directories are labeled and the booleans it needs are set, or SELinux can be disabled if your\n" +
+		" organization doesn't require it. What would you like to do?"
+	prompt := &survey.Select{
+		Message: messageText,
+		Options: []string{SELinuxEnforcing, SELinuxDisable, SELinuxSkip},
+		Default: SELinuxEnforcing,
 	}
+	err := survey.AskOne(prompt, &choice)
+	if err != nil {
+		return "", errors.New("there was an issue with the SELinux prompt")
+	}
+	log.Info(messageText)
+	log.Info(choice)
+	return choice, nil
+}
+
+// AppArmorPrompt asks users whether to put the AppArmor profiles that confine Workbench, R or Python into complain mode
+func AppArmorPrompt(profiles []AppArmorProfile) (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Security.AppArmorComplain, "security.apparmor_complain")
+	}
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, "  "+profile.Name)
+	}
+	name := true
+	messageText := "The following AppArmor profiles are enforcing and can stop Workbench sessions, R or Python from working:\n" +
+		strings.Join(names, "\n") + "\nWould you like to put only these profiles into complain mode? AppArmor stays enabled for everything else."
+	prompt := &survey.Confirm{
+		Message: messageText,
+	}
+	err := survey.AskOne(prompt, &name)
+	if err != nil {
+		return false, errors.New("there was an issue with the AppArmor complain mode prompt")
+	}
+	log.Info(messageText)
+	log.Info(fmt.Sprintf("%v", name))
 	return name, nil
 }
 
 func PromptInstallPrereqs() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Prereqs.Confirm, "prereqs.confirm")
+	}
 	var name bool
 	messageText := "In order to install Workbench from start to finish, you will need the following things\n" +
 		"1. Internet access for this server\n" +
@@ -83,6 +205,9 @@ func PromptInstallPrereqs() (bool, error) {
 
 // PromptUserAccount prompts the user for the name of a local Linux user account to use for verifying the installation
 func PromptUserAccount() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Verify.User, "verify.user")
+	}
 	target := ""
 	messageText := "Enter a non-root local Linux account username to use for testing the Workbench installation:"
 	prompt := &survey.Input{
@@ -0,0 +1,173 @@
+package operatingsystem
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// selinuxFileContext labels the paths matching a semanage fcontext regular expression with an SELinux type
+type selinuxFileContext struct {
+	pattern string
+	setype  string
+}
+
+// selinuxFileContexts label the executables and shared libraries of Workbench, R, Python and Quarto like the
+// ones in /usr/bin and /usr/lib, so they keep working for confined users with SELinux enforcing
+var selinuxFileContexts = []selinuxFileContext{
+	{"/usr/lib/rstudio-server/bin(/.*)?", "bin_t"},
+	{"/opt/R/[^/]+/bin(/.*)?", "bin_t"},
+	{"/opt/R/[^/]+/lib/R/bin(/.*)?", "bin_t"},
+	{"/opt/R/[^/]+/lib/R/lib(/.*)?", "lib_t"},
+	{"/opt/python/[^/]+/bin(/.*)?", "bin_t"},
+	{"/opt/python/[^/]+/lib(/.*)?", "lib_t"},
+	{"/opt/quarto/[^/]+/bin(/.*)?", "bin_t"},
+}
+
+// selinuxInstallTrees are relabeled with restorecon once the file contexts are in place
+var selinuxInstallTrees = []string{"/usr/lib/rstudio-server", "/opt/R", "/opt/python", "/opt/quarto"}
+
+// selinuxDenialFilter matches the audit records of Workbench, R, Python and Quarto
+const selinuxDenialFilter = "rserver|rsession|rstudio|/opt/(R|python|quarto)/"
+
+// ApplySELinuxPolicy keeps SELinux enforcing and configures it for Workbench: the install trees are labeled,
+// the booleans R packages rely on are set and a non-default www-port is labeled as a web port
+func ApplySELinuxPolicy(osType config.OperatingSystem) error {
+	toolsPackage := "policycoreutils-python-utils"
+	if osType == config.Redhat7 {
+		toolsPackage = "policycoreutils-python"
+	}
+	toolsCommand := "yum install -y " + toolsPackage
+	err := system.RunCommand(toolsCommand, true, 1, true)
+	if err != nil {
+		return fmt.Errorf("issue installing the SELinux management tools with the command '%s': %w", toolsCommand, err)
+	}
+
+	for _, fileContext := range selinuxFileContexts {
+		fcontextCommand := fileContextCommand(fileContext)
+		err := system.RunCommand(fcontextCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue adding an SELinux file context with the command '%s': %w", fcontextCommand, err)
+		}
+	}
+
+	// R packages with compiled code commonly need to relocate text segments of their shared libraries
+	booleans := []string{"selinuxuser_execmod"}
+	homeFSCommand := "findmnt -n -o FSTYPE -T /home || true"
+	homeFS, err := system.RunCommandAndCaptureOutput(homeFSCommand, false, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue checking the /home file system with the command '%s': %w", homeFSCommand, err)
+	}
+	if strings.HasPrefix(strings.TrimSpace(homeFS), "nfs") {
+		booleans = append(booleans, "use_nfs_home_dirs")
+	}
+	for _, boolean := range booleans {
+		setseboolCommand := "setsebool -P " + boolean + " 1"
+		err := system.RunCommand(setseboolCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue setting an SELinux boolean with the command '%s': %w", setseboolCommand, err)
+		}
+	}
+
+	err = RelabelSELinux()
+	if err != nil {
+		return err
+	}
+
+	system.PrintAndLogInfo("\nSELinux remains enforcing and has been configured for Workbench, R, Python and Quarto!")
+	return nil
+}
+
+// RelabelSELinux labels a non-default www-port and applies the file contexts to the install trees that exist,
+// it is run again once Workbench, R, Python and Quarto are installed
+func RelabelSELinux() error {
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	wwwPort, err := workbenchWWWPort(rserverConf)
+	if err != nil {
+		return err
+	}
+	if portCommand := portContextCommand(wwwPort); portCommand != "" {
+		err := system.RunCommand(portCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue labeling the Workbench port with the command '%s': %w", portCommand, err)
+		}
+	}
+
+	var trees []string
+	for _, tree := range selinuxInstallTrees {
+		if _, err := os.Stat(tree); err == nil {
+			trees = append(trees, tree)
+		}
+	}
+	if len(trees) == 0 {
+		return nil
+	}
+	restoreconCommand := "restorecon -R " + strings.Join(trees, " ")
+	err = system.RunCommand(restoreconCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue relabeling the install directories with the command '%s': %w", restoreconCommand, err)
+	}
+	return nil
+}
+
+// fileContextCommand adds a file context, or modifies it when semanage already has one for the pattern
+func fileContextCommand(fileContext selinuxFileContext) string {
+	return fmt.Sprintf("semanage fcontext -a -t %[1]s '%[2]s' 2>/dev/null || semanage fcontext -m -t %[1]s '%[2]s'", fileContext.setype, fileContext.pattern)
+}
+
+// portContextCommand labels a non-default www-port as http_port_t, it returns an empty string for the
+// default ports 8787, 443 and 80
+func portContextCommand(port int) string {
+	if port == defaultWWWPort || port == defaultSSLPort || port == defaultHTTPPort {
+		return ""
+	}
+	p := strconv.Itoa(port)
+	return "semanage port -a -t http_port_t -p tcp " + p + " 2>/dev/null || semanage port -m -t http_port_t -p tcp " + p
+}
+
+// SELinuxPolicyApplied returns true when SELinux is enforcing and ApplySELinuxPolicy has added the Workbench file contexts
+func SELinuxPolicyApplied(osType config.OperatingSystem) (bool, error) {
+	if osType != config.Redhat7 && osType != config.Redhat8 && osType != config.Redhat9 {
+		return false, nil
+	}
+	enforceStatus, err := system.RunCommandAndCaptureOutput("getenforce || true", false, 0, false)
+	if err != nil {
+		return false, fmt.Errorf("issue running the getenforce command: %w", err)
+	}
+	if !strings.Contains(enforceStatus, "Enforcing") {
+		return false, nil
+	}
+	customizationsCommand := "semanage fcontext -l -C 2>/dev/null || true"
+	customizations, err := system.RunCommandAndCaptureOutput(customizationsCommand, false, 0, false)
+	if err != nil {
+		return false, fmt.Errorf("issue listing the SELinux file contexts with the command '%s': %w", customizationsCommand, err)
+	}
+	return strings.Contains(customizations, selinuxFileContexts[0].pattern), nil
+}
+
+// ReportSELinuxDenials explains the SELinux denials of Workbench, R, Python and Quarto in the last ten
+// minutes of the audit log with audit2why
+func ReportSELinuxDenials() error {
+	denialsCommand := "ausearch -m AVC,USER_AVC -ts recent --raw 2>/dev/null | grep -E '" + selinuxDenialFilter + "' | audit2why 2>/dev/null || true"
+	denials, err := system.RunCommandAndCaptureOutput(denialsCommand, false, 0, false)
+	if err != nil {
+		return fmt.Errorf("issue checking for SELinux denials with the command '%s': %w", denialsCommand, err)
+	}
+	denials = strings.TrimSpace(denials)
+	if denials == "" {
+		system.PrintAndLogInfo("\nNo SELinux denials for Workbench, R, Python or Quarto were found in the audit log")
+		return nil
+	}
+	system.PrintAndLogInfo("\n=== SELinux denials for Workbench, R, Python or Quarto ===\n" + denials)
+	system.PrintAndLogInfo("\nTo build a local policy module that allows these, review the output of:\n" +
+		"  ausearch -m AVC,USER_AVC -ts recent --raw | grep -E '" + selinuxDenialFilter + "' | audit2allow -M wbi-workbench")
+	return nil
+}
@@ -0,0 +1,18 @@
+package operatingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileContextCommand(t *testing.T) {
+	command := fileContextCommand(selinuxFileContext{"/opt/R/[^/]+/bin(/.*)?", "bin_t"})
+	assert.Equal(t, "semanage fcontext -a -t bin_t '/opt/R/[^/]+/bin(/.*)?' 2>/dev/null || semanage fcontext -m -t bin_t '/opt/R/[^/]+/bin(/.*)?'", command)
+}
+
+func TestPortContextCommand(t *testing.T) {
+	assert.Equal(t, "", portContextCommand(8787))
+	assert.Equal(t, "", portContextCommand(443))
+	assert.Equal(t, "semanage port -a -t http_port_t -p tcp 8443 2>/dev/null || semanage port -m -t http_port_t -p tcp 8443", portContextCommand(8443))
+}
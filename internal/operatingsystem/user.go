@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
@@ -23,10 +24,19 @@ func PromptAndVerifyUser() (string, bool, error) {
 		// lookup user account details
 		user, err := UserLookup(userAccount)
 		if err != nil {
+			if answers.Loaded() {
+				return "", false, answers.InvalidError("verify.user", fmt.Sprintf(`the user account "%s" cannot be found`, userAccount))
+			}
 			system.PrintAndLogInfo(fmt.Sprintf(`The user account "%s" you entered cannot be found. Please try again. To skip this section type "skip".`, userAccount))
 		} else if user.Uid == "0" {
+			if answers.Loaded() {
+				return "", false, answers.InvalidError("verify.user", fmt.Sprintf(`the user account "%s" is root, a non-root user is required`, userAccount))
+			}
 			system.PrintAndLogInfo(fmt.Sprintf(`The user account "%s" is root. A non-root user is required. Please try again. To skip this section type "skip".`, userAccount))
 		} else if user.HomeDir == "" {
+			if answers.Loaded() {
+				return "", false, answers.InvalidError("verify.user", fmt.Sprintf(`the user account "%s" does not have a home directory`, userAccount))
+			}
 			system.PrintAndLogInfo(fmt.Sprintf(`The user account "%s" does not have a home directory. A home directory is required. Please try again. To skip this section type "skip".`, userAccount))
 		} else {
 			system.PrintAndLogInfo(fmt.Sprintf("user %s account found and validated", userAccount))
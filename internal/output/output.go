@@ -0,0 +1,60 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is how commands print their results
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+var format = Text
+
+// Set sets the output format from its name
+func Set(name string) error {
+	switch Format(name) {
+	case Text, JSON, YAML:
+		format = Format(name)
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %s, please provide one of the following: text, json, yaml", name)
+	}
+}
+
+// Current returns the output format
+func Current() Format {
+	return format
+}
+
+// Structured returns true when results are printed as JSON or YAML instead of text
+func Structured() bool {
+	return format != Text
+}
+
+// Print writes a result to stdout in the structured output format
+func Print(v any) error {
+	return Write(os.Stdout, v)
+}
+
+// Write writes a result in the structured output format, JSON when the format is text
+func Write(w io.Writer, v any) error {
+	if format == YAML {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(v)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type result struct {
+	Name   string `json:"name" yaml:"name"`
+	Active bool   `json:"active" yaml:"active"`
+}
+
+func TestSetAndWrite(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, Set("text")) })
+
+	err := Set("xml")
+	assert.ErrorContains(t, err, "invalid output format xml")
+	assert.False(t, Structured())
+
+	require.NoError(t, Set("json"))
+	assert.True(t, Structured())
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, []result{{Name: "rstudio-server", Active: true}}))
+	assert.Equal(t, "[\n  {\n    \"name\": \"rstudio-server\",\n    \"active\": true\n  }\n]\n", out.String())
+
+	require.NoError(t, Set("yaml"))
+	out.Reset()
+	require.NoError(t, Write(&out, []result{{Name: "rstudio-server", Active: true}}))
+	assert.Equal(t, "- name: rstudio-server\n  active: true\n", out.String())
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/mirrors"
 )
 
 func BuildPackagemanagerFullURL(url string, repo string, osType config.OperatingSystem, language string) (string, error) {
@@ -28,7 +29,7 @@ func BuildPublicPackageManagerFullURL(osType config.OperatingSystem) (string, er
 		return "", errors.New("there was an issue converting the operating system type to an os name")
 	}
 
-	fullURL := "https://packagemanager.rstudio.com/cran/__linux__/" + osName + "/" + "latest"
+	fullURL := mirrors.URL(mirrors.PackageManager, "/cran/__linux__/") + osName + "/" + "latest"
 
 	return fullURL, nil
 }
@@ -40,6 +41,8 @@ func ConvertOSTypeToOSName(osType config.OperatingSystem) (string, error) {
 		osName = "focal"
 	case config.Ubuntu22:
 		osName = "jammy"
+	case config.Ubuntu24:
+		osName = "noble"
 	case config.Redhat7:
 		osName = "centos7"
 	case config.Redhat8:
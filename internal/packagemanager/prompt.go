@@ -8,13 +8,29 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 	"github.com/sol-eng/wbi/internal/workbench"
 )
 
 // Prompt users if they wish to add a default Posit Package Manager URL to Workbench
 func PromptPackageManagerChoice() (string, error) {
+	if answers.Loaded() {
+		source, err := answers.RequireOneOf(answers.Get().PackageManager.Source, "packagemanager.source", []string{"private", "public", "skip"})
+		if err != nil {
+			return "", err
+		}
+		switch source {
+		case "private":
+			return "Posit Package Manager", nil
+		case "public":
+			return "Posit Public Package Manager", nil
+		default:
+			return "Skip", nil
+		}
+	}
 	choice := ""
 	messageText := "Would you like to setup Posit Package Manager or Posit Public Package Manager as the default R and/or Python repo for Workbench? You will need connectivity to the Package Manager server to use this option."
 	prompt := &survey.Select{
@@ -63,6 +79,8 @@ func InteractivePackageManagerPrompts(osType config.OperatingSystem) error {
 		}
 		if goodURL {
 			break
+		} else if answers.Loaded() {
+			return answers.InvalidError("packagemanager.url", "Posit Package Manager could not be reached at "+rawPackageManagerURL)
 		} else {
 			system.PrintAndLogInfo(`The URL you entered is not valid. Please try again. To skip this section type "skip".`)
 		}
@@ -94,6 +112,8 @@ func InteractivePackageManagerPrompts(osType config.OperatingSystem) error {
 
 			if goodRepoR {
 				break
+			} else if answers.Loaded() {
+				return answers.InvalidError("packagemanager.r_repo", "the repository "+repoPackageManager+" was not found in Posit Package Manager")
 			} else {
 				system.PrintAndLogInfo(`The repo you entered is not valid. Please try again. To skip this section type "skip".`)
 			}
@@ -107,11 +127,7 @@ func InteractivePackageManagerPrompts(osType config.OperatingSystem) error {
 
 			err = workbench.WriteRepoConfig(packageManagerURLFull, "cran")
 			if err != nil {
-				if strings.Contains(err.Error(), "line already exists in repos.conf") {
-					system.PrintAndLogInfo("CRAN repo already exists in /etc/rstudio/repos.conf. Skipping writing to the file.")
-				} else {
-					return fmt.Errorf("failed to write CRAN repo config: %w", err)
-				}
+				return fmt.Errorf("failed to write CRAN repo config: %w", err)
 			}
 		}
 	}
@@ -141,6 +157,8 @@ func InteractivePackageManagerPrompts(osType config.OperatingSystem) error {
 
 			if goodRepoPython {
 				break
+			} else if answers.Loaded() {
+				return answers.InvalidError("packagemanager.python_repo", "the repository "+repoPackageManagerPython+" was not found in Posit Package Manager")
 			} else {
 				system.PrintAndLogInfo(`The repo you entered is not valid. Please try again. To skip this section type "skip".`)
 			}
@@ -153,11 +171,7 @@ func InteractivePackageManagerPrompts(osType config.OperatingSystem) error {
 			}
 			err = workbench.WriteRepoConfig(packageManagerURLFull, "pypi")
 			if err != nil {
-				if strings.Contains(err.Error(), "line already exists in pip.conf") {
-					system.PrintAndLogInfo("PyPI URL already exists in /etc/pip.conf. Skipping writing to the file.")
-				} else {
-					return fmt.Errorf("failed to write PyPI repo config: %w", err)
-				}
+				return fmt.Errorf("failed to write PyPI repo config: %w", err)
 			}
 		}
 	}
@@ -165,7 +179,7 @@ func InteractivePackageManagerPrompts(osType config.OperatingSystem) error {
 }
 
 func VerifyAndBuildPublicPackageManager(osType config.OperatingSystem) error {
-	publicPackageManagerURL := "https://packagemanager.posit.co"
+	publicPackageManagerURL := mirrors.Base(mirrors.PackageManager)
 
 	// verify URL
 	_, err := VerifyPackageManagerURL(publicPackageManagerURL)
@@ -191,6 +205,9 @@ func VerifyAndBuildPublicPackageManager(osType config.OperatingSystem) error {
 
 // Prompt users for a default Posit Package Manager URL
 func PromptPackageManagerURL() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().PackageManager.URL, "packagemanager.url")
+	}
 	target := ""
 	messageText := "Enter your Posit Package Manager base URL (for example, https://exampleaddress.com):"
 	prompt := &survey.Input{
@@ -216,6 +233,13 @@ func PromptPackageManagerRepo(language string) (string, error) {
 		return "", errors.New("language not supported for Posit Package Manager")
 	}
 
+	if answers.Loaded() {
+		if language == "r" {
+			return answers.Require(answers.Get().PackageManager.RRepo, "packagemanager.r_repo")
+		}
+		return answers.Require(answers.Get().PackageManager.PythonRepo, "packagemanager.python_repo")
+	}
+
 	languageTitle := strings.Title(language)
 
 	target := ""
@@ -269,6 +293,9 @@ func PromptPackageManagerNameAndBuildURL(cleanURL string, osType config.Operatin
 
 // Prompt asking users which language repos they will use
 func PromptLanguageRepos() ([]string, error) {
+	if answers.Loaded() {
+		return answers.RequireSubsetOf(answers.Get().PackageManager.Languages, "packagemanager.languages", []string{"r", "python"})
+	}
 	messageText := "What language repositories would you like to setup?"
 	var qs = []*survey.Question{
 		{
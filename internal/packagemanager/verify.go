@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"time"
 
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
@@ -26,9 +26,7 @@ func VerifyPackageManagerURL(packageManagerURL string) (string, error) {
 	cleanPackageManagerURL := cleanPackageManagerURL(packageManagerURL)
 	fullTestURL := cleanPackageManagerURL + "/__ping__"
 
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
 		http.MethodGet, fullTestURL, nil)
 	if err != nil {
@@ -51,9 +49,7 @@ func VerifyPackageManagerURL(packageManagerURL string) (string, error) {
 func VerifyPackageManagerRepo(packageManagerURL string, packageManagerRepo string, language string) error {
 	repoSearchURL := packageManagerURL + "/__api__/repos?type=" + language
 
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
 		http.MethodGet, repoSearchURL, nil)
 	if err != nil {
@@ -7,11 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/install"
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
@@ -21,6 +22,7 @@ type InstallerInfo struct {
 	URL      string `json:"url"`
 	Version  string `json:"version"`
 	Label    string `json:"label"`
+	SHA256   string `json:"sha256"`
 }
 
 // OperatingSystems contains the installer information for each supported operating system
@@ -49,7 +51,7 @@ func DownloadAndInstallProDrivers(osType config.OperatingSystem) error {
 		return fmt.Errorf("RetrieveProDriversInstallerInfo: %w", err)
 	}
 	// Retrieve installer info
-	installerInfo, err := rstudio.GetInstallerInfo(osType)
+	installerInfo, err := rstudio.GetInstallerInfo(osType, config.HostArchitecture())
 	if err != nil {
 		return fmt.Errorf("GetInstallerInfo: %w", err)
 	}
@@ -59,15 +61,21 @@ func DownloadAndInstallProDrivers(osType config.OperatingSystem) error {
 		return fmt.Errorf("InstallUnixODBC: %w", err)
 	}
 	// Download installer
-	filepath, err := install.DownloadFile("Pro Drivers", installerInfo.URL, installerInfo.BaseName)
+	filepath, err := install.DownloadFile("Pro Drivers", installerInfo.URL, installerInfo.BaseName, installerInfo.SHA256)
 	if err != nil {
 		return fmt.Errorf("DownloadFile: %w", err)
 	}
+	// Verify the package is signed by Posit
+	err = install.VerifyPackageSignature(filepath, osType)
+	if err != nil {
+		return fmt.Errorf("VerifyPackageSignature: %w", err)
+	}
 	// Install Pro Drivers
 	err = InstallProDrivers(filepath, osType)
 	if err != nil {
 		return fmt.Errorf("InstallProDrivers: %w", err)
 	}
+	install.RemoveDownload(filepath)
 
 	// save to command log
 	installCommand, err := install.RetrieveInstallCommand(installerInfo.BaseName, osType)
@@ -75,6 +83,9 @@ func DownloadAndInstallProDrivers(osType config.OperatingSystem) error {
 		return fmt.Errorf("RetrieveInstallCommand: %w", err)
 	}
 	cmdlog.Info("curl -O " + installerInfo.URL)
+	if installerInfo.SHA256 != "" {
+		cmdlog.Info(install.SHA256CheckCommand(installerInfo.SHA256, installerInfo.BaseName))
+	}
 	cmdlog.Info(installCommand)
 
 	// Configure ODBC driver name and locations
@@ -104,28 +115,33 @@ func InstallProDrivers(filepath string, osType config.OperatingSystem) error {
 }
 
 // Pulls out the installer information from the JSON data based on the operating system
-func (pd *ProDrivers) GetInstallerInfo(osType config.OperatingSystem) (InstallerInfo, error) {
+func (pd *ProDrivers) GetInstallerInfo(osType config.OperatingSystem, arch config.Architecture) (InstallerInfo, error) {
+	if arch == config.ARM64 {
+		return InstallerInfo{}, errors.New("the Posit Pro Drivers have no arm64 build")
+	}
+	var info InstallerInfo
 	switch osType {
 	// Posit Pro Drivers are the same for all Ubuntu versions
-	case config.Ubuntu20, config.Ubuntu22:
-		return pd.ProDrivers.Installer.Focal, nil
+	case config.Ubuntu20, config.Ubuntu22, config.Ubuntu24:
+		info = pd.ProDrivers.Installer.Focal
 	case config.Redhat7:
-		return pd.ProDrivers.Installer.Redhat7, nil
+		info = pd.ProDrivers.Installer.Redhat7
 	// Posit Pro Drivers are the same for RHEL 8 and RHEL 9
 	case config.Redhat8, config.Redhat9:
-		return pd.ProDrivers.Installer.Redhat8, nil
+		info = pd.ProDrivers.Installer.Redhat8
 	default:
 		return InstallerInfo{}, errors.New("operating system not supported")
 	}
+	// downloads.json lists upstream URLs, which are remapped to any configured mirror
+	info.URL = mirrors.Rewrite(info.URL)
+	return info, nil
 }
 
 // Retrieves JSON data from Posit
 func RetrieveProDriversInstallerInfo() (ProDrivers, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
-		http.MethodGet, "https://www.rstudio.com/wp-content/downloads.json", nil)
+		http.MethodGet, mirrors.URL(mirrors.PositDownloads, "/wp-content/downloads.json"), nil)
 	if err != nil {
 		return ProDrivers{}, errors.New("error creating request")
 	}
@@ -147,7 +163,7 @@ func RetrieveProDriversInstallerInfo() (ProDrivers, error) {
 
 // Installs unixODBC and unixODBC-devel
 func InstallUnixODBC(osType config.OperatingSystem) error {
-	if osType == config.Ubuntu22 || osType == config.Ubuntu20 {
+	if osType == config.Ubuntu24 || osType == config.Ubuntu22 || osType == config.Ubuntu20 {
 		prereqCommand := "apt-get -y install unixodbc unixodbc-dev"
 		err := system.RunCommand(prereqCommand, true, 1, true)
 		if err != nil {
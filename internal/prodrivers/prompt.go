@@ -6,12 +6,16 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
 // Prompt users if they would like to install Posit Pro Drivers
 func ProDriversInstallPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().ProDrivers.Install, "prodrivers.install")
+	}
 	name := true
 	messageText := "Would you like to install Post Pro Drivers?"
 	prompt := &survey.Confirm{
@@ -27,6 +31,10 @@ func ProDriversInstallPrompt() (bool, error) {
 }
 
 func CheckPromptDownloadAndInstallProDrivers(osType config.OperatingSystem) error {
+	if config.HostArchitecture() == config.ARM64 {
+		system.PrintAndLogInfo("Posit Pro Drivers have no arm64 build, skipping the Pro Drivers installation")
+		return nil
+	}
 	proDriversExistingStatus, err := CheckExistingProDrivers()
 	if err != nil {
 		return fmt.Errorf("issue in checking for prior pro driver installation: %w", err)
@@ -1,16 +1,23 @@
 package quarto
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/sol-eng/wbi/internal/languages"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
+// bundledQuartoPath is the Quarto installed with Workbench
+const bundledQuartoPath = "/usr/lib/rstudio-server/bin/quarto/bin/quarto"
+
 // ScanForBundledQuartoVersion scans for the bundled version of Quarto
 func ScanForBundledQuartoVersion() (string, error) {
-	quartoPath := "/usr/lib/rstudio-server/bin/quarto/bin/quarto"
-	versionCommand := quartoPath + " --version"
+	versionCommand := bundledQuartoPath + " --version"
 	quartoVersion, err := system.RunCommandAndCaptureOutput(versionCommand, false, 0, false)
 	if err != nil {
 		return "", fmt.Errorf("issue finding Quarto version: %w", err)
@@ -20,7 +27,7 @@ func ScanForBundledQuartoVersion() (string, error) {
 
 func checkForBundledQuartoVersion() (bool, error) {
 	// check if /usr/lib/rstudio-server/bin/quarto/bin/quarto exists
-	_, err := os.Stat("/usr/lib/rstudio-server/bin/quarto/bin/quarto")
+	_, err := os.Stat(bundledQuartoPath)
 	if err == nil {
 		return true, nil
 	} else if os.IsNotExist(err) {
@@ -66,3 +73,47 @@ func checkIfQuartoSymlinkExists() bool {
 	system.PrintAndLogInfo("\nAn existing Quarto symlink has been detected (/usr/local/bin/quarto)")
 	return true
 }
+
+// ScanQuartoInstallations scans for the Quarto bundled with Workbench, versions installed in /opt/quarto and
+// Quarto on the PATH, running each one to find its version
+func ScanQuartoInstallations() ([]languages.Installation, error) {
+	var quartoPaths []string
+	bundled, err := checkForBundledQuartoVersion()
+	if err != nil {
+		return nil, fmt.Errorf("issue checking for bundled Quarto version: %w", err)
+	}
+	if bundled {
+		quartoPaths = append(quartoPaths, bundledQuartoPath)
+	}
+	optPaths, err := filepath.Glob("/opt/quarto/*/bin/quarto")
+	if err != nil {
+		return nil, fmt.Errorf("issue scanning /opt/quarto: %w", err)
+	}
+	quartoPaths = append(quartoPaths, optPaths...)
+	if pathQuarto, err := exec.LookPath("quarto"); err == nil && !system.ContainsPath(quartoPaths, pathQuarto) {
+		quartoPaths = append(quartoPaths, pathQuarto)
+	}
+
+	return languages.DescribeInstallations(quartoPaths, []string{"quarto"}, quartoSource, probeQuarto), nil
+}
+
+func quartoSource(path string) string {
+	if path == bundledQuartoPath {
+		return "bundled"
+	} else if strings.HasPrefix(path, "/opt/quarto/") {
+		return "opt"
+	}
+	return "system"
+}
+
+func probeQuarto(path string) (string, string, error) {
+	out, err := system.RunCommandAndCaptureOutput(path+" --version", false, 0, false)
+	if err != nil {
+		return "", "", err
+	}
+	quartoVersion := strings.TrimSpace(out)
+	if quartoVersion == "" || strings.Contains(quartoVersion, "\n") {
+		return "", "", errors.New("quarto did not report its version")
+	}
+	return quartoVersion, "", nil
+}
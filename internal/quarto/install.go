@@ -9,16 +9,19 @@ import (
 	"github.com/samber/lo"
 	"io"
 	"net/http"
-	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/install"
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
@@ -37,14 +40,22 @@ type result struct {
 	err   error
 }
 
+// ReleasesPages is the number of pages of GitHub releases searched for Quarto versions
+const ReleasesPages = 4
+
+// ReleasesURL returns the GitHub API URL for a page of Quarto releases
+func ReleasesURL(page int) string {
+	return mirrors.URL(mirrors.GitHubAPI, "/repos/quarto-dev/quarto-cli/releases?per_page=100&page=") + strconv.Itoa(page)
+}
+
 func RetrieveValidQuartoVersions() ([]string, error) {
 	var availQuartoVersions []string
 	var results []result
 	var quarto Quarto
 	var urls []string
 
-	for pagenum := 1; pagenum < 5; pagenum++ {
-		urls = append(urls, "https://api.github.com/repos/quarto-dev/quarto-cli/releases?per_page=100&page="+strconv.Itoa(pagenum))
+	for pagenum := 1; pagenum <= ReleasesPages; pagenum++ {
+		urls = append(urls, ReleasesURL(pagenum))
 	}
 	wg := sync.WaitGroup{}
 
@@ -53,7 +64,7 @@ func RetrieveValidQuartoVersions() ([]string, error) {
 
 		go func(i int, url string) {
 
-			res, err := http.Get(url)
+			res, err := httpclient.New().Get(url)
 			if err != nil {
 				return
 			}
@@ -114,11 +125,19 @@ func DownloadAndInstallQuartoVersions(quartoVersions []string, osType config.Ope
 
 func DownloadAndInstallQuarto(quartoVersion string, osType config.OperatingSystem) error {
 	// Find URL
-	quartoURL := GenerateQuartoInstallURL(quartoVersion, osType)
+	quartoURL, err := GenerateQuartoInstallURL(quartoVersion, osType, config.HostArchitecture())
+	if err != nil {
+		return fmt.Errorf("GenerateQuartoInstallURL: %w", err)
+	}
+	// Find the expected digest from the release checksums
+	checksum, err := RetrieveQuartoChecksum(quartoVersion, quartoURL)
+	if err != nil {
+		return fmt.Errorf("RetrieveQuartoChecksum: %w", err)
+	}
 	// Download installer
-	installerPath, err := downloadFileQuarto(quartoURL, quartoVersion)
+	installerPath, err := install.DownloadFile("Quarto", quartoURL, filepath.Base(quartoURL), checksum)
 	if err != nil {
-		return fmt.Errorf("DownloadFileQuarto: %w", err)
+		return fmt.Errorf("DownloadFile: %w", err)
 	}
 	// Install Quarto
 	err = installQuarto(installerPath, osType, quartoVersion, true)
@@ -126,75 +145,73 @@ func DownloadAndInstallQuarto(quartoVersion string, osType config.OperatingSyste
 	if err != nil {
 		return fmt.Errorf("InstallQuarto: %w", err)
 	}
+	install.RemoveDownload(installerPath)
 	// save to command log
 	quartoPath := fmt.Sprintf("/opt/quarto/%s", quartoVersion)
 	cmdlog.Info("curl -o quarto.tar.gz -L " + quartoURL)
+	if checksum != "" {
+		cmdlog.Info(install.SHA256CheckCommand(checksum, "quarto.tar.gz"))
+	}
 	cmdlog.Info("mkdir -p " + quartoPath)
 	cmdlog.Info(fmt.Sprintf(`tar -zxvf quarto.tar.gz -C "%s" --strip-components=1`, quartoPath))
 	cmdlog.Info("rm quarto.tar.gz")
 	return nil
 }
 
-func GenerateQuartoInstallURL(quartoVersion string, osType config.OperatingSystem) string {
+func GenerateQuartoInstallURL(quartoVersion string, osType config.OperatingSystem, arch config.Architecture) (string, error) {
 	// treat RHEL 7 differently as specified here: https://docs.posit.co/resources/install-quarto/#specify-quarto-version-tar
 	var url string
 	if osType == config.Redhat7 {
-		url = fmt.Sprintf("https://github.com/quarto-dev/quarto-cli/releases/download/%s/quarto-%s-linux-rhel7-amd64.tar.gz", quartoVersion, strings.Replace(quartoVersion, "v", "", -1))
+		if arch == config.ARM64 {
+			return "", errors.New("quarto has no arm64 build for RHEL 7")
+		}
+		url = mirrors.URL(mirrors.GitHub, fmt.Sprintf("/quarto-dev/quarto-cli/releases/download/%s/quarto-%s-linux-rhel7-amd64.tar.gz", quartoVersion, strings.Replace(quartoVersion, "v", "", -1)))
 	} else {
-		url = fmt.Sprintf("https://github.com/quarto-dev/quarto-cli/releases/download/%s/quarto-%s-linux-amd64.tar.gz", quartoVersion, strings.Replace(quartoVersion, "v", "", -1))
+		url = mirrors.URL(mirrors.GitHub, fmt.Sprintf("/quarto-dev/quarto-cli/releases/download/%s/quarto-%s-linux-%s.tar.gz", quartoVersion, strings.Replace(quartoVersion, "v", "", -1), arch.ToString()))
 	}
-	return url
+	return url, nil
 }
 
-func downloadFileQuarto(url string, version string) (string, error) {
-	system.PrintAndLogInfo("Downloading Quarto Version: " + version + " installer from: " + url)
-
-	// Create the file
-	filename := "*_" + fmt.Sprintf("quarto-%s-linux-amd64.tar.gz", version)
-	tmpFile, err := os.CreateTemp("", filename)
-	if err != nil {
-		return tmpFile.Name(), err
-	}
-
-	defer func() {
-		if tempErr := tmpFile.Close(); tempErr != nil {
-			err = tempErr
-		}
-	}()
-
-	if err != nil {
-		return tmpFile.Name(), err
-	}
+// ChecksumsURL returns the URL of the file listing the SHA-256 digest of each asset in a Quarto release
+func ChecksumsURL(quartoVersion string) string {
+	return mirrors.URL(mirrors.GitHub, fmt.Sprintf("/quarto-dev/quarto-cli/releases/download/%s/quarto-%s-checksums.txt", quartoVersion, strings.Replace(quartoVersion, "v", "", -1)))
+}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+// RetrieveQuartoChecksum returns the SHA-256 digest of a Quarto installer from the release checksums file,
+// or an empty string when the release doesn't publish one
+func RetrieveQuartoChecksum(quartoVersion string, installerURL string) (string, error) {
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
-		http.MethodGet, url, nil)
+		http.MethodGet, ChecksumsURL(quartoVersion), nil)
 	if err != nil {
 		return "", errors.New("error creating request")
 	}
 	res, err := client.Do(req)
 	if err != nil {
-		return "", errors.New("error downloading " + filename + " installer")
+		return "", errors.New("error retrieving the Quarto checksums file")
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		system.PrintAndLogInfo("Quarto version " + quartoVersion + " does not publish a checksums file, the download will not be verified")
+		return "", nil
 	}
-	defer func() {
-		if tempErr := res.Body.Close(); tempErr != nil {
-			err = tempErr
-		}
-	}()
-
 	if res.StatusCode != http.StatusOK {
-		return "", errors.New("error retrieving " + filename + " installer")
+		return "", errors.New("error retrieving the Quarto checksums file")
 	}
-
-	// Writer the body to file
-	_, err = io.Copy(tmpFile, res.Body)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return "", errors.New("error reading the Quarto checksums file")
 	}
 
-	return tmpFile.Name(), nil
+	// each line is "<sha256>  <filename>"
+	installerName := filepath.Base(installerURL)
+	for _, line := range strings.Split(string(body), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == installerName {
+			return fields[0], nil
+		}
+	}
+	return "", errors.New("the Quarto checksums file does not list " + installerName)
 }
 
 // Installs Quarto
@@ -202,16 +219,14 @@ func installQuarto(filepath string, osType config.OperatingSystem, version strin
 
 	// create the /opt/quarto directory if it doesn't exist
 	path := fmt.Sprintf("/opt/quarto/%s", version)
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		err := os.MkdirAll(path, 0755)
-		if err != nil {
-			return fmt.Errorf("error creating directory: %w", err)
-		}
+	err := system.CreateDirectory(path, 0755)
+	if err != nil {
+		return err
 	}
 
 	installCommand := fmt.Sprintf(`tar -zxvf "%s" -C "%s" --strip-components=1`, filepath, path)
 
-	err := system.RunCommand(installCommand, false, 0, false)
+	err = system.RunCommand(installCommand, false, 0, false)
 	if err != nil {
 		return fmt.Errorf("the command '%s' failed to run: %w", installCommand, err)
 	}
@@ -254,6 +269,9 @@ func setQuartoSymlinks(quartoPath string, display bool) error {
 
 // quartoLocationSymlinksPrompt asks users which Quarto binary they want to symlink
 func quartoLocationSymlinksPrompt(quartoPaths []string) (string, error) {
+	if answers.Loaded() {
+		return answers.RequireOneOf(answers.Get().Quarto.SymlinkPath, "quarto.symlink_path", quartoPaths)
+	}
 	// Allow the user to select a version of Quarto to target
 	target := ""
 	messageText := "Select a Quarto binary to symlink:"
@@ -275,6 +293,9 @@ func quartoLocationSymlinksPrompt(quartoPaths []string) (string, error) {
 
 // quartoSymlinkPrompt asks users if they would like to set the quarto symlink
 func quartoSymlinkPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Quarto.Symlink, "quarto.symlink")
+	}
 	name := true
 	messageText := `Would you like to symlink a Quarto version to make it available on PATH? This is recommended so Workbench can default to this version of Quarto in each of the IDEs and users can type "quarto" in the terminal.`
 	prompt := &survey.Confirm{
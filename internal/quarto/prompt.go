@@ -7,6 +7,7 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
 )
 
@@ -92,6 +93,9 @@ func quartoVersionsToPaths(quartoVersions []string) []string {
 }
 
 func PromptQuartoInstall(bundledVersion string) (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Quarto.Install, "quarto.install")
+	}
 	var name bool
 	var messageText string
 	if bundledVersion == "" {
@@ -114,6 +118,9 @@ func PromptQuartoInstall(bundledVersion string) (bool, error) {
 
 // QuartoSelectVersionsPrompt Prompt asking users which Quarto version(s) they would like to install
 func QuartoSelectVersionsPrompt(availableQuartoVersions []string) ([]string, error) {
+	if answers.Loaded() {
+		return answers.RequireSubsetOf(answers.Get().Quarto.Versions, "quarto.versions", availableQuartoVersions)
+	}
 	messageText := "Which version(s) of Quarto would you like to install?"
 	var qs = []*survey.Question{
 		{
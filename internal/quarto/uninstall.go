@@ -0,0 +1,99 @@
+package quarto
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// quartoInstallDir is where wbi installs each version of Quarto
+var quartoInstallDir = "/opt/quarto"
+
+// UninstallQuarto removes a version of Quarto installed in quartoInstallDir and repairs the /usr/local/bin/quarto symlink
+func UninstallQuarto(quartoVersion string) error {
+	quartoDir, err := findQuartoDir(quartoVersion)
+	if err != nil {
+		return err
+	}
+
+	err = system.RemoveDirectory(quartoDir)
+	if err != nil {
+		return fmt.Errorf("issue removing Quarto: %w", err)
+	}
+
+	// point the symlink at the newest remaining version of Quarto in /opt/quarto, or the version bundled with Workbench
+	replacement, err := newestRemainingQuartoPath(quartoDir)
+	if err != nil {
+		return fmt.Errorf("issue scanning for Quarto versions: %w", err)
+	}
+	err = system.RepairSymlink("/usr/local/bin/quarto", quartoDir, replacement)
+	if err != nil {
+		return fmt.Errorf("issue repairing the Quarto symlink: %w", err)
+	}
+
+	system.PrintAndLogInfo("\nQuarto version " + quartoVersion + " successfully uninstalled!\n")
+	return nil
+}
+
+// findQuartoDir returns the install directory of a Quarto version, which is named after the release with or without a
+// leading "v" and must contain bin/quarto
+func findQuartoDir(quartoVersion string) (string, error) {
+	candidates := []string{quartoVersion}
+	if strings.HasPrefix(quartoVersion, "v") {
+		candidates = append(candidates, strings.TrimPrefix(quartoVersion, "v"))
+	} else {
+		candidates = append(candidates, "v"+quartoVersion)
+	}
+	var err error
+	for _, candidate := range candidates {
+		var quartoDir string
+		quartoDir, err = system.VersionDir(quartoInstallDir, candidate, filepath.Join("bin", "quarto"))
+		if err == nil {
+			return quartoDir, nil
+		}
+	}
+	return "", fmt.Errorf("issue finding Quarto version %s: %w", quartoVersion, err)
+}
+
+// newestRemainingQuartoPath returns the newest Quarto binary in /opt/quarto outside of the removed directory,
+// falling back to the version bundled with Workbench
+func newestRemainingQuartoPath(removedDir string) (string, error) {
+	entries, err := os.ReadDir(quartoInstallDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	versions := map[*version.Version]string{}
+	for _, entry := range entries {
+		dir := filepath.Join(quartoInstallDir, entry.Name())
+		quartoPath := filepath.Join(dir, "bin", "quarto")
+		if !entry.IsDir() || dir == removedDir || !system.VerifyFileExists(quartoPath) {
+			continue
+		}
+		v, err := version.NewVersion(entry.Name())
+		if err != nil {
+			continue
+		}
+		versions[v] = quartoPath
+	}
+
+	if len(versions) > 0 {
+		keys := make([]*version.Version, 0, len(versions))
+		for v := range versions {
+			keys = append(keys, v)
+		}
+		sort.Sort(sort.Reverse(version.Collection(keys)))
+		return versions[keys[0]], nil
+	}
+
+	if system.VerifyFileExists("/usr/lib/rstudio-server/bin/quarto/bin/quarto") {
+		return "/usr/lib/rstudio-server/bin/quarto/bin/quarto", nil
+	}
+	return "", nil
+}
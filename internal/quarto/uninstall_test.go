@@ -0,0 +1,75 @@
+package quarto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindQuartoDir(t *testing.T) {
+	root := t.TempDir()
+	originalDir := quartoInstallDir
+	quartoInstallDir = root
+	t.Cleanup(func() { quartoInstallDir = originalDir })
+	for _, dir := range []string{"1.3.340", "v1.2.475"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir, "bin"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, "bin", "quarto"), []byte("#!/bin/sh\n"), 0755))
+	}
+
+	tests := map[string]struct {
+		version     string
+		expected    string
+		expectError string
+	}{
+		"version":                          {version: "1.3.340", expected: filepath.Join(root, "1.3.340")},
+		"version with a v":                 {version: "v1.3.340", expected: filepath.Join(root, "1.3.340")},
+		"directory with a v":               {version: "1.2.475", expected: filepath.Join(root, "v1.2.475")},
+		"not installed":                    {version: "1.4.0", expectError: "version v1.4.0 is not installed"},
+		"bare v resolves to the root":      {version: "v", expectError: "issue finding Quarto version v"},
+		"parent resolves outside":          {version: "..", expectError: "versions can't be empty or contain / or .."},
+		"path within an installed version": {version: "1.3.340/bin", expectError: "versions can't be empty or contain / or .."},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := findQuartoDir(tc.version)
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, dir)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestUninstallQuartoRejectsInvalidVersions(t *testing.T) {
+	root := t.TempDir()
+	originalDir := quartoInstallDir
+	quartoInstallDir = filepath.Join(root, "quarto")
+	t.Cleanup(func() { quartoInstallDir = originalDir })
+	require.NoError(t, os.MkdirAll(filepath.Join(quartoInstallDir, "1.3.340", "bin"), 0755))
+
+	for _, v := range []string{"v", "..", "../quarto", ""} {
+		assert.Error(t, UninstallQuarto(v), v)
+	}
+	assert.DirExists(t, filepath.Join(quartoInstallDir, "1.3.340"))
+}
+
+func TestNewestRemainingQuartoPath(t *testing.T) {
+	root := t.TempDir()
+	originalDir := quartoInstallDir
+	quartoInstallDir = root
+	t.Cleanup(func() { quartoInstallDir = originalDir })
+	for _, dir := range []string{"1.2.475", "1.3.340", "1.4.550"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir, "bin"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, "bin", "quarto"), []byte("#!/bin/sh\n"), 0755))
+	}
+
+	path, err := newestRemainingQuartoPath(filepath.Join(root, "1.4.550"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "1.3.340", "bin", "quarto"), path)
+}
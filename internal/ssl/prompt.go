@@ -9,11 +9,15 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
 // PromptSSL Prompt asking users if they wish to use SSL
 func PromptSSL() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.Enabled, "ssl.enabled")
+	}
 	name := false
 	messageText := "Would you like to use SSL?"
 	prompt := &survey.Confirm{
@@ -112,6 +116,9 @@ func PromptAndVerifySSL(osType config.OperatingSystem) (string, string, error) {
 
 // PromptSSLFilePath Prompt asking users for a filepath to their SSL cert
 func PromptSSLFilePath() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.CertPath, "ssl.cert_path")
+	}
 	target := ""
 	messageText := "Filepath to SSL certificate:"
 	prompt := &survey.Input{
@@ -128,6 +135,9 @@ func PromptSSLFilePath() (string, error) {
 
 // PromptServerURL asks users for the server URL
 func PromptServerURL() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.ServerURL, "ssl.server_url")
+	}
 	target := ""
 	messageText := "Server URL that end users will use to access the Workbench web interface (for example, https://workbench.mydomainname.com):"
 	prompt := &survey.Input{
@@ -144,6 +154,9 @@ func PromptServerURL() (string, error) {
 
 // PromptSSLKeyFilePath Prompt asking users for a filepath to their SSL cert key
 func PromptSSLKeyFilePath() (string, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.KeyPath, "ssl.key_path")
+	}
 	target := ""
 	messageText := "Filepath to SSL certificate key:"
 	prompt := &survey.Input{
@@ -159,6 +172,9 @@ func PromptSSLKeyFilePath() (string, error) {
 }
 
 func PromptMisMatchedHostName() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.AllowHostnameMismatch, "ssl.allow_hostname_mismatch")
+	}
 	name := false
 	messageText := "The hostname of your server and the subject name in the certificate " +
 		"don't match.\n This is common in configurations that include a load balancer " +
@@ -178,6 +194,9 @@ func PromptMisMatchedHostName() (bool, error) {
 }
 
 func PromptAddRootCAToTrustStore() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.TrustRootCA, "ssl.trust_root_ca")
+	}
 	name := true
 	messageText := "The certificate provided is not trusted by the system, this system level trust is usually required" +
 		"\n to support connectivity between systems. Would you like to add this untrusted root certificate " +
@@ -195,6 +214,9 @@ func PromptAddRootCAToTrustStore() (bool, error) {
 }
 
 func PromptRootCAMissing() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().SSL.AllowMissingRootCA, "ssl.allow_missing_root_ca")
+	}
 	name := true
 	messageText := "The certificate provided does not include a root Certificate Authority," +
 		" otherwise known as a Root CA Certificate. Generally, Posit products require a chain of certificates from server to" +
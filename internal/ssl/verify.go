@@ -42,8 +42,8 @@ func VerifySSLHostMatch(serverCert *x509.Certificate) (bool, error) {
 		return certHostMisMatch, fmt.Errorf("failed to retrieve hostname: %w", err)
 	}
 
-	fmt.Println("Detected Server Name: " + hostname)
-	fmt.Println("Detected Certificate Primary DNS Name: " + serverCert.DNSNames[0])
+	system.PrintAndLogInfo("Detected Server Name: " + hostname)
+	system.PrintAndLogInfo("Detected Certificate Primary DNS Name: " + serverCert.DNSNames[0])
 
 	if !strings.Contains(serverCert.DNSNames[0], hostname) {
 		certHostMisMatch = true
@@ -67,10 +67,10 @@ func VerifyTrustedCertificate(serverCert *x509.Certificate, intermediateCA *x509
 
 	_, err = serverCert.Verify(opts)
 	if err != nil {
-		fmt.Println("The server certificate is not trusted by the system:" + err.Error())
+		system.PrintAndLogInfo("The server certificate is not trusted by the system:" + err.Error())
 		return false, nil
 	} else {
-		fmt.Println("This certificate is trusted by system")
+		system.PrintAndLogInfo("This certificate is trusted by system")
 	}
 
 	return true, nil
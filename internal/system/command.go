@@ -6,14 +6,20 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
 )
 
-// Runs a command in the terminal and streams the output
+// Runs a command in the terminal and streams the output, in dry-run mode the command is recorded instead
 func RunCommand(command string, displayCommand bool, delay time.Duration, save bool) error {
+	if DryRun() {
+		RecordCommand(command)
+		return nil
+	}
+
 	if displayCommand {
 		PrintAndLogInfo("Running command: " + command)
 	}
@@ -26,7 +32,8 @@ func RunCommand(command string, displayCommand bool, delay time.Duration, save b
 	cmd := exec.Command("/bin/sh", "-c", command)
 
 	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outBuf)
+	// stdout is kept for structured output, so the command's output goes wherever messages are printed
+	cmd.Stdout = io.MultiWriter(messages, &outBuf)
 
 	err := cmd.Run()
 	if err != nil {
@@ -46,7 +53,15 @@ func RunCommand(command string, displayCommand bool, delay time.Duration, save b
 }
 
 // Runs a command in the terminal and return stdout/stderr as seperate strings
+//
+// In dry-run mode commands saved to the command log are recorded instead of run.
+// Read-only checks (save is false) still run so the plan reflects the current state of the server.
 func RunCommandAndCaptureOutput(command string, displayCommand bool, delay time.Duration, save bool) (string, error) {
+	if DryRun() && save {
+		RecordCommand(command)
+		return "", nil
+	}
+
 	if displayCommand {
 		PrintAndLogInfo("Running command: " + command)
 	}
@@ -67,3 +82,26 @@ func RunCommandAndCaptureOutput(command string, displayCommand bool, delay time.
 
 	return string(out), nil
 }
+
+// RunCommandAndCaptureStdout runs a read-only command and returns its stdout, for commands whose output is parsed
+// such as JSON. Warnings the command prints to stderr are logged instead, or included in the error when it fails.
+func RunCommandAndCaptureStdout(command string, displayCommand bool) (string, error) {
+	if displayCommand {
+		PrintAndLogInfo("Running command: " + command)
+	}
+
+	var errBuf bytes.Buffer
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stderr = &errBuf
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("issue running the command '%s': %w: %s", command, err, strings.TrimSpace(errBuf.String()))
+	}
+	log.Info(string(out))
+	if errBuf.Len() > 0 {
+		log.Warn(errBuf.String())
+	}
+
+	return string(out), nil
+}
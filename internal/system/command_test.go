@@ -0,0 +1,17 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandAndCaptureStdout(t *testing.T) {
+	output, err := RunCommandAndCaptureStdout(`echo '{"kernelspecs":{}}'; echo 'warning: deprecated' >&2`, false)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"kernelspecs\":{}}\n", output)
+
+	_, err = RunCommandAndCaptureStdout("echo 'no such kernel' >&2; exit 1", false)
+	assert.ErrorContains(t, err, "exit status 1: no such kernel")
+}
@@ -2,41 +2,68 @@ package system
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io/fs"
 	"os"
 	"strings"
+
+	"github.com/samber/lo"
+	cmdlog "github.com/sol-eng/wbi/internal/logging"
 )
 
-// DeleteStrings deletes a slice of strings from a file
+// DeleteStrings deletes every line containing any of a slice of strings from a file
 func DeleteStrings(lines []string, filepath string, perm fs.FileMode) error {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+	var content string
+	if DryRun() {
+		planned, exists, err := ReadPlannedFile(filepath)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("failed to open file: %s does not exist", filepath)
+		}
+		content = planned
+	} else {
+		file, err := os.ReadFile(filepath)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		content = string(file)
 	}
-	defer file.Close()
-
-	var bs []byte
-	buf := bytes.NewBuffer(bs)
-
-	fileScanner := bufio.NewScanner(file)
 
+	var buf strings.Builder
+	fileScanner := bufio.NewScanner(strings.NewReader(content))
 	for fileScanner.Scan() {
-		for _, line := range lines {
-			if strings.Contains(fileScanner.Text(), line) {
-				continue
-			}
-			_, err = buf.WriteString(fileScanner.Text() + "\n")
-			if err != nil {
-				return fmt.Errorf("failed to write line: %w", err)
-			}
+		text := fileScanner.Text()
+		if lo.SomeBy(lines, func(line string) bool { return strings.Contains(text, line) }) {
+			continue
 		}
+		buf.WriteString(text + "\n")
 	}
 
-	err = os.WriteFile(filepath, buf.Bytes(), perm)
+	if DryRun() {
+		return RecordFileChange(filepath, buf.String())
+	}
+
+	err := os.WriteFile(filepath, []byte(buf.String()), perm)
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 	return nil
 }
+
+// RemoveDirectory removes a directory and everything in it, in dry-run mode the removal is recorded instead
+func RemoveDirectory(path string) error {
+	removeCommand := "rm -rf " + path
+	if DryRun() {
+		RecordCommand(removeCommand)
+		return nil
+	}
+	PrintAndLogInfo("Removing " + path)
+	err := os.RemoveAll(path)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	cmdlog.Info(removeCommand)
+	return nil
+}
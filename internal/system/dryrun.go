@@ -0,0 +1,154 @@
+package system
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+)
+
+// PlanStepKind is the type of action recorded in a dry-run plan
+type PlanStepKind string
+
+const (
+	PlanCommand  PlanStepKind = "command"
+	PlanFile     PlanStepKind = "file"
+	PlanDownload PlanStepKind = "download"
+	PlanService  PlanStepKind = "service"
+)
+
+// PlanStep is a single action wbi would have taken if it was not running in dry-run mode
+type PlanStep struct {
+	Kind        PlanStepKind
+	Description string
+	// Diff contains a unified diff for file steps
+	Diff string
+}
+
+var (
+	dryRun bool
+	plan   []PlanStep
+	// virtualFiles tracks the planned content of each file modified during a dry run so
+	// consecutive changes to the same file are diffed against each other
+	virtualFiles = map[string]string{}
+	restartRegex = regexp.MustCompile(`^(systemctl (re)?start \S+|rstudio-server (re)?start|rstudio-launcher (re)?start|service \S+ (re)?start)`)
+)
+
+// SetDryRun enables or disables dry-run mode. In dry-run mode mutating actions are
+// recorded in a plan instead of being executed.
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+	plan = []PlanStep{}
+	virtualFiles = map[string]string{}
+}
+
+// DryRun returns true when mutating actions should be recorded instead of executed
+func DryRun() bool {
+	return dryRun
+}
+
+// Plan returns the ordered list of recorded dry-run steps
+func Plan() []PlanStep {
+	return plan
+}
+
+func recordStep(step PlanStep) {
+	plan = append(plan, step)
+	log.Info(fmt.Sprintf("dry run: recorded %s step: %s", step.Kind, step.Description))
+}
+
+// RecordCommand records a command that would have been run, service restarts are recorded separately
+func RecordCommand(command string) {
+	trimmed := strings.TrimSpace(command)
+	if restartRegex.MatchString(trimmed) {
+		recordStep(PlanStep{Kind: PlanService, Description: trimmed})
+		return
+	}
+	recordStep(PlanStep{Kind: PlanCommand, Description: trimmed})
+}
+
+// RecordDownload records a URL that would have been downloaded
+func RecordDownload(url string, destination string) {
+	recordStep(PlanStep{Kind: PlanDownload, Description: url + " -> " + destination})
+}
+
+// ReadPlannedFile returns the content of a file including any changes already
+// recorded during the dry run
+func ReadPlannedFile(filepath string) (string, bool, error) {
+	if content, ok := virtualFiles[filepath]; ok {
+		return content, true, nil
+	}
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(content), true, nil
+}
+
+// RecordFileChange records the new content of a file and the diff against its current content
+func RecordFileChange(filepath string, newContent string) error {
+	oldContent, exists, err := ReadPlannedFile(filepath)
+	if err != nil {
+		return err
+	}
+	if exists && oldContent == newContent {
+		return nil
+	}
+
+	fromFile := filepath
+	if !exists {
+		fromFile = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(oldContent),
+		B:        splitLines(newContent),
+		FromFile: fromFile,
+		ToFile:   filepath,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create diff for %s: %w", filepath, err)
+	}
+
+	description := "modify " + filepath
+	if !exists {
+		description = "create " + filepath
+	}
+	virtualFiles[filepath] = newContent
+	recordStep(PlanStep{Kind: PlanFile, Description: description, Diff: diff})
+	return nil
+}
+
+// splitLines splits content into lines that keep their line endings, as expected by difflib
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// PrintPlan writes the recorded dry-run plan in the order the steps would have been run
+func PrintPlan(w io.Writer) {
+	fmt.Fprintln(w, "\n=== Dry run plan ===")
+	if len(plan) == 0 {
+		fmt.Fprintln(w, "No changes would be made.")
+		return
+	}
+	for i, step := range plan {
+		fmt.Fprintf(w, "%d. [%s] %s\n", i+1, step.Kind, step.Description)
+		if step.Diff != "" {
+			for _, line := range strings.Split(strings.TrimRight(step.Diff, "\n"), "\n") {
+				fmt.Fprintln(w, "     "+line)
+			}
+		}
+	}
+	fmt.Fprintln(w, "\nNo changes were made because wbi was run with --dry-run.")
+}
@@ -0,0 +1,120 @@
+package system
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useDryRun enables dry-run mode for a test
+func useDryRun(t *testing.T) {
+	SetDryRun(true)
+	t.Cleanup(func() { SetDryRun(false) })
+}
+
+func TestRecordCommand(t *testing.T) {
+	useDryRun(t)
+	RecordCommand("  apt-get install -y gdebi-core ")
+	RecordCommand("systemctl restart rstudio-server")
+	RecordCommand("rstudio-launcher restart")
+	RecordDownload("https://cdn.rstudio.com/r/versions.json", "/tmp/versions.json")
+
+	assert.Equal(t, []PlanStep{
+		{Kind: PlanCommand, Description: "apt-get install -y gdebi-core"},
+		{Kind: PlanService, Description: "systemctl restart rstudio-server"},
+		{Kind: PlanService, Description: "rstudio-launcher restart"},
+		{Kind: PlanDownload, Description: "https://cdn.rstudio.com/r/versions.json -> /tmp/versions.json"},
+	}, Plan())
+}
+
+func TestRecordFileChange(t *testing.T) {
+	useDryRun(t)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "rserver.conf")
+	require.NoError(t, os.WriteFile(existing, []byte("www-port=8787\n"), 0644))
+	created := filepath.Join(dir, "r-versions")
+
+	// planned changes are read back before the file on disk, which is left unchanged
+	require.NoError(t, RecordFileChange(existing, "www-port=8787\nauth-pam-sessions-profile=1\n"))
+	content, exists, err := ReadPlannedFile(existing)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "www-port=8787\nauth-pam-sessions-profile=1\n", content)
+	onDisk, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "www-port=8787\n", string(onDisk))
+
+	// a second change is diffed against the first
+	require.NoError(t, RecordFileChange(existing, "www-port=443\nauth-pam-sessions-profile=1\n"))
+	// unchanged content records nothing
+	require.NoError(t, RecordFileChange(existing, "www-port=443\nauth-pam-sessions-profile=1\n"))
+
+	_, exists, err = ReadPlannedFile(created)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	require.NoError(t, RecordFileChange(created, "Path: /opt/R/4.3.2\n"))
+	assert.NoFileExists(t, created)
+
+	steps := Plan()
+	require.Len(t, steps, 3)
+	assert.Equal(t, PlanStep{
+		Kind:        PlanFile,
+		Description: "modify " + existing,
+		Diff:        "--- " + existing + "\n+++ " + existing + "\n@@ -1 +1,2 @@\n www-port=8787\n+auth-pam-sessions-profile=1\n",
+	}, steps[0])
+	assert.Equal(t, PlanStep{
+		Kind:        PlanFile,
+		Description: "modify " + existing,
+		Diff:        "--- " + existing + "\n+++ " + existing + "\n@@ -1,2 +1,2 @@\n-www-port=8787\n+www-port=443\n auth-pam-sessions-profile=1\n",
+	}, steps[1])
+	assert.Equal(t, PlanStep{
+		Kind:        PlanFile,
+		Description: "create " + created,
+		Diff:        "--- /dev/null\n+++ " + created + "\n@@ -0,0 +1 @@\n+Path: /opt/R/4.3.2\n",
+	}, steps[2])
+
+	var out bytes.Buffer
+	PrintPlan(&out)
+	assert.Contains(t, out.String(), "1. [file] modify "+existing+"\n     --- "+existing+"\n")
+	assert.Contains(t, out.String(), "3. [file] create "+created+"\n     --- /dev/null\n     +++ "+created+"\n     @@ -0,0 +1 @@\n     +Path: /opt/R/4.3.2\n")
+	assert.Contains(t, out.String(), "No changes were made because wbi was run with --dry-run.")
+
+	// turning dry-run mode off forgets the planned files
+	SetDryRun(false)
+	content, _, err = ReadPlannedFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "www-port=8787\n", content)
+}
+
+func TestPrintEmptyPlan(t *testing.T) {
+	useDryRun(t)
+	var out bytes.Buffer
+	PrintPlan(&out)
+	assert.Equal(t, "\n=== Dry run plan ===\nNo changes would be made.\n", out.String())
+}
+
+func TestRunCommandInDryRun(t *testing.T) {
+	useDryRun(t)
+	marker := filepath.Join(t.TempDir(), "marker")
+
+	// commands that change the server are recorded instead of run
+	require.NoError(t, RunCommand("touch "+marker, false, 0, true))
+	output, err := RunCommandAndCaptureOutput("touch "+marker, false, 0, true)
+	require.NoError(t, err)
+	assert.Empty(t, output)
+	assert.NoFileExists(t, marker)
+
+	// read-only checks still run so the plan reflects the server
+	output, err = RunCommandAndCaptureOutput("echo checked", false, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, "checked\n", output)
+
+	assert.Equal(t, []PlanStep{
+		{Kind: PlanCommand, Description: "touch " + marker},
+		{Kind: PlanCommand, Description: "touch " + marker},
+	}, Plan())
+}
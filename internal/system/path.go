@@ -1,6 +1,10 @@
 package system
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
 
 // AddToPATH adds a path to the PATH environment variable in a profile.d script
 func AddToPATH(path string, filename string) error {
@@ -11,3 +15,41 @@ func AddToPATH(path string, filename string) error {
 	}
 	return nil
 }
+
+// VersionDir returns the install directory of a version under root, such as /opt/R/4.2.3. The version must name a
+// direct child of root, and the directory must contain the binary so nothing else is ever removed in its place.
+func VersionDir(root string, version string, binary string) (string, error) {
+	if version == "" || strings.Contains(version, "/") || strings.Contains(version, "..") {
+		return "", fmt.Errorf("invalid version %q, versions can't be empty or contain / or ..", version)
+	}
+	root = filepath.Clean(root)
+	dir := filepath.Clean(filepath.Join(root, version))
+	if filepath.Dir(dir) != root {
+		return "", fmt.Errorf("invalid version %q, it is not a directory in %s", version, root)
+	}
+	if !VerifyFileExists(filepath.Join(dir, binary)) {
+		return "", fmt.Errorf("version %s is not installed in %s", version, root)
+	}
+	return dir, nil
+}
+
+// SamePath returns true when two paths are equal or resolve to the same file, such as Quarto on the PATH through the
+// /usr/local/bin/quarto symlink
+func SamePath(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	resolvedA, errA := filepath.EvalSymlinks(a)
+	resolvedB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && resolvedA == resolvedB
+}
+
+// ContainsPath returns true when any of the paths is the same file as path
+func ContainsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if SamePath(p, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "quarto")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "1.3.340", "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "1.3.340", "bin", "quarto"), []byte("#!/bin/sh\n"), 0755))
+	// a directory without the binary, such as a partial install or something else in /opt
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "1.2.475"), 0755))
+
+	tests := map[string]struct {
+		version     string
+		expected    string
+		expectError string
+	}{
+		"installed version":         {version: "1.3.340", expected: filepath.Join(root, "1.3.340")},
+		"missing binary":            {version: "1.2.475", expectError: "version 1.2.475 is not installed in " + root},
+		"not installed":             {version: "1.4.0", expectError: "version 1.4.0 is not installed"},
+		"empty":                     {version: "", expectError: "versions can't be empty or contain / or .."},
+		"root":                      {version: ".", expectError: "it is not a directory in " + root},
+		"parent":                    {version: "..", expectError: "versions can't be empty or contain / or .."},
+		"nested path":               {version: "1.3.340/bin", expectError: "versions can't be empty or contain / or .."},
+		"escape through the parent": {version: "../../etc", expectError: "versions can't be empty or contain / or .."},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := VersionDir(root, tc.version, filepath.Join("bin", "quarto"))
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, dir)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestRemoveDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "1.3.340")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+
+	SetDryRun(true)
+	t.Cleanup(func() { SetDryRun(false) })
+	require.NoError(t, RemoveDirectory(dir))
+	assert.DirExists(t, dir)
+	assert.Equal(t, []PlanStep{{Kind: PlanCommand, Description: "rm -rf " + dir}}, Plan())
+
+	SetDryRun(false)
+	require.NoError(t, RemoveDirectory(dir))
+	assert.NoDirExists(t, dir)
+}
+
+func TestSamePath(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "quarto")
+	require.NoError(t, os.WriteFile(target, []byte{}, 0755))
+	link := filepath.Join(dir, "quarto-link")
+	require.NoError(t, os.Symlink(target, link))
+	other := filepath.Join(dir, "other")
+	require.NoError(t, os.WriteFile(other, []byte{}, 0755))
+
+	assert.True(t, SamePath(target, target))
+	assert.True(t, SamePath(link, target))
+	assert.False(t, SamePath(other, target))
+	assert.False(t, SamePath(filepath.Join(dir, "missing"), target))
+
+	assert.True(t, ContainsPath([]string{other, target}, link))
+	assert.False(t, ContainsPath([]string{other}, link))
+}
@@ -2,11 +2,26 @@ package system
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// messages is where progress messages are printed, stderr when stdout is reserved for structured output
+var messages io.Writer = os.Stdout
+
+// SetMessageOutput sets where PrintAndLogInfo prints messages, the output of commands and the dry-run plan
+func SetMessageOutput(w io.Writer) {
+	messages = w
+}
+
+// MessageOutput returns where messages are printed
+func MessageOutput() io.Writer {
+	return messages
+}
+
 func PrintAndLogInfo(message string) {
-	fmt.Println(message)
+	fmt.Fprintln(messages, message)
 	log.Info(message)
 }
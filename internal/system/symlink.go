@@ -0,0 +1,39 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RepairSymlink removes a symlink that points into a removed directory or at a file that no longer
+// exists, and points it at the replacement instead when one is provided
+func RepairSymlink(link string, removedDir string, replacement string) error {
+	target, err := os.Readlink(link)
+	if err != nil {
+		// the link doesn't exist or isn't a symlink so there is nothing to repair
+		return nil
+	}
+
+	pointsAtRemoved := strings.HasPrefix(target, strings.TrimSuffix(removedDir, "/")+"/")
+	if !pointsAtRemoved && VerifyFileExists(target) {
+		return nil
+	}
+
+	PrintAndLogInfo("\nThe symlink " + link + " points to " + target + " which has been removed")
+	removeCommand := "rm -f " + link
+	err = RunCommand(removeCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("error removing symlink with the command '%s': %w", removeCommand, err)
+	}
+
+	if replacement != "" {
+		linkCommand := "ln -s " + replacement + " " + link
+		err = RunCommand(linkCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("error setting symlink with the command '%s': %w", linkCommand, err)
+		}
+		PrintAndLogInfo(link + " now points to " + replacement)
+	}
+	return nil
+}
@@ -2,15 +2,26 @@ package system
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"strings"
+	"time"
 
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
 )
 
 // WriteStrings appends a slice of strings to a file and creates the file if it doesn't exist
 func WriteStrings(lines []string, filepath string, perm fs.FileMode, print bool, save bool) error {
+	if DryRun() {
+		content, _, err := ReadPlannedFile(filepath)
+		if err != nil {
+			return err
+		}
+		return RecordFileChange(filepath, content+strings.Join(lines, "\n")+"\n")
+	}
+
 	if print {
 		PrintAndLogInfo("\n=== Writing to the file " + filepath + " ===")
 	}
@@ -36,3 +47,106 @@ func WriteStrings(lines []string, filepath string, perm fs.FileMode, print bool,
 
 	return nil
 }
+
+// WriteFileWithBackup writes a file if its content differs from the original, an existing file is first backed up
+// alongside the original with a timestamp suffix. In dry-run mode the backup and a diff are recorded instead.
+func WriteFileWithBackup(path string, content string, original string, exists bool, perm fs.FileMode) error {
+	return writeFileWithBackup(path, content, original, exists, perm, nil)
+}
+
+// WriteSecretFileWithBackup writes a file holding passwords or tokens like WriteFileWithBackup, but the redacted
+// content is logged and recorded in place of a diff that would show the secrets. The permissions are also set on an
+// existing file.
+func WriteSecretFileWithBackup(path string, content string, redacted string, original string, exists bool, perm fs.FileMode) error {
+	return writeFileWithBackup(path, content, original, exists, perm, &redacted)
+}
+
+func writeFileWithBackup(path string, content string, original string, exists bool, perm fs.FileMode, redacted *string) error {
+	if exists && content == original {
+		PrintAndLogInfo("\nNo changes needed to " + path)
+		return nil
+	}
+	backupPath := BackupPath(path)
+	logged := "cat > " + path + " <<'EOF'\n" + content + "EOF"
+	if redacted != nil {
+		logged = "cat > " + path + " <<'EOF'\n" + *redacted + "EOF\nchmod " + fmt.Sprintf("%o", perm) + " " + path
+	}
+
+	if DryRun() {
+		if exists {
+			RecordCommand("cp -p " + path + " " + backupPath)
+		}
+		if redacted != nil {
+			RecordCommand(logged)
+			return nil
+		}
+		return RecordFileChange(path, content)
+	}
+
+	PrintAndLogInfo("\n=== Writing to the file " + path + " ===")
+	if exists {
+		err := os.WriteFile(backupPath, []byte(original), perm)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		PrintAndLogInfo("Backed up " + path + " to " + backupPath)
+		cmdlog.Info("cp -p " + path + " " + backupPath)
+	}
+
+	if redacted != nil {
+		// WriteFile keeps the permissions of an existing file, so they're restricted before the secrets are written
+		err := os.Chmod(path, perm)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to set the permissions of %s: %w", path, err)
+		}
+	}
+	err := os.WriteFile(path, []byte(content), perm)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if redacted != nil {
+		// a new file is created with the umask applied, so the permissions are set exactly
+		err = os.Chmod(path, perm)
+		if err != nil {
+			return fmt.Errorf("failed to set the permissions of %s: %w", path, err)
+		}
+	}
+	cmdlog.Info(logged)
+	return nil
+}
+
+// BackupPath returns the path a file is backed up to, the timestamp has sub-second precision so saving the same file
+// twice in one run doesn't overwrite the backup of the original
+func BackupPath(path string) string {
+	return path + ".bak-" + time.Now().Format("20060102T150405.000000000")
+}
+
+// CreateDirectory creates a directory and any missing parents if it doesn't exist
+func CreateDirectory(path string, perm fs.FileMode) error {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return nil
+	}
+	if DryRun() {
+		RecordCommand("mkdir -p " + path)
+		return nil
+	}
+	err := os.MkdirAll(path, perm)
+	if err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	return nil
+}
+
+// ReadPasswordFile reads a password from the first line of a file
+func ReadPasswordFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the password file %s: %w", path, err)
+	}
+	password, _, _ := strings.Cut(string(content), "\n")
+	password = strings.TrimSuffix(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("the password file %s is empty", path)
+	}
+	return password, nil
+}
@@ -0,0 +1,90 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPasswordFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("p@ss\n"), 0600))
+	password, err := ReadPasswordFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "p@ss", password)
+
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0600))
+	_, err = ReadPasswordFile(path)
+	assert.ErrorContains(t, err, "is empty")
+}
+
+func TestWriteFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "r-versions")
+
+	require.NoError(t, WriteFileWithBackup(path, "Path: /opt/R/4.3.2\n", "", false, 0644))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Path: /opt/R/4.3.2\n", string(content))
+	backups, err := filepath.Glob(path + ".bak-*")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	// unchanged content leaves the file alone
+	require.NoError(t, WriteFileWithBackup(path, "Path: /opt/R/4.3.2\n", "Path: /opt/R/4.3.2\n", true, 0644))
+	backups, err = filepath.Glob(path + ".bak-*")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	require.NoError(t, WriteFileWithBackup(path, "Path: /opt/R/4.4.0\n", "Path: /opt/R/4.3.2\n", true, 0644))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Path: /opt/R/4.4.0\n", string(content))
+	backups, err = filepath.Glob(path + ".bak-*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Path: /opt/R/4.3.2\n", string(backup))
+
+	// a second save in the same second keeps the first backup
+	require.NoError(t, WriteFileWithBackup(path, "Path: /opt/R/4.4.1\n", "Path: /opt/R/4.4.0\n", true, 0644))
+	backups, err = filepath.Glob(path + ".bak-*")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	backup, err = os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Path: /opt/R/4.3.2\n", string(backup))
+}
+
+func TestWriteSecretFileWithBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launcher.kubernetes.conf")
+	require.NoError(t, os.WriteFile(path, []byte("auth-token=old\n"), 0644))
+
+	useDryRun(t)
+	require.NoError(t, WriteSecretFileWithBackup(path, "auth-token=new\n", "auth-token=********\n", "auth-token=old\n", true, 0600))
+	steps := Plan()
+	require.Len(t, steps, 2)
+	assert.Contains(t, steps[0].Description, "cp -p "+path+" "+path+".bak-")
+	// the plan shows the redacted content instead of a diff
+	assert.Equal(t, PlanStep{Kind: PlanCommand, Description: "cat > " + path + " <<'EOF'\nauth-token=********\nEOF\nchmod 600 " + path}, steps[1])
+
+	SetDryRun(false)
+	require.NoError(t, WriteSecretFileWithBackup(path, "auth-token=new\n", "auth-token=********\n", "auth-token=old\n", true, 0600))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "auth-token=new\n", string(content))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// a new secret file is created with the permissions
+	newPath := filepath.Join(t.TempDir(), "database.conf")
+	require.NoError(t, WriteSecretFileWithBackup(newPath, "password=p@ss\n", "password=********\n", "", false, 0600))
+	info, err = os.Stat(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
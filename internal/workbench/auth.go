@@ -0,0 +1,289 @@
+package workbench
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/httpclient"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// Authentication types configured by wbi config auth
+const (
+	AuthSAML   = "saml"
+	AuthOpenID = "openid"
+	AuthPAM    = "pam"
+	AuthProxy  = "proxy"
+)
+
+// AuthTypes lists the valid authentication types
+var AuthTypes = []string{AuthSAML, AuthOpenID, AuthPAM, AuthProxy}
+
+var (
+	rserverConfPath = "/etc/rstudio/rserver.conf"
+	// openIDClientSecretPath holds the OpenID Connect client ID and secret, readable only by root
+	openIDClientSecretPath = "/etc/rstudio/openid-client-secret"
+)
+
+// authEnableKeys turn on each single sign-on type in rserver.conf, PAM is used when none of them are set
+var authEnableKeys = map[string]string{
+	AuthSAML:   "auth-saml",
+	AuthOpenID: "auth-openid",
+	AuthProxy:  "auth-proxy",
+}
+
+// AuthOptions describes how users sign in to Workbench
+type AuthOptions struct {
+	Type string
+	// MetadataURL is the URL of the SAML identity provider's metadata
+	MetadataURL string
+	// Issuer is the OpenID Connect issuer URL, its discovery document is read from /.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// UsernameClaim is the OpenID Connect claim or SAML attribute used as the username, Workbench's default is used when empty
+	UsernameClaim string
+	// SignInURL is the page of the authenticating proxy users are sent to when they aren't signed in
+	SignInURL string
+	// UserHeader is the header the proxy sets to the username, Workbench's default is used when empty
+	UserHeader string
+}
+
+// samlEntityDescriptor is the part of SAML metadata that describes an identity provider
+type samlEntityDescriptor struct {
+	XMLName           xml.Name
+	EntityID          string `xml:"entityID,attr"`
+	IDPSSODescriptors []struct {
+		SingleSignOnServices []struct {
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+		KeyDescriptors []struct {
+			Use         string `xml:"use,attr"`
+			Certificate string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+// openIDConfiguration is the part of an OpenID Connect discovery document Workbench relies on
+type openIDConfiguration struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// WriteAuthConfig validates the identity provider, then sets the auth-* keys in rserver.conf for the type of
+// authentication, turning off any other single sign-on type, and prints what to register with the identity provider
+func WriteAuthConfig(opts AuthOptions) error {
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	for authType, key := range authEnableKeys {
+		if authType != opts.Type {
+			rserverConf.Delete(key)
+		}
+	}
+
+	switch opts.Type {
+	case AuthSAML:
+		entityID, err := ValidateSAMLMetadata(opts.MetadataURL)
+		if err != nil {
+			return err
+		}
+		system.PrintAndLogInfo("The SAML metadata for the identity provider " + entityID + " is valid")
+		rserverConf.Set("auth-saml", "1")
+		rserverConf.Set("auth-saml-metadata-url", opts.MetadataURL)
+		if opts.UsernameClaim != "" {
+			rserverConf.Set("auth-saml-sp-attribute-username", opts.UsernameClaim)
+		}
+	case AuthOpenID:
+		err := ValidateOpenIDIssuer(opts.Issuer)
+		if err != nil {
+			return err
+		}
+		system.PrintAndLogInfo("The OpenID Connect discovery document for " + opts.Issuer + " is valid")
+		rserverConf.Set("auth-openid", "1")
+		rserverConf.Set("auth-openid-issuer", opts.Issuer)
+		if opts.UsernameClaim != "" {
+			rserverConf.Set("auth-openid-username-claim", opts.UsernameClaim)
+		}
+		err = writeOpenIDClientSecret(opts.ClientID, opts.ClientSecret)
+		if err != nil {
+			return err
+		}
+	case AuthProxy:
+		rserverConf.Set("auth-proxy", "1")
+		rserverConf.Set("auth-proxy-sign-in-url", opts.SignInURL)
+		if opts.UserHeader != "" {
+			rserverConf.Set("auth-proxy-user-header", opts.UserHeader)
+		}
+	case AuthPAM:
+		// PAM is used when no single sign-on type is turned on
+	default:
+		return fmt.Errorf("invalid authentication type %s, valid options are: %s", opts.Type, strings.Join(AuthTypes, ", "))
+	}
+
+	err = rserverConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	serverURL, _ := rserverConf.Get("launcher-sessions-callback-address")
+	system.PrintAndLogInfo(authNextSteps(opts.Type, serverURL))
+	return nil
+}
+
+// authNextSteps describes what to register with the identity provider, using the server URL set by the SSL step
+func authNextSteps(authType string, serverURL string) string {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	missingURL := serverURL == ""
+	if missingURL {
+		serverURL = "https://YOUR_SERVER_URL.com"
+	}
+
+	var message string
+	switch authType {
+	case AuthSAML:
+		message = "\nRegister Workbench with the SAML identity provider using:\n" +
+			"  Assertion Consumer Service (ACS) URL: " + serverURL + "/saml/acs\n" +
+			"  Service provider metadata: " + serverURL + "/saml/metadata"
+	case AuthOpenID:
+		message = "\nRegister this redirect URL with the OpenID Connect client:\n" +
+			"  " + serverURL + "/openid/callback"
+	case AuthProxy:
+		message = "\nThe proxy must authenticate every request and set the username header before forwarding it to Workbench."
+	default:
+		message = "\nWorkbench now authenticates users with PAM."
+		missingURL = false
+	}
+	if missingURL {
+		message += "\nThe server URL is not set, replace YOUR_SERVER_URL.com above with the URL of this server or set it with wbi config ssl."
+	}
+	return message + "\nRestart Workbench with 'rstudio-server restart' to apply the change."
+}
+
+// ValidateSAMLMetadata fetches the metadata of a SAML identity provider and checks it has a single sign-on service
+// and a valid signing certificate, returning the identity provider's entity ID
+func ValidateSAMLMetadata(metadataURL string) (string, error) {
+	body, err := fetchAuthDocument(metadataURL)
+	if err != nil {
+		return "", err
+	}
+	var descriptor samlEntityDescriptor
+	err = xml.Unmarshal(body, &descriptor)
+	if err != nil {
+		return "", fmt.Errorf("the SAML metadata at %s is not valid XML: %w", metadataURL, err)
+	}
+	if descriptor.XMLName.Local != "EntityDescriptor" {
+		return "", fmt.Errorf("the SAML metadata at %s must describe a single identity provider in an EntityDescriptor, found %s", metadataURL, descriptor.XMLName.Local)
+	}
+	if descriptor.EntityID == "" {
+		return "", fmt.Errorf("the SAML metadata at %s has no entityID", metadataURL)
+	}
+	if len(descriptor.IDPSSODescriptors) == 0 {
+		return "", fmt.Errorf("the SAML metadata at %s does not describe an identity provider (IDPSSODescriptor)", metadataURL)
+	}
+
+	idp := descriptor.IDPSSODescriptors[0]
+	if len(idp.SingleSignOnServices) == 0 || idp.SingleSignOnServices[0].Location == "" {
+		return "", fmt.Errorf("the SAML metadata at %s has no SingleSignOnService", metadataURL)
+	}
+	signing := false
+	for _, key := range idp.KeyDescriptors {
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key.Certificate), ""))
+		if err != nil {
+			return "", fmt.Errorf("the SAML metadata at %s contains an invalid certificate: %w", metadataURL, err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return "", fmt.Errorf("the SAML metadata at %s contains an invalid certificate: %w", metadataURL, err)
+		}
+		if time.Now().After(cert.NotAfter) {
+			return "", fmt.Errorf("the SAML signing certificate at %s expired on %s", metadataURL, cert.NotAfter.Format("2006-01-02"))
+		}
+		signing = true
+	}
+	if !signing {
+		return "", fmt.Errorf("the SAML metadata at %s has no signing certificate", metadataURL)
+	}
+	return descriptor.EntityID, nil
+}
+
+// ValidateOpenIDIssuer fetches the discovery document of an OpenID Connect issuer and checks it describes that issuer
+func ValidateOpenIDIssuer(issuer string) error {
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	body, err := fetchAuthDocument(discoveryURL)
+	if err != nil {
+		return err
+	}
+	var configuration openIDConfiguration
+	err = json.Unmarshal(body, &configuration)
+	if err != nil {
+		return fmt.Errorf("the OpenID Connect discovery document at %s is not valid JSON: %w", discoveryURL, err)
+	}
+	if strings.TrimSuffix(configuration.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return fmt.Errorf("the OpenID Connect discovery document at %s is for the issuer %q, not %q", discoveryURL, configuration.Issuer, issuer)
+	}
+	if configuration.AuthorizationEndpoint == "" || configuration.TokenEndpoint == "" || configuration.JWKSURI == "" {
+		return fmt.Errorf("the OpenID Connect discovery document at %s is missing the authorization_endpoint, token_endpoint or jwks_uri", discoveryURL)
+	}
+	return nil
+}
+
+func fetchAuthDocument(documentURL string) ([]byte, error) {
+	client := httpclient.New()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, documentURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", documentURL, err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", documentURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", documentURL, res.Status)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", documentURL, err)
+	}
+	return body, nil
+}
+
+// writeOpenIDClientSecret writes the client ID and secret with 0600 permissions, backing up an existing file. The
+// secret is left out of the command log and the dry run plan.
+func writeOpenIDClientSecret(clientID string, clientSecret string) error {
+	if clientID == "" || clientSecret == "" {
+		return errors.New("the client ID and client secret are required for OpenID Connect")
+	}
+	original, exists, err := system.ReadPlannedFile(openIDClientSecretPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", openIDClientSecretPath, err)
+	}
+
+	content := "client-id=" + clientID + "\nclient-secret=" + clientSecret + "\n"
+	redacted := "client-id=" + clientID + "\nclient-secret=********\n"
+	err = system.WriteSecretFileWithBackup(openIDClientSecretPath, content, redacted, original, exists, 0600)
+	if err != nil {
+		return err
+	}
+	if !system.DryRun() {
+		system.PrintAndLogInfo("The OpenID Connect client credentials have been written to " + openIDClientSecretPath)
+	}
+	return nil
+}
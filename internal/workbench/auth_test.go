@@ -0,0 +1,166 @@
+package workbench
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIdentityProvider serves SAML metadata and an OpenID Connect discovery document, standing in for an identity provider
+func newIdentityProvider(t *testing.T, certificate string) *httptest.Server {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/saml/metadata", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="https://idp.example.com/saml">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo><ds:X509Data><ds:X509Certificate>
+        ` + certificate + `
+      </ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/saml/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`))
+	})
+	mux.HandleFunc("/saml/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<EntityDescriptor entityID="https://idp.example.com/saml"></EntityDescriptor>`))
+	})
+	mux.HandleFunc("/oidc/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"issuer":"` + server.URL + `/oidc","authorization_endpoint":"` + server.URL + `/oidc/authorize",` +
+			`"token_endpoint":"` + server.URL + `/oidc/token","jwks_uri":"` + server.URL + `/oidc/keys"}`))
+	})
+	mux.HandleFunc("/other/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"issuer":"https://elsewhere.example.com","authorization_endpoint":"a","token_endpoint":"b","jwks_uri":"c"}`))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newSigningCertificate(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func setAuthPaths(t *testing.T, rserverConf string) {
+	dir := t.TempDir()
+	originalConf, originalSecret := rserverConfPath, openIDClientSecretPath
+	rserverConfPath = filepath.Join(dir, "rserver.conf")
+	openIDClientSecretPath = filepath.Join(dir, "openid-client-secret")
+	t.Cleanup(func() {
+		rserverConfPath, openIDClientSecretPath = originalConf, originalSecret
+	})
+	require.NoError(t, os.WriteFile(rserverConfPath, []byte(rserverConf), 0644))
+}
+
+func TestValidateSAMLMetadata(t *testing.T) {
+	server := newIdentityProvider(t, newSigningCertificate(t))
+
+	entityID, err := ValidateSAMLMetadata(server.URL + "/saml/metadata")
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com/saml", entityID)
+
+	_, err = ValidateSAMLMetadata(server.URL + "/saml/empty")
+	assert.ErrorContains(t, err, "does not describe an identity provider")
+
+	_, err = ValidateSAMLMetadata(server.URL + "/missing")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestValidateOpenIDIssuer(t *testing.T) {
+	server := newIdentityProvider(t, "")
+
+	assert.NoError(t, ValidateOpenIDIssuer(server.URL+"/oidc"))
+	assert.NoError(t, ValidateOpenIDIssuer(server.URL+"/oidc/"))
+	assert.ErrorContains(t, ValidateOpenIDIssuer(server.URL+"/other"), "is for the issuer")
+}
+
+func TestWriteAuthConfig(t *testing.T) {
+	server := newIdentityProvider(t, newSigningCertificate(t))
+
+	t.Run("openid", func(t *testing.T) {
+		setAuthPaths(t, "launcher-sessions-callback-address=https://workbench.example.com\nauth-saml=1\n")
+		err := WriteAuthConfig(AuthOptions{Type: AuthOpenID, Issuer: server.URL + "/oidc", ClientID: "workbench", ClientSecret: "s3cret", UsernameClaim: "email"})
+		require.NoError(t, err)
+
+		conf, err := os.ReadFile(rserverConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "launcher-sessions-callback-address=https://workbench.example.com\nauth-openid=1\nauth-openid-issuer="+server.URL+"/oidc\nauth-openid-username-claim=email\n", string(conf))
+
+		secret, err := os.ReadFile(openIDClientSecretPath)
+		require.NoError(t, err)
+		assert.Equal(t, "client-id=workbench\nclient-secret=s3cret\n", string(secret))
+		info, err := os.Stat(openIDClientSecretPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("openid with an existing client secret", func(t *testing.T) {
+		setAuthPaths(t, "auth-openid=1\n")
+		require.NoError(t, os.WriteFile(openIDClientSecretPath, []byte("client-id=old\nclient-secret=old\n"), 0644))
+		err := WriteAuthConfig(AuthOptions{Type: AuthOpenID, Issuer: server.URL + "/oidc", ClientID: "workbench", ClientSecret: "s3cret"})
+		require.NoError(t, err)
+
+		secret, err := os.ReadFile(openIDClientSecretPath)
+		require.NoError(t, err)
+		assert.Equal(t, "client-id=workbench\nclient-secret=s3cret\n", string(secret))
+		info, err := os.Stat(openIDClientSecretPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		backups, err := filepath.Glob(openIDClientSecretPath + ".bak-*")
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		backup, err := os.ReadFile(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, "client-id=old\nclient-secret=old\n", string(backup))
+	})
+
+	t.Run("saml", func(t *testing.T) {
+		setAuthPaths(t, "auth-openid=1\n")
+		err := WriteAuthConfig(AuthOptions{Type: AuthSAML, MetadataURL: server.URL + "/saml/metadata"})
+		require.NoError(t, err)
+
+		conf, err := os.ReadFile(rserverConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "auth-saml=1\nauth-saml-metadata-url="+server.URL+"/saml/metadata\n", string(conf))
+	})
+
+	t.Run("invalid metadata leaves the config unchanged", func(t *testing.T) {
+		setAuthPaths(t, "auth-openid=1\n")
+		err := WriteAuthConfig(AuthOptions{Type: AuthSAML, MetadataURL: server.URL + "/saml/empty"})
+		assert.Error(t, err)
+
+		conf, err := os.ReadFile(rserverConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "auth-openid=1\n", string(conf))
+	})
+}
+
+func TestAuthNextSteps(t *testing.T) {
+	assert.Contains(t, authNextSteps(AuthSAML, "https://workbench.example.com/"), "https://workbench.example.com/saml/acs")
+	assert.Contains(t, authNextSteps(AuthOpenID, "https://workbench.example.com"), "https://workbench.example.com/openid/callback")
+	assert.Contains(t, authNextSteps(AuthOpenID, ""), "The server URL is not set")
+}
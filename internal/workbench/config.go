@@ -3,51 +3,32 @@ package workbench
 import (
 	"fmt"
 
-	"github.com/sol-eng/wbi/internal/system"
+	"github.com/sol-eng/wbi/internal/conffile"
 )
 
-// WriteRepoConfig writes the repo config to the Workbench config file
+// WriteRepoConfig sets the default repo in repos.conf (cran) or pip.conf (pypi), replacing any existing value
 func WriteRepoConfig(url string, source string) error {
 	if source == "cran" {
-		filepath := "/etc/rstudio/repos.conf"
-		// check to ensure the line doesn't already exist
-		lineExists, err := system.CheckStringExists("CRAN=", filepath)
+		reposConf, err := conffile.Load("/etc/rstudio/repos.conf")
 		if err != nil {
-			return fmt.Errorf("failed to check if line exists: %w", err)
+			return fmt.Errorf("failed to load config: %w", err)
 		}
+		reposConf.Set("CRAN", url)
 
-		if !lineExists {
-			writeLines := []string{
-				"CRAN=" + url,
-			}
-
-			err := system.WriteStrings(writeLines, filepath, 0644, true, true)
-			if err != nil {
-				return fmt.Errorf("failed to write config: %w", err)
-			}
-		} else {
-			return fmt.Errorf("line already exists in repos.conf")
+		err = reposConf.Save(0644)
+		if err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
 		}
 	} else if source == "pypi" {
-		filepath := "/etc/pip.conf"
-		// check to ensure the line doesn't already exist
-		lineExists, err := system.CheckStringExists("index-url="+url, filepath)
+		pipConf, err := conffile.Load("/etc/pip.conf")
 		if err != nil {
-			return fmt.Errorf("failed to check if line exists: %w", err)
+			return fmt.Errorf("failed to load config: %w", err)
 		}
+		pipConf.Section("global").Set("index-url", url)
 
-		if !lineExists {
-			writeLines := []string{
-				"[global]",
-				"index-url=" + url,
-			}
-
-			err = system.WriteStrings(writeLines, filepath, 0644, true, true)
-			if err != nil {
-				return fmt.Errorf("failed to write config: %w", err)
-			}
-		} else {
-			return fmt.Errorf("line already exists in pip.conf")
+		err = pipConf.Save(0644)
+		if err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
 		}
 	}
 	return nil
@@ -67,98 +48,64 @@ func cleanServerURL(serverURL string) string {
 	return serverURL
 }
 
-// WriteSSLConfig writes the SSL config to the Workbench config file
+// WriteSSLConfig sets the SSL config in rserver.conf, replacing any existing certificate and key paths
 func WriteSSLConfig(certPath string, keyPath string, serverURL string) error {
 	// clean the serverURL
 	serverURLClean := cleanServerURL(serverURL)
 	finalServerURL := "https://" + serverURLClean
 
-	// check to ensure the lines don't already exist
-	linesCheck := []string{
-		"ssl-enabled=",
-		"ssl-certificate=",
-		"ssl-certificate-key=",
-	}
-	filepath := "/etc/rstudio/rserver.conf"
-
-	var linesExist bool
-	for _, value := range linesCheck {
-		matched, err := system.CheckStringExists(value, filepath)
-		if err == nil && matched {
-			linesExist = true
-		}
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
 	}
+	rserverConf.Set("launcher-sessions-callback-address", finalServerURL)
+	rserverConf.Set("ssl-enabled", "1")
+	rserverConf.Set("ssl-certificate", certPath)
+	rserverConf.Set("ssl-certificate-key", keyPath)
 
-	// remove launcher-sessions-callback-address and append the lines if they don't exist
-	if !linesExist {
-		// remove launcher-sessions-callback-address
-		err := system.DeleteStrings([]string{"launcher-sessions-callback-address"}, filepath, 0644)
-		if err != nil {
-			return fmt.Errorf("failed to delete launcher-sessions-callback-address: %w", err)
-		}
-
-		// append new lines including the updated launcher-sessions-callback-address
-		writeLines := []string{
-			"",
-			"launcher-sessions-callback-address=" + finalServerURL,
-			"",
-			"ssl-enabled=1",
-			"ssl-certificate=" + certPath,
-			"ssl-certificate-key=" + keyPath,
-		}
-
-		err = system.WriteStrings(writeLines, filepath, 0644, true, true)
-		if err != nil {
-			return fmt.Errorf("failed to write config: %w", err)
-		}
-	} else {
-		return fmt.Errorf("at least one line already exists in rserver.conf")
+	err = rserverConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
 	}
-
 	return nil
 }
 
-// WriteConnectURLConfig writes the Connect URL config to the Workbench config file
+// WriteConnectURLConfig sets the default Connect server in rsession.conf, replacing any existing value
 func WriteConnectURLConfig(url string) error {
-	// check to ensure the line doesn't already exist
-	filepath := "/etc/rstudio/rsession.conf"
-	lineExists, err := system.CheckStringExists("default-rsconnect-server=", filepath)
+	rsessionConf, err := conffile.Load("/etc/rstudio/rsession.conf")
 	if err != nil {
-		return fmt.Errorf("failed to check if line exists: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
+	rsessionConf.Set("default-rsconnect-server", url)
 
-	if !lineExists {
-		writeLines := []string{
-			"default-rsconnect-server=" + url,
-		}
-
-		err = system.WriteStrings(writeLines, filepath, 0644, true, true)
-		if err != nil {
-			return fmt.Errorf("failed to write config: %w", err)
-		}
-	} else {
-		return fmt.Errorf("line already exists in rsession.conf")
+	err = rsessionConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
 	}
 	return nil
 }
 
-// WriteJupyterConfig writes the Jupyter config to the Workbench config file
+// WriteJupyterConfig sets the Jupyter executable in jupyter.conf, replacing any existing value
 func WriteJupyterConfig(jupyterPath string) error {
-	// remove the existing line
-	filepath := "/etc/rstudio/jupyter.conf"
-	err := system.DeleteStrings([]string{"# jupyter-exe=/usr/local/bin/jupyter"}, filepath, 0644)
+	jupyterConf, err := conffile.Load("/etc/rstudio/jupyter.conf")
 	if err != nil {
-		return fmt.Errorf("failed to delete the old jupyter-exe=: %w", err)
-	}
-
-	writeLines := []string{
-		"jupyter-exe=" + jupyterPath,
+		return fmt.Errorf("failed to load config: %w", err)
 	}
+	jupyterConf.Set("jupyter-exe", jupyterPath)
 
-	err = system.WriteStrings(writeLines, filepath, 0644, true, true)
+	err = jupyterConf.Save(0644)
 	if err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
-
 	return nil
 }
+
+// ReadJupyterConfig returns the Jupyter executable set in jupyter.conf, or an empty string if it is not set
+func ReadJupyterConfig() (string, error) {
+	jupyterConf, err := conffile.Load("/etc/rstudio/jupyter.conf")
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	jupyterPath, _ := jupyterConf.Get("jupyter-exe")
+	return jupyterPath, nil
+}
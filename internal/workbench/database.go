@@ -0,0 +1,231 @@
+package workbench
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// Database providers configured by wbi config database
+const (
+	DatabasePostgreSQL = "postgresql"
+	DatabaseSQLite     = "sqlite"
+)
+
+// DatabaseProviders lists the valid database providers
+var DatabaseProviders = []string{DatabasePostgreSQL, DatabaseSQLite}
+
+// DefaultPostgreSQLPort is used when no port is provided
+const DefaultPostgreSQLPort = "5432"
+
+var (
+	databaseConfPath = "/etc/rstudio/database.conf"
+	// sqliteDatabaseDir is where Workbench keeps its SQLite database unless database.conf sets a directory
+	sqliteDatabaseDir = "/var/lib/rstudio-server"
+	// runDatabaseCommand runs psql and rstudio-server encrypt-password, passing secrets through the environment
+	// or stdin so they stay out of the process list
+	runDatabaseCommand = runWithInput
+	psqlInstalled      = func() bool {
+		_, err := exec.LookPath("psql")
+		return err == nil
+	}
+)
+
+// postgresqlKeys are removed from database.conf when switching back to SQLite
+var postgresqlKeys = []string{"host", "port", "database", "username", "password", "connection-uri"}
+
+// DatabaseOptions describes the database Workbench stores its data in
+type DatabaseOptions struct {
+	Provider string
+	Host     string
+	Port     string
+	Database string
+	User     string
+	Password string
+	// EncryptPassword stores the output of rstudio-server encrypt-password in database.conf rather than the password
+	EncryptPassword bool
+	// MigrateSQLite keeps the existing SQLite database for Workbench to migrate into PostgreSQL when it restarts,
+	// otherwise it is moved aside and Workbench starts with an empty PostgreSQL database
+	MigrateSQLite bool
+}
+
+// WriteDatabaseConfig tests the connection to PostgreSQL, then writes database.conf with 0600 permissions and
+// handles any existing SQLite data. Nothing is changed when the connection fails.
+func WriteDatabaseConfig(opts DatabaseOptions) error {
+	databaseConf, err := conffile.Load(databaseConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	switch opts.Provider {
+	case DatabasePostgreSQL:
+		if opts.Port == "" {
+			opts.Port = DefaultPostgreSQLPort
+		}
+		tables, err := TestDatabaseConnection(opts)
+		if err != nil {
+			return fmt.Errorf("%w\n%s was not changed, fix the connection details and try again", err, databaseConfPath)
+		}
+		sqlitePath := SQLiteDatabasePath()
+		if sqlitePath != "" && opts.MigrateSQLite && tables > 0 {
+			return fmt.Errorf("the PostgreSQL database %s already contains tables and Workbench only migrates SQLite data into an empty database, use an empty database or leave out the SQLite data", opts.Database)
+		}
+		password := opts.Password
+		if opts.EncryptPassword {
+			password, err = EncryptPassword(opts.Password)
+			if err != nil {
+				return err
+			}
+		}
+		databaseConf.Set("provider", DatabasePostgreSQL)
+		databaseConf.Delete("connection-uri")
+		databaseConf.Set("host", opts.Host)
+		databaseConf.Set("port", opts.Port)
+		databaseConf.Set("database", opts.Database)
+		databaseConf.Set("username", opts.User)
+		databaseConf.Set("password", password)
+		err = databaseConf.SaveSecret(0600, "password")
+		if err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+		err = handleSQLiteData(sqlitePath, opts.MigrateSQLite)
+		if err != nil {
+			return err
+		}
+	case DatabaseSQLite:
+		databaseConf.Set("provider", DatabaseSQLite)
+		for _, key := range postgresqlKeys {
+			databaseConf.Delete(key)
+		}
+		err = databaseConf.SaveSecret(0600, "password")
+		if err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid database provider %s, valid options are: %s", opts.Provider, strings.Join(DatabaseProviders, ", "))
+	}
+
+	system.PrintAndLogInfo("\nRestart Workbench with 'rstudio-server restart' to apply the change.")
+	return nil
+}
+
+// TestDatabaseConnection connects to PostgreSQL with psql and returns the number of tables in the public schema.
+// Without psql only the host and port are checked, and the number of tables is -1.
+func TestDatabaseConnection(opts DatabaseOptions) (int, error) {
+	if opts.Port == "" {
+		opts.Port = DefaultPostgreSQLPort
+	}
+	address := net.JoinHostPort(opts.Host, opts.Port)
+	system.PrintAndLogInfo("\nTesting the connection to the PostgreSQL database " + opts.Database + " on " + address + " as " + opts.User)
+
+	if !psqlInstalled() {
+		conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+		if err != nil {
+			return -1, fmt.Errorf("could not connect to PostgreSQL on %s: %w", address, err)
+		}
+		conn.Close()
+		system.PrintAndLogInfo("psql is not installed, so only the host and port were checked. The database, user and password are checked when Workbench restarts.")
+		return -1, nil
+	}
+
+	connection := fmt.Sprintf("host=%s port=%s dbname=%s user=%s connect_timeout=10", opts.Host, opts.Port, opts.Database, opts.User)
+	query := "SELECT count(*) FROM information_schema.tables WHERE table_schema = 'public'"
+	output, err := runDatabaseCommand("psql", []string{"--no-psqlrc", "--tuples-only", "--no-align", connection, "-c", query}, []string{"PGPASSWORD=" + opts.Password}, "")
+	if err != nil {
+		return 0, fmt.Errorf("could not connect to the PostgreSQL database %s on %s as %s: %w", opts.Database, address, opts.User, err)
+	}
+	tables, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output from psql when counting the tables in %s: %q", opts.Database, output)
+	}
+	system.PrintAndLogInfo("Connected to the PostgreSQL database " + opts.Database)
+	return tables, nil
+}
+
+// EncryptPassword encrypts a password with rstudio-server encrypt-password, which uses the secure cookie key so
+// every Workbench server sharing the key can decrypt it
+func EncryptPassword(password string) (string, error) {
+	encryptCommand := "rstudio-server encrypt-password"
+	if system.DryRun() {
+		system.RecordCommand(encryptCommand)
+		return "********", nil
+	}
+	system.PrintAndLogInfo("Running command: " + encryptCommand)
+	output, err := runDatabaseCommand("rstudio-server", []string{"encrypt-password"}, nil, password+"\n")
+	if err != nil {
+		return "", fmt.Errorf("issue encrypting the database password with the command '%s': %w", encryptCommand, err)
+	}
+	encrypted := lastField(output)
+	if encrypted == "" {
+		return "", fmt.Errorf("the command '%s' did not output an encrypted password", encryptCommand)
+	}
+	return encrypted, nil
+}
+
+// lastField returns the last word of the last non-empty line, the encrypted password follows the prompt text
+func lastField(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// SQLiteDatabasePath returns the path of Workbench's SQLite database, an empty string when it doesn't exist
+func SQLiteDatabasePath() string {
+	dir := sqliteDatabaseDir
+	if databaseConf, err := conffile.Load(databaseConfPath); err == nil {
+		if configured, ok := databaseConf.Get("directory"); ok && configured != "" {
+			dir = configured
+		}
+	}
+	path := filepath.Join(dir, "rstudio.sqlite")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// handleSQLiteData leaves the SQLite database in place for Workbench to migrate into PostgreSQL when it restarts,
+// or moves it aside so Workbench starts with an empty PostgreSQL database
+func handleSQLiteData(sqlitePath string, migrate bool) error {
+	if sqlitePath == "" {
+		return nil
+	}
+	if migrate {
+		system.PrintAndLogInfo("\nWorkbench will migrate the data in " + sqlitePath + " into PostgreSQL when it restarts. Keep " + sqlitePath + " until the migration is complete.")
+		return nil
+	}
+	backupPath := system.BackupPath(sqlitePath)
+	moveCommand := "mv " + sqlitePath + " " + backupPath
+	err := system.RunCommand(moveCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue moving the SQLite database aside with the command '%s': %w", moveCommand, err)
+	}
+	system.PrintAndLogInfo("The SQLite data was not migrated, it has been kept in " + backupPath)
+	return nil
+}
+
+func runWithInput(name string, args []string, env []string, stdin string) (string, error) {
+	var outBuf, errBuf bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(errBuf.String()))
+	}
+	return outBuf.String(), nil
+}
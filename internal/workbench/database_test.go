@@ -0,0 +1,134 @@
+package workbench
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDatabase stands in for psql and rstudio-server encrypt-password, recording how each was run
+type fakeDatabase struct {
+	tables   string
+	connErr  error
+	commands []string
+	env      []string
+	stdin    []string
+}
+
+func (f *fakeDatabase) run(name string, args []string, env []string, stdin string) (string, error) {
+	f.commands = append(f.commands, name+" "+strings.Join(args, " "))
+	f.env = append(f.env, env...)
+	f.stdin = append(f.stdin, stdin)
+	if name == "psql" {
+		return f.tables + "\n", f.connErr
+	}
+	return "Enter password: \nEncrypted password: ENCRYPTED==\n", nil
+}
+
+// useTestDatabase points database.conf and the SQLite directory at a temporary directory and replaces psql
+func useTestDatabase(t *testing.T, databaseConf string, sqlite bool) *fakeDatabase {
+	dir := t.TempDir()
+	fake := &fakeDatabase{tables: "0"}
+	originalConf, originalDir, originalRun, originalPSQL := databaseConfPath, sqliteDatabaseDir, runDatabaseCommand, psqlInstalled
+	databaseConfPath = filepath.Join(dir, "database.conf")
+	sqliteDatabaseDir = dir
+	runDatabaseCommand = fake.run
+	psqlInstalled = func() bool { return true }
+	t.Cleanup(func() {
+		databaseConfPath, sqliteDatabaseDir, runDatabaseCommand, psqlInstalled = originalConf, originalDir, originalRun, originalPSQL
+	})
+	if databaseConf != "" {
+		require.NoError(t, os.WriteFile(databaseConfPath, []byte(databaseConf), 0644))
+	}
+	if sqlite {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "rstudio.sqlite"), []byte("sqlite"), 0600))
+	}
+	return fake
+}
+
+func TestWriteDatabaseConfig(t *testing.T) {
+	opts := DatabaseOptions{Provider: DatabasePostgreSQL, Host: "db.example.com", Database: "rstudio", User: "rstudio", Password: "p@ss"}
+
+	t.Run("postgresql", func(t *testing.T) {
+		fake := useTestDatabase(t, "# Workbench database\nprovider=sqlite\n", false)
+		require.NoError(t, WriteDatabaseConfig(opts))
+
+		conf, err := os.ReadFile(databaseConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "# Workbench database\nprovider=postgresql\nhost=db.example.com\nport=5432\ndatabase=rstudio\nusername=rstudio\npassword=p@ss\n", string(conf))
+		info, err := os.Stat(databaseConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		require.Len(t, fake.commands, 1)
+		assert.Contains(t, fake.commands[0], "host=db.example.com port=5432 dbname=rstudio user=rstudio")
+		assert.NotContains(t, fake.commands[0], "p@ss")
+		assert.Equal(t, []string{"PGPASSWORD=p@ss"}, fake.env)
+	})
+
+	t.Run("encrypted password", func(t *testing.T) {
+		fake := useTestDatabase(t, "", false)
+		encrypted := opts
+		encrypted.EncryptPassword = true
+		require.NoError(t, WriteDatabaseConfig(encrypted))
+
+		conf, err := os.ReadFile(databaseConfPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(conf), "password=ENCRYPTED==\n")
+		assert.NotContains(t, string(conf), "p@ss")
+		assert.Equal(t, "rstudio-server encrypt-password", fake.commands[1])
+		assert.Equal(t, "p@ss\n", fake.stdin[1])
+	})
+
+	t.Run("connection failure leaves database.conf unchanged", func(t *testing.T) {
+		fake := useTestDatabase(t, "provider=sqlite\n", false)
+		fake.connErr = errors.New("password authentication failed for user \"rstudio\"")
+		err := WriteDatabaseConfig(opts)
+		assert.ErrorContains(t, err, "could not connect to the PostgreSQL database rstudio on db.example.com:5432 as rstudio: password authentication failed")
+		assert.ErrorContains(t, err, "was not changed")
+
+		conf, err := os.ReadFile(databaseConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "provider=sqlite\n", string(conf))
+	})
+
+	t.Run("SQLite data is kept for migration", func(t *testing.T) {
+		useTestDatabase(t, "", true)
+		migrate := opts
+		migrate.MigrateSQLite = true
+		require.NoError(t, WriteDatabaseConfig(migrate))
+		assert.FileExists(t, filepath.Join(sqliteDatabaseDir, "rstudio.sqlite"))
+	})
+
+	t.Run("SQLite data is not migrated into a database with tables", func(t *testing.T) {
+		fake := useTestDatabase(t, "", true)
+		fake.tables = "12"
+		migrate := opts
+		migrate.MigrateSQLite = true
+		assert.ErrorContains(t, WriteDatabaseConfig(migrate), "already contains tables")
+		assert.NoFileExists(t, databaseConfPath)
+	})
+
+	t.Run("SQLite data is moved aside", func(t *testing.T) {
+		useTestDatabase(t, "", true)
+		require.NoError(t, WriteDatabaseConfig(opts))
+		assert.NoFileExists(t, filepath.Join(sqliteDatabaseDir, "rstudio.sqlite"))
+		backups, err := filepath.Glob(filepath.Join(sqliteDatabaseDir, "rstudio.sqlite.bak-*"))
+		require.NoError(t, err)
+		assert.Len(t, backups, 1)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		useTestDatabase(t, "provider=postgresql\nhost=db.example.com\nport=5432\ndatabase=rstudio\nusername=rstudio\npassword=p@ss\n", false)
+		require.NoError(t, WriteDatabaseConfig(DatabaseOptions{Provider: DatabaseSQLite}))
+
+		conf, err := os.ReadFile(databaseConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "provider=sqlite\n", string(conf))
+	})
+}
@@ -6,29 +6,42 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/sol-eng/wbi/internal/config"
+	"github.com/sol-eng/wbi/internal/httpclient"
 	"github.com/sol-eng/wbi/internal/install"
 	cmdlog "github.com/sol-eng/wbi/internal/logging"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/system"
 )
 
+// DownloadsURL returns the URL that lists the latest Workbench and Pro Drivers installers
+func DownloadsURL() string {
+	return mirrors.URL(mirrors.PositDownloads, "/wp-content/downloads.json")
+}
+
 // InstallerInfo contains the information needed to download and install Workbench
 type InstallerInfo struct {
 	BaseName string `json:"basename"`
 	URL      string `json:"url"`
 	Version  string `json:"version"`
 	Label    string `json:"label"`
+	SHA256   string `json:"sha256"`
 }
 
 // OperatingSystems contains the installer information for each supported operating system
 type OperatingSystems struct {
 	Focal   InstallerInfo `json:"focal"`
 	Jammy   InstallerInfo `json:"jammy"`
+	Noble   InstallerInfo `json:"noble"`
 	Redhat7 InstallerInfo `json:"redhat7_64"`
 	Redhat8 InstallerInfo `json:"rhel8"`
 	Redhat9 InstallerInfo `json:"rhel9"`
+	// arm64 builds are only published for Ubuntu 22.04 and later and RHEL 8 and later
+	JammyARM64   InstallerInfo `json:"jammy_arm64"`
+	NobleARM64   InstallerInfo `json:"noble_arm64"`
+	Redhat8ARM64 InstallerInfo `json:"rhel8_arm64"`
+	Redhat9ARM64 InstallerInfo `json:"rhel9_arm64"`
 }
 
 // Installer contains the installer information for a product
@@ -64,26 +77,35 @@ func DownloadAndInstallWorkbench(osType config.OperatingSystem) error {
 		return fmt.Errorf("RetrieveWorkbenchInstallerInfo: %w", err)
 	}
 	// Retrieve installer info
-	installerInfo, err := rstudio.GetInstallerInfo(osType)
+	installerInfo, err := rstudio.GetInstallerInfo(osType, config.HostArchitecture())
 	if err != nil {
 		return fmt.Errorf("GetInstallerInfo: %w", err)
 	}
 	// Download installer
-	filepath, err := install.DownloadFile("Workbench", installerInfo.URL, installerInfo.BaseName)
+	filepath, err := install.DownloadFile("Workbench", installerInfo.URL, installerInfo.BaseName, installerInfo.SHA256)
 	if err != nil {
 		return fmt.Errorf("DownloadFile: %w", err)
 	}
+	// Verify the package is signed by Posit
+	err = install.VerifyPackageSignature(filepath, osType)
+	if err != nil {
+		return fmt.Errorf("VerifyPackageSignature: %w", err)
+	}
 	// Install Workbench
 	err = InstallWorkbench(filepath, osType)
 	if err != nil {
 		return fmt.Errorf("InstallWorkbench: %w", err)
 	}
+	install.RemoveDownload(filepath)
 	// save to command log
 	installCommand, err := RetrieveInstallCommandForWorkbench(installerInfo.BaseName, osType)
 	if err != nil {
 		return fmt.Errorf("RetrieveInstallCommand: %w", err)
 	}
 	cmdlog.Info("curl -O " + installerInfo.URL)
+	if installerInfo.SHA256 != "" {
+		cmdlog.Info(install.SHA256CheckCommand(installerInfo.SHA256, installerInfo.BaseName))
+	}
 	cmdlog.Info(installCommand)
 	return nil
 }
@@ -107,7 +129,7 @@ func InstallWorkbench(filepath string, osType config.OperatingSystem) error {
 // Creates the proper command to install Workbench based on the operating system
 func RetrieveInstallCommandForWorkbench(filepath string, osType config.OperatingSystem) (string, error) {
 	switch osType {
-	case config.Ubuntu22, config.Ubuntu20:
+	case config.Ubuntu24, config.Ubuntu22, config.Ubuntu20:
 		return "DEBIAN_FRONTEND=noninteractive gdebi -n " + filepath, nil
 	case config.Redhat7, config.Redhat8, config.Redhat9:
 		return "yum install -y " + filepath, nil
@@ -117,30 +139,56 @@ func RetrieveInstallCommandForWorkbench(filepath string, osType config.Operating
 }
 
 // Pulls out the installer information from the JSON data based on the operating system
-func (r *RStudio) GetInstallerInfo(osType config.OperatingSystem) (InstallerInfo, error) {
+func (r *RStudio) GetInstallerInfo(osType config.OperatingSystem, arch config.Architecture) (InstallerInfo, error) {
+	if arch == config.ARM64 {
+		return r.getARM64InstallerInfo(osType)
+	}
+	var info InstallerInfo
 	switch osType {
 	case config.Ubuntu20:
-		return r.Rstudio.Pro.Stable.Server.Installer.Focal, nil
+		info = r.Rstudio.Pro.Stable.Server.Installer.Focal
 	case config.Ubuntu22:
-		return r.Rstudio.Pro.Stable.Server.Installer.Jammy, nil
+		info = r.Rstudio.Pro.Stable.Server.Installer.Jammy
+	case config.Ubuntu24:
+		info = r.Rstudio.Pro.Stable.Server.Installer.Noble
 	case config.Redhat7:
-		return r.Rstudio.Pro.Stable.Server.Installer.Redhat7, nil
+		info = r.Rstudio.Pro.Stable.Server.Installer.Redhat7
 	case config.Redhat8:
-		return r.Rstudio.Pro.Stable.Server.Installer.Redhat8, nil
+		info = r.Rstudio.Pro.Stable.Server.Installer.Redhat8
 	case config.Redhat9:
-		return r.Rstudio.Pro.Stable.Server.Installer.Redhat9, nil
+		info = r.Rstudio.Pro.Stable.Server.Installer.Redhat9
 	default:
 		return InstallerInfo{}, errors.New("operating system not supported")
 	}
+	// downloads.json lists upstream URLs, which are remapped to any configured mirror
+	info.URL = mirrors.Rewrite(info.URL)
+	return info, nil
+}
+
+func (r *RStudio) getARM64InstallerInfo(osType config.OperatingSystem) (InstallerInfo, error) {
+	var info InstallerInfo
+	switch osType {
+	case config.Ubuntu22:
+		info = r.Rstudio.Pro.Stable.Server.Installer.JammyARM64
+	case config.Ubuntu24:
+		info = r.Rstudio.Pro.Stable.Server.Installer.NobleARM64
+	case config.Redhat8:
+		info = r.Rstudio.Pro.Stable.Server.Installer.Redhat8ARM64
+	case config.Redhat9:
+		info = r.Rstudio.Pro.Stable.Server.Installer.Redhat9ARM64
+	}
+	if info.URL == "" {
+		return InstallerInfo{}, fmt.Errorf("workbench has no arm64 build for %s", osType.ToString())
+	}
+	info.URL = mirrors.Rewrite(info.URL)
+	return info, nil
 }
 
 // Retrieves JSON data from Posit
 func RetrieveWorkbenchInstallerInfo() (RStudio, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.New()
 	req, err := http.NewRequestWithContext(context.Background(),
-		http.MethodGet, "https://www.rstudio.com/wp-content/downloads.json", nil)
+		http.MethodGet, DownloadsURL(), nil)
 	if err != nil {
 		return RStudio{}, errors.New("error creating request")
 	}
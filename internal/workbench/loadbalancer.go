@@ -0,0 +1,336 @@
+package workbench
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sol-eng/wbi/internal/conffile"
+	"github.com/sol-eng/wbi/internal/operatingsystem"
+	"github.com/sol-eng/wbi/internal/system"
+)
+
+// Load balancing methods Workbench uses to pick a node for new sessions
+const (
+	BalancerSessions     = "sessions"
+	BalancerUserSessions = "user-sessions"
+	BalancerSystemLoad   = "system-load"
+)
+
+// Balancers lists the valid load balancing methods
+var Balancers = []string{BalancerSessions, BalancerUserSessions, BalancerSystemLoad}
+
+var (
+	rstudioConfDir = "/etc/rstudio"
+	findMount      = operatingsystem.FindMount
+	// installedWorkbenchVersion returns the output of rstudio-server version
+	installedWorkbenchVersion = func() (string, error) {
+		out, err := system.RunCommandAndCaptureOutput("rstudio-server version", false, 0, false)
+		return strings.TrimSpace(out), err
+	}
+)
+
+// clusterFiles are copied from /etc/rstudio to the cluster directory so every node is configured the same way,
+// secret files are only readable by root
+var clusterFiles = []struct {
+	name   string
+	secret bool
+}{
+	{name: "load-balancer"},
+	{name: "rserver.conf"},
+	{name: "rsession.conf"},
+	{name: "launcher.conf"},
+	{name: "r-versions"},
+	{name: "repos.conf"},
+	{name: "jupyter.conf"},
+	{name: "vscode.conf"},
+	{name: "launcher.pub"},
+	{name: "database.conf", secret: true},
+	{name: "openid-client-secret", secret: true},
+	{name: "secure-cookie-key", secret: true},
+	{name: "launcher.pem", secret: true},
+}
+
+// clusterVersionFile records the version of Workbench on the first node, other nodes must run the same version
+const clusterVersionFile = "workbench-version"
+
+// LoadBalancerOptions describes a load balanced Workbench cluster
+type LoadBalancerOptions struct {
+	// SharedStoragePath is an NFS directory shared by every node, set as server-shared-storage-path
+	SharedStoragePath string
+	// ClusterDir is where the configuration and keys are copied for other nodes to join with wbi join
+	ClusterDir string
+	Balancer   string
+	// HostName is the address other nodes and the load balancer reach this node at, Workbench's default is used when empty
+	HostName string
+}
+
+// ConfigureLoadBalancer checks the shared storage and database are suitable for load balancing, writes the
+// load-balancer file and server-shared-storage-path, creates the shared keys, then copies the configuration to the
+// cluster directory for other nodes
+func ConfigureLoadBalancer(opts LoadBalancerOptions) error {
+	err := checkLoadBalancerDatabase(databaseConfPath)
+	if err != nil {
+		return err
+	}
+	err = CheckSharedStorage(opts.SharedStoragePath)
+	if err != nil {
+		return err
+	}
+	dirCommand := "mkdir -p " + opts.SharedStoragePath
+	err = system.RunCommand(dirCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue creating the shared storage directory with the command '%s': %w", dirCommand, err)
+	}
+
+	rserverConf, err := conffile.Load(rserverConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	rserverConf.Set("server-shared-storage-path", opts.SharedStoragePath)
+	err = rserverConf.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if opts.Balancer == "" {
+		opts.Balancer = BalancerSessions
+	}
+	loadBalancer, err := conffile.Load(filepath.Join(rstudioConfDir, "load-balancer"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	loadBalancer.Set("balancer", opts.Balancer)
+	if opts.HostName != "" {
+		loadBalancer.Set("www-host-name", opts.HostName)
+	}
+	err = loadBalancer.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	err = createClusterKeys()
+	if err != nil {
+		return err
+	}
+	err = publishClusterFiles(opts.ClusterDir)
+	if err != nil {
+		return err
+	}
+
+	system.PrintAndLogInfo("\nThe load balancer configuration has been copied to " + opts.ClusterDir + ". On each other node, install the same version of Workbench, then run:\n" +
+		"  wbi join --from " + opts.ClusterDir + "\n" +
+		"Restart Workbench on every node with 'rstudio-server restart' to apply the change.")
+	return nil
+}
+
+// checkLoadBalancerDatabase returns an error unless a database.conf uses PostgreSQL, every node must share one database
+func checkLoadBalancerDatabase(path string) error {
+	databaseConf, err := conffile.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	provider, _ := databaseConf.Get("provider")
+	if strings.ToLower(provider) != DatabasePostgreSQL {
+		return errors.New("load balanced Workbench servers must share a PostgreSQL database, but " + path + " uses SQLite. Configure PostgreSQL first with 'wbi config database'")
+	}
+	return nil
+}
+
+// CheckSharedStorage returns an error unless the shared storage path is on an NFS mount Workbench can use
+func CheckSharedStorage(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("the shared storage path %s must be an absolute path", path)
+	}
+	mount, err := findMount(path)
+	if err != nil {
+		return fmt.Errorf("issue finding the file system of %s: %w", path, err)
+	}
+	return sharedStorageProblem(path, mount)
+}
+
+// sharedStorageProblem describes why a mount can't be used for shared storage, every node needs to read and write
+// the same files and Workbench relies on NFS file locking
+func sharedStorageProblem(path string, mount operatingsystem.Mount) error {
+	if !mount.NFS() {
+		return fmt.Errorf("the shared storage path %s is on a local %s file system mounted at %s, load balanced Workbench servers need the same NFS share mounted on every node", path, mount.FSType, mount.MountPoint)
+	}
+	if _, ok := mount.Option("ro"); ok {
+		return fmt.Errorf("the NFS share %s is mounted read-only at %s, mount it with the rw option", mount.Device, mount.MountPoint)
+	}
+	if _, ok := mount.Option("nolock"); ok {
+		return fmt.Errorf("the NFS share %s is mounted at %s with nolock, Workbench needs NFS file locking so remove the nolock option", mount.Device, mount.MountPoint)
+	}
+	if localLock, ok := mount.Option("local_lock"); ok && localLock != "none" {
+		return fmt.Errorf("the NFS share %s is mounted at %s with local_lock=%s, Workbench needs file locks shared between nodes so use local_lock=none", mount.Device, mount.MountPoint, localLock)
+	}
+	return nil
+}
+
+// createClusterKeys creates the secure cookie key and the Job Launcher key pair when they don't exist, existing keys
+// are kept so users stay signed in
+func createClusterKeys() error {
+	secureCookieKeyPath := filepath.Join(rstudioConfDir, "secure-cookie-key")
+	if _, err := os.Stat(secureCookieKeyPath); err != nil {
+		// the key is created with umask 077 so it's never readable by other users, even briefly
+		keyCommand := "(umask 077 && openssl rand -hex 32 > " + secureCookieKeyPath + ")"
+		err := system.RunCommand(keyCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue creating the secure cookie key with the command '%s': %w", keyCommand, err)
+		}
+	}
+
+	launcherKeyPath := filepath.Join(rstudioConfDir, "launcher.pem")
+	launcherPubPath := filepath.Join(rstudioConfDir, "launcher.pub")
+	newKey := false
+	if _, err := os.Stat(launcherKeyPath); err != nil {
+		keyCommand := "(umask 077 && openssl genpkey -algorithm RSA -pkeyopt rsa_keygen_bits:2048 -out " + launcherKeyPath + ")"
+		err := system.RunCommand(keyCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue creating the Job Launcher key with the command '%s': %w", keyCommand, err)
+		}
+		newKey = true
+	}
+	// the public key must match the private key
+	if _, err := os.Stat(launcherPubPath); err != nil || newKey {
+		pubCommand := "openssl rsa -in " + launcherKeyPath + " -pubout -out " + launcherPubPath
+		err := system.RunCommand(pubCommand, true, 0, true)
+		if err != nil {
+			return fmt.Errorf("issue creating the Job Launcher public key with the command '%s': %w", pubCommand, err)
+		}
+	}
+	return nil
+}
+
+// publishClusterFiles copies the configuration and keys to the cluster directory, which only root can read
+func publishClusterFiles(clusterDir string) error {
+	dirCommand := "install -d -m 700 " + clusterDir
+	err := system.RunCommand(dirCommand, true, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue creating the cluster directory with the command '%s': %w", dirCommand, err)
+	}
+	for _, file := range clusterFiles {
+		source := filepath.Join(rstudioConfDir, file.name)
+		if !plannedFileExists(source) {
+			continue
+		}
+		err := installFile(source, filepath.Join(clusterDir, file.name), file.secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	version, err := installedWorkbenchVersion()
+	if err != nil {
+		return fmt.Errorf("issue finding the version of Workbench with the command 'rstudio-server version': %w", err)
+	}
+	versionPath := filepath.Join(clusterDir, clusterVersionFile)
+	versionCommand := "echo '" + version + "' > " + versionPath
+	err = system.RunCommand(versionCommand, false, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue recording the Workbench version with the command '%s': %w", versionCommand, err)
+	}
+	return nil
+}
+
+// JoinCluster checks the shared storage and database, then makes this node consistent with the first node by
+// copying the configuration and keys from the cluster directory
+func JoinCluster(clusterDir string, hostName string) error {
+	if _, err := os.Stat(filepath.Join(clusterDir, "load-balancer")); err != nil {
+		return fmt.Errorf("%s does not contain a load-balancer file, create it on the first node with 'wbi config load-balancer'", clusterDir)
+	}
+
+	version, err := installedWorkbenchVersion()
+	if err != nil {
+		return errors.New("Workbench is not installed on this node, install the same version as the first node before joining") //nolint:all
+	}
+	if clusterVersion, err := os.ReadFile(filepath.Join(clusterDir, clusterVersionFile)); err == nil {
+		if strings.TrimSpace(string(clusterVersion)) != version {
+			return fmt.Errorf("this node runs Workbench %s but the first node runs %s, every node must run the same version", version, strings.TrimSpace(string(clusterVersion)))
+		}
+	}
+
+	// check the cluster's configuration works on this node before changing anything
+	err = checkLoadBalancerDatabase(filepath.Join(clusterDir, "database.conf"))
+	if err != nil {
+		return err
+	}
+	rserverConf, err := conffile.Load(filepath.Join(clusterDir, "rserver.conf"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	sharedStoragePath, ok := rserverConf.Get("server-shared-storage-path")
+	if !ok || sharedStoragePath == "" {
+		return errors.New("server-shared-storage-path is not set in " + clusterDir + "/rserver.conf, run 'wbi config load-balancer' on the first node")
+	}
+	err = CheckSharedStorage(sharedStoragePath)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range clusterFiles {
+		source := filepath.Join(clusterDir, file.name)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+		destination := filepath.Join(rstudioConfDir, file.name)
+		if _, err := os.Stat(destination); err == nil {
+			backupCommand := "cp -p " + destination + " " + system.BackupPath(destination)
+			err := system.RunCommand(backupCommand, false, 0, true)
+			if err != nil {
+				return fmt.Errorf("issue backing up %s with the command '%s': %w", destination, backupCommand, err)
+			}
+		}
+		err := installFile(source, destination, file.secret)
+		if err != nil {
+			return err
+		}
+		if system.DryRun() && !file.secret {
+			// later steps read the copied files, so plan them with the cluster's content
+			content, err := os.ReadFile(source)
+			if err == nil {
+				system.RecordFileChange(destination, string(content))
+			}
+		}
+	}
+
+	// the first node's host name was copied with the load-balancer file
+	loadBalancer, err := conffile.Load(filepath.Join(rstudioConfDir, "load-balancer"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if hostName != "" {
+		loadBalancer.Set("www-host-name", hostName)
+	} else {
+		loadBalancer.Delete("www-host-name")
+	}
+	err = loadBalancer.Save(0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	system.PrintAndLogInfo("\nThis node now has the same configuration as the first node. Restart Workbench with 'rstudio-server restart' to apply the change.")
+	return nil
+}
+
+// installFile copies a file, secret files are only readable by root
+func installFile(source string, destination string, secret bool) error {
+	mode := "644"
+	if secret {
+		mode = "600"
+	}
+	installCommand := "install -m " + mode + " " + source + " " + destination
+	err := system.RunCommand(installCommand, false, 0, true)
+	if err != nil {
+		return fmt.Errorf("issue copying %s with the command '%s': %w", source, installCommand, err)
+	}
+	return nil
+}
+
+// plannedFileExists checks for a file, including files created earlier in a dry run
+func plannedFileExists(path string) bool {
+	_, exists, err := system.ReadPlannedFile(path)
+	return err == nil && exists
+}
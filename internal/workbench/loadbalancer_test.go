@@ -0,0 +1,175 @@
+package workbench
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sol-eng/wbi/internal/operatingsystem"
+	"github.com/sol-eng/wbi/internal/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestCluster points /etc/rstudio at a temporary directory, with the shared storage on NFS and Workbench installed
+func useTestCluster(t *testing.T, mount operatingsystem.Mount, version string) string {
+	dir := t.TempDir()
+	originalDir, originalRserver, originalDatabase := rstudioConfDir, rserverConfPath, databaseConfPath
+	originalFindMount, originalVersion := findMount, installedWorkbenchVersion
+	rstudioConfDir = dir
+	rserverConfPath = filepath.Join(dir, "rserver.conf")
+	databaseConfPath = filepath.Join(dir, "database.conf")
+	findMount = func(string) (operatingsystem.Mount, error) { return mount, nil }
+	installedWorkbenchVersion = func() (string, error) { return version, nil }
+	t.Cleanup(func() {
+		rstudioConfDir, rserverConfPath, databaseConfPath = originalDir, originalRserver, originalDatabase
+		findMount, installedWorkbenchVersion = originalFindMount, originalVersion
+	})
+	return dir
+}
+
+var testNFSMount = operatingsystem.Mount{Device: "nfs.example.com:/export/shared", MountPoint: "/shared", FSType: "nfs4", Options: []string{"rw", "vers=4.1", "hard"}}
+
+func TestSharedStorageProblem(t *testing.T) {
+	tests := map[string]struct {
+		mount       operatingsystem.Mount
+		expectError string
+	}{
+		"nfs": {
+			mount: testNFSMount,
+		},
+		"local file system": {
+			mount:       operatingsystem.Mount{Device: "/dev/sda1", MountPoint: "/", FSType: "ext4", Options: []string{"rw"}},
+			expectError: "is on a local ext4 file system mounted at /",
+		},
+		"read-only": {
+			mount:       operatingsystem.Mount{Device: "nfs:/shared", MountPoint: "/shared", FSType: "nfs", Options: []string{"ro", "vers=3"}},
+			expectError: "is mounted read-only",
+		},
+		"no locking": {
+			mount:       operatingsystem.Mount{Device: "nfs:/shared", MountPoint: "/shared", FSType: "nfs", Options: []string{"rw", "vers=3", "nolock"}},
+			expectError: "with nolock",
+		},
+		"local locks": {
+			mount:       operatingsystem.Mount{Device: "nfs:/shared", MountPoint: "/shared", FSType: "nfs", Options: []string{"rw", "local_lock=all"}},
+			expectError: "with local_lock=all",
+		},
+		"shared locks": {
+			mount: operatingsystem.Mount{Device: "nfs:/shared", MountPoint: "/shared", FSType: "nfs4", Options: []string{"rw", "local_lock=none"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := sharedStorageProblem("/shared/rstudio", tc.mount)
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestConfigureLoadBalancer(t *testing.T) {
+	opts := LoadBalancerOptions{SharedStoragePath: "/shared/rstudio", ClusterDir: "/shared/wbi-cluster", HostName: "node1.example.com"}
+
+	t.Run("requires PostgreSQL", func(t *testing.T) {
+		useTestCluster(t, testNFSMount, "2024.04.2+764.pro1")
+		assert.ErrorContains(t, ConfigureLoadBalancer(opts), "must share a PostgreSQL database")
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		dir := useTestCluster(t, testNFSMount, "2024.04.2+764.pro1")
+		require.NoError(t, os.WriteFile(databaseConfPath, []byte("provider=postgresql\nhost=db.example.com\n"), 0600))
+		system.SetDryRun(true)
+		t.Cleanup(func() { system.SetDryRun(false) })
+
+		require.NoError(t, ConfigureLoadBalancer(opts))
+		var steps []string
+		for _, step := range system.Plan() {
+			steps = append(steps, step.Description)
+		}
+		plan := strings.Join(steps, "\n")
+		assert.Contains(t, plan, "mkdir -p /shared/rstudio")
+		assert.Contains(t, plan, "(umask 077 && openssl rand -hex 32 > "+dir+"/secure-cookie-key)")
+		assert.Contains(t, plan, "(umask 077 && openssl genpkey -algorithm RSA")
+		assert.Contains(t, plan, "openssl rsa -in "+dir+"/launcher.pem -pubout -out "+dir+"/launcher.pub")
+		assert.Contains(t, plan, "install -d -m 700 /shared/wbi-cluster")
+		assert.Contains(t, plan, "install -m 600 "+dir+"/database.conf /shared/wbi-cluster/database.conf")
+		assert.Contains(t, plan, "install -m 644 "+dir+"/load-balancer /shared/wbi-cluster/load-balancer")
+		assert.Contains(t, plan, "install -m 644 "+dir+"/rserver.conf /shared/wbi-cluster/rserver.conf")
+		assert.Contains(t, plan, "echo '2024.04.2+764.pro1' > /shared/wbi-cluster/workbench-version")
+
+		rserverConf, _, err := system.ReadPlannedFile(rserverConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, "server-shared-storage-path=/shared/rstudio\n", rserverConf)
+		loadBalancer, _, err := system.ReadPlannedFile(filepath.Join(dir, "load-balancer"))
+		require.NoError(t, err)
+		assert.Equal(t, "balancer=sessions\nwww-host-name=node1.example.com\n", loadBalancer)
+	})
+}
+
+func TestJoinCluster(t *testing.T) {
+	clusterDir := t.TempDir()
+	files := map[string]string{
+		"load-balancer":     "balancer=sessions\nwww-host-name=node1.example.com\n",
+		"rserver.conf":      "server-shared-storage-path=/shared/rstudio\n",
+		"database.conf":     "provider=postgresql\nhost=db.example.com\npassword=p@ss\n",
+		"secure-cookie-key": "0123456789abcdef\n",
+		"workbench-version": "2024.04.2+764.pro1\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(clusterDir, name), []byte(content), 0600))
+	}
+
+	t.Run("copies the configuration", func(t *testing.T) {
+		dir := useTestCluster(t, testNFSMount, "2024.04.2+764.pro1")
+		require.NoError(t, os.WriteFile(rserverConfPath, []byte("www-port=8787\n"), 0644))
+		require.NoError(t, JoinCluster(clusterDir, "node2.example.com"))
+
+		loadBalancer, err := os.ReadFile(filepath.Join(dir, "load-balancer"))
+		require.NoError(t, err)
+		assert.Equal(t, "balancer=sessions\nwww-host-name=node2.example.com\n", string(loadBalancer))
+		rserverConf, err := os.ReadFile(rserverConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, files["rserver.conf"], string(rserverConf))
+
+		info, err := os.Stat(filepath.Join(dir, "secure-cookie-key"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		info, err = os.Stat(rserverConfPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+		backups, err := filepath.Glob(rserverConfPath + ".bak-*")
+		require.NoError(t, err)
+		assert.Len(t, backups, 1)
+		assert.NoFileExists(t, filepath.Join(dir, "workbench-version"))
+	})
+
+	t.Run("without a host name", func(t *testing.T) {
+		dir := useTestCluster(t, testNFSMount, "2024.04.2+764.pro1")
+		require.NoError(t, JoinCluster(clusterDir, ""))
+		loadBalancer, err := os.ReadFile(filepath.Join(dir, "load-balancer"))
+		require.NoError(t, err)
+		assert.Equal(t, "balancer=sessions\n", string(loadBalancer))
+	})
+
+	t.Run("different Workbench version", func(t *testing.T) {
+		dir := useTestCluster(t, testNFSMount, "2023.12.1+402.pro1")
+		assert.ErrorContains(t, JoinCluster(clusterDir, ""), "every node must run the same version")
+		assert.NoFileExists(t, filepath.Join(dir, "load-balancer"))
+	})
+
+	t.Run("shared storage not on NFS", func(t *testing.T) {
+		dir := useTestCluster(t, operatingsystem.Mount{Device: "/dev/sda1", MountPoint: "/", FSType: "xfs"}, "2024.04.2+764.pro1")
+		assert.ErrorContains(t, JoinCluster(clusterDir, ""), "is on a local xfs file system")
+		assert.NoFileExists(t, filepath.Join(dir, "load-balancer"))
+	})
+
+	t.Run("not a cluster directory", func(t *testing.T) {
+		useTestCluster(t, testNFSMount, "2024.04.2+764.pro1")
+		assert.ErrorContains(t, JoinCluster(t.TempDir(), ""), "does not contain a load-balancer file")
+	})
+}
@@ -7,11 +7,15 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/sol-eng/wbi/internal/answers"
 	"github.com/sol-eng/wbi/internal/config"
 )
 
 // Prompt users if they would like to install Workbench
 func WorkbenchInstallPrompt() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Workbench.Install, "workbench.install")
+	}
 	name := true
 	messageText := "Workbench is required to be installed to continue. Would you like to install Workbench?"
 	prompt := &survey.Confirm{
@@ -27,6 +31,9 @@ func WorkbenchInstallPrompt() (bool, error) {
 }
 
 func PromptInstallVerify() (bool, error) {
+	if answers.Loaded() {
+		return answers.Require(answers.Get().Verify.Enabled, "verify.enabled")
+	}
 	name := false
 	messageText := "Would you like to verify the installation of Workbench?"
 	prompt := &survey.Confirm{
@@ -59,3 +66,159 @@ func CheckPromptDownloadAndInstallWorkbench(osType config.OperatingSystem) error
 	}
 	return nil
 }
+
+// PromptAuthOptions asks users for the type of authentication and the details it needs, keeping any values
+// already provided with flags
+func PromptAuthOptions(opts AuthOptions) (AuthOptions, error) {
+	if opts.Type == "" {
+		messageText := "How should users sign in to Workbench?"
+		prompt := &survey.Select{
+			Message: messageText,
+			Options: AuthTypes,
+			Default: AuthPAM,
+			Description: func(value string, index int) string {
+				return map[string]string{
+					AuthSAML:   "SAML single sign-on",
+					AuthOpenID: "OpenID Connect single sign-on",
+					AuthPAM:    "local Linux accounts",
+					AuthProxy:  "an authenticating proxy in front of Workbench",
+				}[value]
+			},
+		}
+		err := survey.AskOne(prompt, &opts.Type)
+		if err != nil {
+			return opts, errors.New("there was an issue with the authentication type prompt")
+		}
+		log.Info(messageText)
+		log.Info(opts.Type)
+	}
+
+	type authInput struct {
+		value       *string
+		messageText string
+		secret      bool
+		optional    bool
+	}
+	var inputs []authInput
+	switch opts.Type {
+	case AuthSAML:
+		inputs = []authInput{
+			{value: &opts.MetadataURL, messageText: "URL of the SAML identity provider metadata:"},
+			{value: &opts.UsernameClaim, messageText: "SAML attribute to use as the username (leave blank for Workbench's default, Username):", optional: true},
+		}
+	case AuthOpenID:
+		inputs = []authInput{
+			{value: &opts.Issuer, messageText: "OpenID Connect issuer URL (for example, https://login.microsoftonline.com/TENANT-ID/v2.0):"},
+			{value: &opts.ClientID, messageText: "OpenID Connect client ID:"},
+			{value: &opts.ClientSecret, messageText: "OpenID Connect client secret:", secret: true},
+			{value: &opts.UsernameClaim, messageText: "Claim to use as the username (leave blank for Workbench's default, preferred_username):", optional: true},
+		}
+	case AuthProxy:
+		inputs = []authInput{
+			{value: &opts.SignInURL, messageText: "URL of the proxy's sign in page:"},
+			{value: &opts.UserHeader, messageText: "Header the proxy sets to the username (leave blank for Workbench's default, X-RStudio-Username):", optional: true},
+		}
+	}
+
+	for _, input := range inputs {
+		if *input.value != "" {
+			continue
+		}
+		var prompt survey.Prompt = &survey.Input{Message: input.messageText}
+		if input.secret {
+			prompt = &survey.Password{Message: input.messageText}
+		}
+		var askOpts []survey.AskOpt
+		if !input.optional {
+			askOpts = append(askOpts, survey.WithValidator(survey.Required))
+		}
+		err := survey.AskOne(prompt, input.value, askOpts...)
+		if err != nil {
+			return opts, fmt.Errorf("issue prompting for authentication details: %w", err)
+		}
+		log.Info(input.messageText)
+		if !input.secret {
+			log.Info(*input.value)
+		}
+	}
+	return opts, nil
+}
+
+// PromptDatabaseOptions asks users for the database provider and connection details, keeping any values already
+// provided with flags, and offers to encrypt the password and migrate existing SQLite data
+func PromptDatabaseOptions(opts DatabaseOptions) (DatabaseOptions, error) {
+	if opts.Provider == "" {
+		messageText := "Which database should Workbench store its data in?"
+		prompt := &survey.Select{
+			Message: messageText,
+			Options: DatabaseProviders,
+			Default: DatabasePostgreSQL,
+			Description: func(value string, index int) string {
+				return map[string]string{
+					DatabasePostgreSQL: "an external PostgreSQL database, required for load balancing",
+					DatabaseSQLite:     "a local SQLite database, Workbench's default",
+				}[value]
+			},
+		}
+		err := survey.AskOne(prompt, &opts.Provider)
+		if err != nil {
+			return opts, errors.New("there was an issue with the database provider prompt")
+		}
+		log.Info(messageText)
+		log.Info(opts.Provider)
+	}
+	if opts.Provider != DatabasePostgreSQL {
+		return opts, nil
+	}
+
+	inputs := []struct {
+		value        *string
+		messageText  string
+		defaultValue string
+		secret       bool
+	}{
+		{value: &opts.Host, messageText: "PostgreSQL host:"},
+		{value: &opts.Port, messageText: "PostgreSQL port:", defaultValue: DefaultPostgreSQLPort},
+		{value: &opts.Database, messageText: "PostgreSQL database:"},
+		{value: &opts.User, messageText: "PostgreSQL user:"},
+		{value: &opts.Password, messageText: "Password of the PostgreSQL user:", secret: true},
+	}
+	for _, input := range inputs {
+		if *input.value != "" {
+			continue
+		}
+		var prompt survey.Prompt = &survey.Input{Message: input.messageText, Default: input.defaultValue}
+		if input.secret {
+			prompt = &survey.Password{Message: input.messageText}
+		}
+		err := survey.AskOne(prompt, input.value, survey.WithValidator(survey.Required))
+		if err != nil {
+			return opts, fmt.Errorf("issue prompting for database details: %w", err)
+		}
+		log.Info(input.messageText)
+		if !input.secret {
+			log.Info(*input.value)
+		}
+	}
+
+	if !opts.EncryptPassword {
+		messageText := "Would you like to store the password encrypted with rstudio-server encrypt-password?"
+		err := survey.AskOne(&survey.Confirm{Message: messageText, Default: true}, &opts.EncryptPassword)
+		if err != nil {
+			return opts, errors.New("there was an issue with the encrypt password prompt")
+		}
+		log.Info(messageText)
+		log.Info(fmt.Sprintf("%v", opts.EncryptPassword))
+	}
+
+	if sqlitePath := SQLiteDatabasePath(); sqlitePath != "" && !opts.MigrateSQLite {
+		messageText := "Would you like Workbench to migrate the existing data in " + sqlitePath + " into PostgreSQL?"
+		err := survey.AskOne(&survey.Confirm{Message: messageText, Default: true}, &opts.MigrateSQLite)
+		if err != nil {
+			return opts, errors.New("there was an issue with the SQLite migration prompt")
+		}
+		log.Info(messageText)
+		log.Info(fmt.Sprintf("%v", opts.MigrateSQLite))
+	}
+	return opts, nil
+}
@@ -2,50 +2,86 @@ package workbench
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/sol-eng/wbi/internal/system"
 )
 
-func StatusRStudioServerAndLauncher() error {
-	err := StatusRStudioServer()
+// ServiceStatus is the state of a Workbench service parsed from its status output
+type ServiceStatus struct {
+	Service string `json:"service" yaml:"service"`
+	Active  bool   `json:"active" yaml:"active"`
+	// State is the systemd state, such as "active (running)" or "inactive (dead)"
+	State   string `json:"state" yaml:"state"`
+	Since   string `json:"since,omitempty" yaml:"since,omitempty"`
+	MainPID int    `json:"main_pid,omitempty" yaml:"main_pid,omitempty"`
+	// Output is the unparsed status output, only printed in text output
+	Output string `json:"-" yaml:"-"`
+}
+
+func StatusRStudioServerAndLauncher() ([]ServiceStatus, error) {
+	serverStatus, err := StatusRStudioServer()
 	if err != nil {
-		return fmt.Errorf("issue running status for rstudio-server: %w", err)
+		return nil, fmt.Errorf("issue running status for rstudio-server: %w", err)
 	}
-	err = StatusRStudioLauncher()
+	launcherStatus, err := StatusRStudioLauncher()
 	if err != nil {
-		return fmt.Errorf("issue running status for rstudio-launcher: %w", err)
+		return nil, fmt.Errorf("issue running status for rstudio-launcher: %w", err)
 	}
-	return nil
+	return []ServiceStatus{serverStatus, launcherStatus}, nil
 }
 
-func StatusRStudioServer() error {
+func StatusRStudioServer() (ServiceStatus, error) {
 	status, err := system.RunCommandAndCaptureOutput("rstudio-server status | cat", true, 1, false)
 	if err != nil {
-		return fmt.Errorf("issue running status for rstudio-server: %w", err)
-	}
-	if strings.Contains(status, "active (running)") {
-		system.PrintAndLogInfo(status)
-		system.PrintAndLogInfo("\nrstudio-server status is active (running)!")
-	} else {
-		system.PrintAndLogInfo(status)
-		system.PrintAndLogInfo("\nrstudio-server status is not active!")
+		return ServiceStatus{}, fmt.Errorf("issue running status for rstudio-server: %w", err)
 	}
-
-	return nil
+	return ParseServiceStatus("rstudio-server", status), nil
 }
 
-func StatusRStudioLauncher() error {
+func StatusRStudioLauncher() (ServiceStatus, error) {
 	status, err := system.RunCommandAndCaptureOutput("rstudio-launcher status | cat", true, 1, false)
 	if err != nil {
-		return fmt.Errorf("issue running status for rstudio-launcher with the command 'rstudio-launcher status | cat': %w", err)
+		return ServiceStatus{}, fmt.Errorf("issue running status for rstudio-launcher with the command 'rstudio-launcher status | cat': %w", err)
+	}
+	return ParseServiceStatus("rstudio-launcher", status), nil
+}
+
+// ParseServiceStatus parses the Active and Main PID lines of systemctl status output
+func ParseServiceStatus(service string, output string) ServiceStatus {
+	status := ServiceStatus{Service: service, State: "unknown", Output: output}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if value, found := cutPrefix(line, "Active:"); found {
+			state, since, _ := strings.Cut(value, " since ")
+			status.State = strings.TrimSpace(state)
+			status.Active = strings.HasPrefix(status.State, "active (running)")
+			status.Since, _, _ = strings.Cut(since, ";")
+		} else if value, found := cutPrefix(line, "Main PID:"); found {
+			pid, _, _ := strings.Cut(strings.TrimSpace(value), " ")
+			status.MainPID, _ = strconv.Atoi(pid)
+		}
+	}
+	if status.State == "unknown" {
+		status.Active = strings.Contains(output, "active (running)")
 	}
-	if strings.Contains(status, "active (running)") {
-		system.PrintAndLogInfo(status)
-		system.PrintAndLogInfo("\nrstudio-launcher status is active (running)!")
+	return status
+}
+
+// PrintServiceStatus prints the status output followed by whether the service is running
+func PrintServiceStatus(status ServiceStatus) {
+	system.PrintAndLogInfo(status.Output)
+	if status.Active {
+		system.PrintAndLogInfo("\n" + status.Service + " status is active (running)!")
 	} else {
-		system.PrintAndLogInfo(status)
-		system.PrintAndLogInfo("\nrstudio-launcher status is not active!")
+		system.PrintAndLogInfo("\n" + status.Service + " status is not active!")
+	}
+}
+
+func cutPrefix(s string, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
 	}
-	return nil
+	return strings.TrimSpace(strings.TrimPrefix(s, prefix)), true
 }
@@ -0,0 +1,32 @@
+package workbench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceStatus(t *testing.T) {
+	running := `● rstudio-server.service - RStudio Server
+     Loaded: loaded (/lib/systemd/system/rstudio-server.service; enabled; vendor preset: enabled)
+     Active: active (running) since Mon 2023-03-20 10:00:00 UTC; 2h 3min ago
+   Main PID: 1234 (rserver)
+      Tasks: 5 (limit: 4915)`
+	status := ParseServiceStatus("rstudio-server", running)
+	assert.Equal(t, "rstudio-server", status.Service)
+	assert.True(t, status.Active)
+	assert.Equal(t, "active (running)", status.State)
+	assert.Equal(t, "Mon 2023-03-20 10:00:00 UTC", status.Since)
+	assert.Equal(t, 1234, status.MainPID)
+
+	stopped := `● rstudio-launcher.service - RStudio Launcher
+     Active: inactive (dead)`
+	status = ParseServiceStatus("rstudio-launcher", stopped)
+	assert.False(t, status.Active)
+	assert.Equal(t, "inactive (dead)", status.State)
+	assert.Empty(t, status.Since)
+
+	status = ParseServiceStatus("rstudio-server", "")
+	assert.False(t, status.Active)
+	assert.Equal(t, "unknown", status.State)
+}
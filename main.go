@@ -5,6 +5,7 @@ import (
 	"github.com/sol-eng/wbi/cmd"
 	"github.com/sol-eng/wbi/internal/config"
 	"github.com/sol-eng/wbi/internal/languages"
+	"github.com/sol-eng/wbi/internal/mirrors"
 	"github.com/sol-eng/wbi/internal/prodrivers"
 	"github.com/sol-eng/wbi/internal/quarto"
 	"github.com/sol-eng/wbi/internal/workbench"
@@ -30,6 +31,8 @@ func OSSwitch(osGo string) config.OperatingSystem {
 		osType = config.Ubuntu20
 	case "U22":
 		osType = config.Ubuntu22
+	case "U24":
+		osType = config.Ubuntu24
 	case "RH7":
 		osType = config.Redhat7
 	case "RH8":
@@ -44,6 +47,18 @@ func OSSwitch(osGo string) config.OperatingSystem {
 
 }
 
+// The exports below read the mirrors in /etc/wbi/mirrors.yaml and the WBI_MIRROR_<SOURCE> environment variables,
+// setmirrors loads a different file and returns an error message, or an empty string on success
+//
+//export setmirrors
+func setmirrors(file *C.char) *C.char {
+	err := mirrors.Configure(C.GoString(file), nil)
+	if err != nil {
+		return C.CString(err.Error())
+	}
+	return C.CString("")
+}
+
 //export rversions
 func rversions() *C.char {
 	result, _ := languages.RetrieveValidRVersions()
@@ -55,7 +70,7 @@ func rurls(os *C.char, rVersion *C.char) *C.char {
 	osGo := C.GoString(os)
 	rVersionGo := C.GoString(rVersion)
 	osType := OSSwitch(osGo)
-	installerInfo, _ := languages.PopulateInstallerInfo("r", rVersionGo, osType)
+	installerInfo, _ := languages.PopulateInstallerInfo("r", rVersionGo, osType, config.HostArchitecture())
 	//fmt.Println(installerInfo.URL)
 	return C.CString(installerInfo.URL)
 }
@@ -74,7 +89,7 @@ func pythonurls(os *C.char, pythonVersion *C.char) *C.char {
 	osGo := C.GoString(os)
 	pythonVersionGo := C.GoString(pythonVersion)
 	osType := OSSwitch(osGo)
-	installerInfo, _ := languages.PopulateInstallerInfo("python", pythonVersionGo, osType)
+	installerInfo, _ := languages.PopulateInstallerInfo("python", pythonVersionGo, osType, config.HostArchitecture())
 	//fmt.Println(installerInfo.URL)
 	return C.CString(installerInfo.URL)
 }
@@ -90,7 +105,7 @@ func quartourls(os *C.char, quartoVersion *C.char) *C.char {
 	osGo := C.GoString(os)
 	quartoVersionGo := C.GoString(quartoVersion)
 	osType := OSSwitch(osGo)
-	quartoURL := quarto.GenerateQuartoInstallURL(quartoVersionGo, osType)
+	quartoURL, _ := quarto.GenerateQuartoInstallURL(quartoVersionGo, osType, config.HostArchitecture())
 
 	//fmt.Println(installerInfo.URL)
 	return C.CString(quartoURL)
@@ -103,7 +118,7 @@ func workbenchurl(os *C.char) (*C.char, *C.char) {
 	// Retrieve JSON data
 	rstudio, _ := workbench.RetrieveWorkbenchInstallerInfo()
 	// Retrieve installer info
-	installerInfo, _ := rstudio.GetInstallerInfo(osType)
+	installerInfo, _ := rstudio.GetInstallerInfo(osType, config.HostArchitecture())
 	//fmt.Println(installerInfo.URL)
 	return C.CString(installerInfo.Version), C.CString(installerInfo.URL)
 }
@@ -115,7 +130,7 @@ func driverurl(os *C.char) (*C.char, *C.char) {
 	// Retrieve JSON data
 	rstudio, _ := prodrivers.RetrieveProDriversInstallerInfo()
 	// Retrieve installer info
-	installerInfo, _ := rstudio.GetInstallerInfo(osType)
+	installerInfo, _ := rstudio.GetInstallerInfo(osType, config.HostArchitecture())
 	//fmt.Println(installerInfo.URL)
 	return C.CString(installerInfo.Version), C.CString(installerInfo.URL)
 }